@@ -33,6 +33,11 @@ func (handler *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 
 			return
 		}
+		if errors.Is(err, service.ErrSuspended) {
+			http.Error(w, err.Error(), http.StatusForbidden)
+
+			return
+		}
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 
 		return
@@ -70,6 +75,9 @@ func (handler *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
 		if errors.Is(err, service.ErrUnathorized) {
 			http.Error(w, err.Error(), http.StatusUnauthorized)
 			return
+		} else if errors.Is(err, service.ErrSuspended) {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
 		} else {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
@@ -38,3 +38,13 @@ type RefreshTokenAndClaimsDTO struct {
 	RefreshToken string
 	Claims       jwt.MapClaims
 }
+
+type AccountStatus struct {
+	Role      string `json:"role"`
+	Suspended bool   `json:"suspended"`
+}
+
+type AccessClaims struct {
+	Role string `json:"role"`
+	jwt.StandardClaims
+}
@@ -3,8 +3,12 @@ package service
 import (
 	"crypto/rsa"
 	"errors"
+	"fmt"
+	"net/http"
+	"os"
 	"time"
 
+	"github.com/KristijanPill/Nishtagram/auth-service/helpers"
 	"github.com/KristijanPill/Nishtagram/auth-service/payload"
 	"github.com/KristijanPill/Nishtagram/auth-service/repository"
 	jwt "github.com/dgrijalva/jwt-go"
@@ -31,6 +35,8 @@ type LoginResponse struct {
 
 var ErrUnathorized = errors.New("unathorized")
 
+var ErrSuspended = errors.New("account suspended")
+
 func NewAuthService(publicKey *rsa.PublicKey, secretKey *rsa.PrivateKey, hmacKey []byte, refreshTokenRepository *repository.RefreshTokenRepository, credentialsRepository *repository.CredentialsRepository) *AuthService {
 	return &AuthService{
 		PublicKey:              publicKey,
@@ -53,7 +59,17 @@ func (service *AuthService) Login(loginRequest *payload.LoginRequest) (*LoginRes
 		return nil, err
 	}
 
-	return service.generateTokens(credentials.ID)
+	status, err := service.fetchAccountStatus(credentials.ID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if status.Suspended {
+		return nil, ErrSuspended
+	}
+
+	return service.generateTokens(credentials.ID, status.Role)
 }
 
 func (service *AuthService) Register(credentials *payload.Credentials) error {
@@ -79,11 +95,43 @@ func (service *AuthService) Refresh(refreshToken string, userID uuid.UUID) (stri
 		return "", ErrUnathorized
 	}
 
-	return service.generateAccessToken(userID)
+	status, err := service.fetchAccountStatus(userID)
+
+	if err != nil {
+		return "", err
+	}
+
+	if status.Suspended {
+		return "", ErrSuspended
+	}
+
+	return service.generateAccessToken(userID, status.Role)
 }
 
-func (service *AuthService) generateTokens(userID uuid.UUID) (*LoginResponse, error) {
-	accessToken, err := service.generateAccessToken(userID)
+// fetchAccountStatus asks user-service for the role that goes into the access
+// token and whether the account has been suspended by a moderator.
+func (service *AuthService) fetchAccountStatus(userID uuid.UUID) (*payload.AccountStatus, error) {
+	requestURL := fmt.Sprintf("http://%s:%s/internal/account-status/%s", os.Getenv("USER_SERVICE_DOMAIN"), os.Getenv("USER_SERVICE_PORT"), userID.String())
+	response, err := http.Get(requestURL)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, ErrUnathorized
+	}
+
+	status := &payload.AccountStatus{}
+	err = helpers.FromJSON(status, response.Body)
+
+	return status, err
+}
+
+func (service *AuthService) generateTokens(userID uuid.UUID, role string) (*LoginResponse, error) {
+	accessToken, err := service.generateAccessToken(userID, role)
 
 	if err != nil {
 		return nil, err
@@ -104,11 +152,14 @@ func (service *AuthService) generateTokens(userID uuid.UUID) (*LoginResponse, er
 	}, nil
 }
 
-func (service *AuthService) generateAccessToken(userID uuid.UUID) (string, error) {
-	claims := jwt.StandardClaims{
-		Subject:   userID.String(),
-		IssuedAt:  time.Now().Unix(),
-		ExpiresAt: time.Now().Add(accessTokenDuration * time.Minute).Unix(),
+func (service *AuthService) generateAccessToken(userID uuid.UUID, role string) (string, error) {
+	claims := payload.AccessClaims{
+		Role: role,
+		StandardClaims: jwt.StandardClaims{
+			Subject:   userID.String(),
+			IssuedAt:  time.Now().Unix(),
+			ExpiresAt: time.Now().Add(accessTokenDuration * time.Minute).Unix(),
+		},
 	}
 
 	token := jwt.NewWithClaims(
@@ -1,13 +1,19 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 
 	"github.com/KristijanPill/Nishtagram/media-service/helpers"
+	"github.com/KristijanPill/Nishtagram/media-service/middleware"
 	"github.com/KristijanPill/Nishtagram/media-service/payload"
 	"github.com/KristijanPill/Nishtagram/media-service/service"
+	"github.com/dgrijalva/jwt-go"
 )
 
+var errRequestTooLarge = fmt.Errorf("a request can be at most %d MB", service.MaxRequestSize>>20)
+
 type MediaHandler struct {
 	service *service.MediaService
 }
@@ -17,135 +23,143 @@ func NewMediaHandler(service *service.MediaService) *MediaHandler {
 }
 
 func (handler *MediaHandler) CreatePost(w http.ResponseWriter, r *http.Request) {
-	err := r.ParseMultipartForm(1024 * 128)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	media, ok := handler.upload(w, r, service.PostUpload)
 
+	if !ok {
 		return
 	}
 
-	formData := r.MultipartForm
-
-	images := formData.File["media"]
-
-	var postPaths []string
-
-	for i, _ := range images {
-		image, err := images[i].Open()
-		defer image.Close()
+	var paths []string
 
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+	for _, metadata := range media {
+		paths = append(paths, metadata.Path)
+	}
 
-			return
-		}
+	posts := &payload.MediaUploadResponse{MediaPaths: paths, Media: media}
+	helpers.ToJSON(&posts, w)
+}
 
-		path, err := handler.service.CreatePost(&image, images[i].Filename)
+func (handler *MediaHandler) CreateStory(w http.ResponseWriter, r *http.Request) {
+	media, ok := handler.upload(w, r, service.StoryUpload)
 
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+	if !ok {
+		return
+	}
 
-			return
-		}
+	var paths []string
 
-		postPaths = append(postPaths, path)
+	for _, metadata := range media {
+		paths = append(paths, metadata.Path)
 	}
 
-	posts := &payload.MediaUploadResponse{MediaPaths: postPaths}
-	helpers.ToJSON(&posts, w)
+	stories := &payload.MediaUploadResponse{MediaPaths: paths, Media: media}
+	helpers.ToJSON(&stories, w)
 }
 
-func (handler *MediaHandler) CreateStory(w http.ResponseWriter, r *http.Request) {
-	err := r.ParseMultipartForm(1024 * 128)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+func (handler *MediaHandler) CreateDocument(w http.ResponseWriter, r *http.Request) {
+	media, ok := handler.upload(w, r, service.DocumentUpload)
 
+	if !ok {
 		return
 	}
 
-	formData := r.MultipartForm
-
-	images := formData.File["media"]
-
-	var postPaths []string
+	documentPictureUploadResponse := &payload.DocumentPictureUploadResponse{DocumentPicture: media[0].Path}
+	helpers.ToJSON(&documentPictureUploadResponse, w)
+}
 
-	for i, _ := range images {
-		image, err := images[i].Open()
-		defer image.Close()
+func (handler *MediaHandler) UploadProfilePicture(w http.ResponseWriter, r *http.Request) {
+	media, ok := handler.upload(w, r, service.ProfilePictureUpload)
 
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+	if !ok {
+		return
+	}
 
-			return
-		}
+	profilePictureUploadResponse := &payload.ProfilePictureUploadResponse{ProfilePicture: media[0].Path}
+	helpers.ToJSON(&profilePictureUploadResponse, w)
+}
 
-		path, err := handler.service.CreateStory(&image, images[i].Filename)
+func (handler *MediaHandler) FindStorageUsage(w http.ResponseWriter, r *http.Request) {
+	claims := r.Context().Value(middleware.TokenKey{}).(jwt.MapClaims)
+	userID := helpers.ExtractClaim("sub", claims)
 
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+	helpers.ToJSON(handler.service.StorageUsage(userID), w)
+}
 
-			return
-		}
+// upload stores the files sent in the media field of the form. The body is
+// limited before it is parsed, so parts that don't fit in memory can't fill up
+// the disk either, and the parts are removed once the files are stored.
+func (handler *MediaHandler) upload(w http.ResponseWriter, r *http.Request, kind service.UploadKind) ([]payload.MediaMetadata, bool) {
+	if r.ContentLength > service.MaxRequestSize {
+		http.Error(w, errRequestTooLarge.Error(), http.StatusRequestEntityTooLarge)
 
-		postPaths = append(postPaths, path)
+		return nil, false
 	}
 
-	posts := &payload.MediaUploadResponse{MediaPaths: postPaths}
-	helpers.ToJSON(&posts, w)
-}
+	r.Body = http.MaxBytesReader(w, r.Body, service.MaxRequestSize)
 
-func (handler *MediaHandler) CreateDocument(w http.ResponseWriter, r *http.Request) {
 	err := r.ParseMultipartForm(1024 * 128)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 
-		return
+		return nil, false
 	}
 
-	file, handle, err := r.FormFile("media")
+	defer r.MultipartForm.RemoveAll()
 
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	claims := r.Context().Value(middleware.TokenKey{}).(jwt.MapClaims)
+	userID := helpers.ExtractClaim("sub", claims)
 
-		return
+	media, err := handler.service.Upload(kind, userID, r.MultipartForm.File["media"])
+
+	if writeUploadError(w, err) {
+		return nil, false
 	}
 
-	documentPath, err := handler.service.UploadDocumentPicture(&file, handle.Filename)
+	return media, true
+}
+
+func (handler *MediaHandler) DeleteStories(w http.ResponseWriter, r *http.Request) {
+	dto := &payload.MediaDelete{}
+	err := helpers.FromJSON(&dto, r.Body)
 
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 
 		return
 	}
 
-	documentPictureUploadResponse := &payload.DocumentPictureUploadResponse{DocumentPicture: documentPath}
-	helpers.ToJSON(&documentPictureUploadResponse, w)
-}
+	err = handler.service.DeleteStories(dto.Paths)
 
-func (handler *MediaHandler) UploadProfilePicture(w http.ResponseWriter, r *http.Request) {
-	err := r.ParseMultipartForm(1024 * 128)
-	if err != nil {
+	if err == service.ErrInvalidMediaPath {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 
 		return
 	}
 
-	file, handle, err := r.FormFile("media")
-
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 
 		return
 	}
 
-	picturePath, err := handler.service.UploadProfilePicture(&file, handle.Filename)
+	w.WriteHeader(http.StatusNoContent)
+}
 
-	if err != nil {
+// writeUploadError writes the response for a rejected or failed upload and
+// reports whether there was an error.
+func writeUploadError(w http.ResponseWriter, err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, service.ErrNoMedia), errors.Is(err, service.ErrTooManyFiles), errors.Is(err, service.ErrInvalidImage):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, service.ErrFileTooLarge), errors.Is(err, service.ErrQuotaExceeded):
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+	case errors.Is(err, service.ErrUnsupportedMediaType):
+		http.Error(w, err.Error(), http.StatusUnsupportedMediaType)
+	default:
 		http.Error(w, err.Error(), http.StatusInternalServerError)
-
-		return
 	}
 
-	profilePictureUploadResponse := &payload.ProfilePictureUploadResponse{ProfilePicture: picturePath}
-	helpers.ToJSON(&profilePictureUploadResponse, w)
+	return true
 }
@@ -33,11 +33,24 @@ func handleFunc(handler *handler.MediaHandler, securityMiddleware *middleware.Se
 	postRouterRestricted.HandleFunc("/upload/profile-picture", handler.UploadProfilePicture)
 	postRouterRestricted.Use(securityMiddleware.Authenticate)
 
+	getRouterRestricted := sm.Methods(http.MethodGet).Subrouter()
+	getRouterRestricted.HandleFunc("/upload/usage", handler.FindStorageUsage)
+	getRouterRestricted.Use(securityMiddleware.Authenticate)
+
+	postRouterInternal := sm.Methods(http.MethodPost).Subrouter()
+	postRouterInternal.HandleFunc("/internal/delete/stories", handler.DeleteStories)
+
 	http.Handle("/", *fs)
 }
 
 func main() {
-	mediaService := service.NewMediaService()
+	storageQuota, err := service.NewStorageQuota("./data/quotas.json")
+
+	if err != nil {
+		panic(err.Error())
+	}
+
+	mediaService := service.NewMediaService(storageQuota)
 	mediaHandler := handler.NewMediaHandler(mediaService)
 	securityMiddleware := middleware.NewSecurityMiddleware(publicKey)
 
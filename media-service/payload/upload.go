@@ -1,7 +1,31 @@
 package payload
 
 type MediaUploadResponse struct {
-	MediaPaths []string `json:"mediaPaths"`
+	MediaPaths []string        `json:"mediaPaths"`
+	Media      []MediaMetadata `json:"media"`
+}
+
+type MediaMetadata struct {
+	Path       string           `json:"path"`
+	Type       string           `json:"type"`
+	Width      int              `json:"width"`
+	Height     int              `json:"height"`
+	Duration   float64          `json:"duration"`
+	Blurhash   string           `json:"blurhash,omitempty"`
+	Renditions []MediaRendition `json:"renditions,omitempty"`
+}
+
+// MediaRendition is a copy of an image in one of the sizes media-service
+// makes: thumbnail, feed or full.
+type MediaRendition struct {
+	Name   string `json:"name"`
+	Path   string `json:"path"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+type MediaDelete struct {
+	Paths []string `json:"paths"`
 }
 
 type DocumentPictureUploadResponse struct {
@@ -11,3 +35,8 @@ type DocumentPictureUploadResponse struct {
 type ProfilePictureUploadResponse struct {
 	ProfilePicture string `json:"profile_picture"`
 }
+
+type StorageUsage struct {
+	Used  int64 `json:"used"`
+	Quota int64 `json:"quota"`
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"image"
+	"math"
+	"strings"
+)
+
+const (
+	blurhashComponentsX = 4
+	blurhashComponentsY = 3
+	blurhashSampleSize  = 32
+)
+
+const base83Characters = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz#$%*+,-.:;=?@[]^_{|}~"
+
+// encodeBlurhash computes the blurhash placeholder of the image, as described
+// on blurha.sh. The image is sampled down first, since the hash only keeps a
+// few colour components anyway.
+func encodeBlurhash(img *image.RGBA) string {
+	sample := resize(img, blurhashSampleSize)
+	width, height := sample.Bounds().Dx(), sample.Bounds().Dy()
+
+	var factors [blurhashComponentsY][blurhashComponentsX][3]float64
+
+	for j := 0; j < blurhashComponentsY; j++ {
+		for i := 0; i < blurhashComponentsX; i++ {
+			normalisation := 2.0
+
+			if i == 0 && j == 0 {
+				normalisation = 1
+			}
+
+			var factor [3]float64
+
+			for y := 0; y < height; y++ {
+				for x := 0; x < width; x++ {
+					basis := normalisation * math.Cos(math.Pi*float64(i*x)/float64(width)) * math.Cos(math.Pi*float64(j*y)/float64(height))
+					pixel := sample.Pix[y*sample.Stride+x*4:]
+
+					for c := 0; c < 3; c++ {
+						factor[c] += basis * srgbToLinear(pixel[c])
+					}
+				}
+			}
+
+			for c := 0; c < 3; c++ {
+				factors[j][i][c] = factor[c] / float64(width*height)
+			}
+		}
+	}
+
+	var hash strings.Builder
+
+	hash.WriteString(encodeBase83((blurhashComponentsX-1)+(blurhashComponentsY-1)*9, 1))
+
+	maximum := 0.0
+
+	for j := 0; j < blurhashComponentsY; j++ {
+		for i := 0; i < blurhashComponentsX; i++ {
+			if i == 0 && j == 0 {
+				continue
+			}
+
+			for c := 0; c < 3; c++ {
+				maximum = math.Max(maximum, math.Abs(factors[j][i][c]))
+			}
+		}
+	}
+
+	quantisedMaximum := int(math.Max(0, math.Min(82, math.Floor(maximum*166-0.5))))
+	maximumValue := float64(quantisedMaximum+1) / 166
+
+	hash.WriteString(encodeBase83(quantisedMaximum, 1))
+
+	dc := factors[0][0]
+	hash.WriteString(encodeBase83(linearToSrgb(dc[0])<<16+linearToSrgb(dc[1])<<8+linearToSrgb(dc[2]), 4))
+
+	for j := 0; j < blurhashComponentsY; j++ {
+		for i := 0; i < blurhashComponentsX; i++ {
+			if i == 0 && j == 0 {
+				continue
+			}
+
+			value := 0
+
+			for _, component := range factors[j][i] {
+				quantised := int(math.Max(0, math.Min(18, math.Floor(signedPow(component/maximumValue, 0.5)*9+9.5))))
+				value = value*19 + quantised
+			}
+
+			hash.WriteString(encodeBase83(value, 2))
+		}
+	}
+
+	return hash.String()
+}
+
+func encodeBase83(value int, length int) string {
+	encoded := make([]byte, length)
+
+	for i := length - 1; i >= 0; i-- {
+		encoded[i] = base83Characters[value%83]
+		value /= 83
+	}
+
+	return string(encoded)
+}
+
+func srgbToLinear(value uint8) float64 {
+	v := float64(value) / 255
+
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+
+	return math.Pow((v+0.055)/1.055, 2.4)
+}
+
+func linearToSrgb(value float64) int {
+	v := math.Max(0, math.Min(1, value))
+
+	if v <= 0.0031308 {
+		return int(v*12.92*255 + 0.5)
+	}
+
+	return int((1.055*math.Pow(v, 1/2.4)-0.055)*255 + 0.5)
+}
+
+func signedPow(value float64, exponent float64) float64 {
+	return math.Copysign(math.Pow(math.Abs(value), exponent), value)
+}
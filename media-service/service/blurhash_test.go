@@ -0,0 +1,90 @@
+package service
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"strings"
+	"testing"
+)
+
+func TestEncodeBase83(t *testing.T) {
+	tests := []struct {
+		value  int
+		length int
+		want   string
+	}{
+		{value: 0, length: 1, want: "0"},
+		{value: 21, length: 1, want: "L"},
+		{value: 82, length: 1, want: "~"},
+		{value: 3429, length: 2, want: "fQ"},
+		{value: 83*83 - 1, length: 2, want: "~~"},
+		{value: 0, length: 4, want: "0000"},
+		{value: 0xFF0000, length: 4, want: "TI:j"},
+		{value: 0xFFFFFF, length: 4, want: "TSUA"},
+	}
+
+	for _, test := range tests {
+		if got := encodeBase83(test.value, test.length); got != test.want {
+			t.Errorf("encodeBase83(%d, %d) = %q, want %q", test.value, test.length, got, test.want)
+		}
+	}
+}
+
+func solid(width int, height int, colour color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), &image.Uniform{C: colour}, image.Point{}, draw.Src)
+
+	return img
+}
+
+func TestEncodeBlurhashAverageColour(t *testing.T) {
+	tests := []struct {
+		name string
+		img  *image.RGBA
+		want string
+	}{
+		{name: "black", img: solid(8, 8, color.RGBA{A: 255}), want: "0000"},
+		{name: "white", img: solid(8, 8, color.RGBA{R: 255, G: 255, B: 255, A: 255}), want: "TSUA"},
+		{name: "red", img: solid(8, 8, color.RGBA{R: 255, A: 255}), want: "TI:j"},
+		{name: "sampled down first", img: solid(300, 200, color.RGBA{R: 255, A: 255}), want: "TI:j"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			hash := encodeBlurhash(test.img)
+
+			if len(hash) != 28 || !strings.HasPrefix(hash, "L") {
+				t.Fatalf("encodeBlurhash() = %q, want 28 characters starting with the 4x3 size flag L", hash)
+			}
+
+			if got := hash[2:6]; got != test.want {
+				t.Errorf("encodeBlurhash() = %q, want average colour %q", hash, test.want)
+			}
+		})
+	}
+}
+
+func TestEncodeBlurhashOfDetailedImage(t *testing.T) {
+	black := &image.Uniform{C: color.RGBA{A: 255}}
+	white := &image.Uniform{C: color.RGBA{R: 255, G: 255, B: 255, A: 255}}
+
+	left := solid(64, 32, color.RGBA{A: 255})
+	draw.Draw(left, image.Rect(32, 0, 64, 32), white, image.Point{}, draw.Src)
+
+	right := solid(64, 32, color.RGBA{A: 255})
+	draw.Draw(right, image.Rect(0, 0, 32, 32), white, image.Point{}, draw.Src)
+	draw.Draw(right, image.Rect(32, 0, 64, 32), black, image.Point{}, draw.Src)
+
+	leftHash, rightHash := encodeBlurhash(left), encodeBlurhash(right)
+
+	// Half black and half white averages to a mid grey in linear light,
+	// which is #BCBCBC in sRGB.
+	if leftHash[2:6] != "Lqe9" || rightHash[2:6] != "Lqe9" {
+		t.Errorf("encodeBlurhash() = %q and %q, want average colour %q", leftHash, rightHash, "Lqe9")
+	}
+
+	if leftHash == rightHash {
+		t.Errorf("encodeBlurhash() = %q for both, want mirrored images to differ", leftHash)
+	}
+}
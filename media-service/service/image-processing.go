@@ -0,0 +1,359 @@
+package service
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"io/ioutil"
+	"path"
+	"strings"
+
+	"github.com/KristijanPill/Nishtagram/media-service/payload"
+)
+
+const (
+	fullSizeRendition  = "full"
+	feedRendition      = "feed"
+	thumbnailRendition = "thumbnail"
+)
+
+const (
+	fullSize    = 2048
+	jpegQuality = 85
+	maxPixels   = 50 * 1000 * 1000
+)
+
+// rendition is a smaller copy of an image, stored next to it. Images are
+// scaled down to fit in a square of the given size, never up.
+type rendition struct {
+	name string
+	size int
+}
+
+var renditions = []rendition{
+	{name: thumbnailRendition, size: 320},
+	{name: feedRendition, size: 1080},
+}
+
+// processImage replaces a stored image with a copy that is turned the way its
+// EXIF orientation says and is at most full size. The copy is encoded without
+// any of the metadata of the original, which drops the camera details and GPS
+// position. Animated GIFs are kept as they are, since they carry no EXIF and
+// re-encoding would keep only the first frame. It returns the metadata of the
+// image and the size of every file it wrote.
+func processImage(mediaPath string, withRenditions bool) (*payload.MediaMetadata, map[string]int64, error) {
+	data, err := ioutil.ReadFile("." + mediaPath)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+
+	if err != nil {
+		return nil, nil, ErrInvalidImage
+	}
+
+	if config.Width*config.Height > maxPixels {
+		return nil, nil, fmt.Errorf("%w: an image can have at most %d megapixels", ErrFileTooLarge, maxPixels/1000000)
+	}
+
+	decoded, _, err := image.Decode(bytes.NewReader(data))
+
+	if err != nil {
+		return nil, nil, ErrInvalidImage
+	}
+
+	full := resize(orient(toRGBA(decoded), readOrientation(data)), fullSize)
+	width, height := full.Bounds().Dx(), full.Bounds().Dy()
+	written := make(map[string]int64)
+
+	if format == "gif" {
+		width, height = config.Width, config.Height
+		written[mediaPath] = int64(len(data))
+	} else {
+		size, err := writeImage(mediaPath, full)
+
+		if err != nil {
+			return nil, written, err
+		}
+
+		written[mediaPath] = size
+	}
+
+	metadata := &payload.MediaMetadata{
+		Path:     mediaPath,
+		Type:     imageMediaType,
+		Width:    width,
+		Height:   height,
+		Blurhash: encodeBlurhash(full),
+	}
+
+	if !withRenditions {
+		return metadata, written, nil
+	}
+
+	metadata.Renditions = append(metadata.Renditions, payload.MediaRendition{
+		Name:   fullSizeRendition,
+		Path:   mediaPath,
+		Width:  metadata.Width,
+		Height: metadata.Height,
+	})
+
+	for _, rendition := range renditions {
+		scaled := resize(full, rendition.size)
+		renditionPath := renditionPath(mediaPath, rendition.name)
+		size, err := writeImage(renditionPath, scaled)
+
+		if err != nil {
+			return nil, written, err
+		}
+
+		written[renditionPath] = size
+		metadata.Renditions = append(metadata.Renditions, payload.MediaRendition{
+			Name:   rendition.name,
+			Path:   renditionPath,
+			Width:  scaled.Bounds().Dx(),
+			Height: scaled.Bounds().Dy(),
+		})
+	}
+
+	return metadata, written, nil
+}
+
+// renditionPath names a rendition after the image it was made from. JPEGs stay
+// JPEGs, and everything else is stored as PNG to keep the transparency.
+func renditionPath(mediaPath string, name string) string {
+	extension := path.Ext(mediaPath)
+
+	if extension != ".jpg" {
+		extension = ".png"
+	}
+
+	return strings.TrimSuffix(mediaPath, path.Ext(mediaPath)) + "_" + name + extension
+}
+
+func renditionPaths(mediaPath string) []string {
+	var paths []string
+
+	for _, rendition := range renditions {
+		paths = append(paths, renditionPath(mediaPath, rendition.name))
+	}
+
+	return paths
+}
+
+func writeImage(mediaPath string, img image.Image) (int64, error) {
+	var encoded bytes.Buffer
+	var err error
+
+	if path.Ext(mediaPath) == ".jpg" {
+		err = jpeg.Encode(&encoded, img, &jpeg.Options{Quality: jpegQuality})
+	} else {
+		err = png.Encode(&encoded, img)
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	if err := ioutil.WriteFile("."+mediaPath, encoded.Bytes(), 0644); err != nil {
+		return 0, err
+	}
+
+	return int64(encoded.Len()), nil
+}
+
+func toRGBA(img image.Image) *image.RGBA {
+	bounds := img.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), img, bounds.Min, draw.Src)
+
+	return rgba
+}
+
+// orient turns and mirrors the image the way the EXIF orientation tag says it
+// has to be shown.
+func orient(src *image.RGBA, orientation int) *image.RGBA {
+	if orientation < 2 || orientation > 8 {
+		return src
+	}
+
+	width, height := src.Bounds().Dx(), src.Bounds().Dy()
+	orientedWidth, orientedHeight := width, height
+
+	if orientation >= 5 {
+		orientedWidth, orientedHeight = height, width
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, orientedWidth, orientedHeight))
+
+	for y := 0; y < orientedHeight; y++ {
+		for x := 0; x < orientedWidth; x++ {
+			sx, sy := x, y
+
+			switch orientation {
+			case 2:
+				sx = width - 1 - x
+			case 3:
+				sx, sy = width-1-x, height-1-y
+			case 4:
+				sy = height - 1 - y
+			case 5:
+				sx, sy = y, x
+			case 6:
+				sx, sy = y, height-1-x
+			case 7:
+				sx, sy = width-1-y, height-1-x
+			case 8:
+				sx, sy = width-1-y, x
+			}
+
+			copy(dst.Pix[y*dst.Stride+x*4:y*dst.Stride+x*4+4], src.Pix[sy*src.Stride+sx*4:sy*src.Stride+sx*4+4])
+		}
+	}
+
+	return dst
+}
+
+// resize scales the image down to fit in a square of the given size, averaging
+// the pixels each new pixel covers.
+func resize(src *image.RGBA, size int) *image.RGBA {
+	width, height := src.Bounds().Dx(), src.Bounds().Dy()
+
+	if width <= size && height <= size {
+		return src
+	}
+
+	scaledWidth, scaledHeight := size, height*size/width
+
+	if height > width {
+		scaledWidth, scaledHeight = width*size/height, size
+	}
+
+	if scaledWidth < 1 {
+		scaledWidth = 1
+	}
+
+	if scaledHeight < 1 {
+		scaledHeight = 1
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, scaledWidth, scaledHeight))
+
+	for y := 0; y < scaledHeight; y++ {
+		top, bottom := y*height/scaledHeight, (y+1)*height/scaledHeight
+
+		if bottom == top {
+			bottom++
+		}
+
+		for x := 0; x < scaledWidth; x++ {
+			left, right := x*width/scaledWidth, (x+1)*width/scaledWidth
+
+			if right == left {
+				right++
+			}
+
+			var sum [4]int
+
+			for sy := top; sy < bottom; sy++ {
+				for sx := left; sx < right; sx++ {
+					pixel := src.Pix[sy*src.Stride+sx*4:]
+
+					for c := 0; c < 4; c++ {
+						sum[c] += int(pixel[c])
+					}
+				}
+			}
+
+			count := (bottom - top) * (right - left)
+
+			for c := 0; c < 4; c++ {
+				dst.Pix[y*dst.Stride+x*4+c] = uint8(sum[c] / count)
+			}
+		}
+	}
+
+	return dst
+}
+
+// readOrientation finds the orientation tag in the EXIF segment of a JPEG.
+// Images without one are shown as they are stored.
+func readOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+
+	for offset := 2; offset+4 <= len(data); {
+		if data[offset] != 0xFF {
+			return 1
+		}
+
+		marker := data[offset+1]
+
+		if marker == 0xDA || marker == 0xD9 {
+			return 1
+		}
+
+		size := int(binary.BigEndian.Uint16(data[offset+2 : offset+4]))
+
+		if size < 2 || offset+2+size > len(data) {
+			return 1
+		}
+
+		segment := data[offset+4 : offset+2+size]
+
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return readTIFFOrientation(segment[6:])
+		}
+
+		offset += 2 + size
+	}
+
+	return 1
+}
+
+func readTIFFOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+
+	var order binary.ByteOrder
+
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	ifd := int64(order.Uint32(tiff[4:8]))
+
+	if ifd+2 > int64(len(tiff)) {
+		return 1
+	}
+
+	entries := int(order.Uint16(tiff[ifd:]))
+
+	for i := 0; i < entries; i++ {
+		entry := int(ifd) + 2 + i*12
+
+		if entry+12 > len(tiff) {
+			return 1
+		}
+
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			return int(order.Uint16(tiff[entry+8:]))
+		}
+	}
+
+	return 1
+}
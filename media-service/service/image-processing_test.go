@@ -0,0 +1,216 @@
+package service
+
+import (
+	"encoding/binary"
+	"image"
+	"reflect"
+	"testing"
+)
+
+// grey makes an image whose pixels have the given grey values, row by row.
+func grey(rows [][]uint8) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, len(rows[0]), len(rows)))
+
+	for y, row := range rows {
+		for x, value := range row {
+			copy(img.Pix[y*img.Stride+x*4:], []uint8{value, value, value, 255})
+		}
+	}
+
+	return img
+}
+
+// greyValues reads back the grey values of an image made by grey.
+func greyValues(img *image.RGBA) [][]uint8 {
+	rows := make([][]uint8, img.Bounds().Dy())
+
+	for y := range rows {
+		rows[y] = make([]uint8, img.Bounds().Dx())
+
+		for x := range rows[y] {
+			rows[y][x] = img.Pix[y*img.Stride+x*4]
+		}
+	}
+
+	return rows
+}
+
+func TestOrient(t *testing.T) {
+	src := [][]uint8{
+		{0, 1, 2},
+		{3, 4, 5},
+	}
+
+	tests := []struct {
+		name        string
+		orientation int
+		want        [][]uint8
+	}{
+		{name: "unknown", orientation: 0, want: [][]uint8{{0, 1, 2}, {3, 4, 5}}},
+		{name: "normal", orientation: 1, want: [][]uint8{{0, 1, 2}, {3, 4, 5}}},
+		{name: "mirrored", orientation: 2, want: [][]uint8{{2, 1, 0}, {5, 4, 3}}},
+		{name: "upside down", orientation: 3, want: [][]uint8{{5, 4, 3}, {2, 1, 0}}},
+		{name: "flipped", orientation: 4, want: [][]uint8{{3, 4, 5}, {0, 1, 2}}},
+		{name: "transposed", orientation: 5, want: [][]uint8{{0, 3}, {1, 4}, {2, 5}}},
+		{name: "turned right", orientation: 6, want: [][]uint8{{3, 0}, {4, 1}, {5, 2}}},
+		{name: "transversed", orientation: 7, want: [][]uint8{{5, 2}, {4, 1}, {3, 0}}},
+		{name: "turned left", orientation: 8, want: [][]uint8{{2, 5}, {1, 4}, {0, 3}}},
+		{name: "out of range", orientation: 9, want: [][]uint8{{0, 1, 2}, {3, 4, 5}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := greyValues(orient(grey(src), test.orientation)); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("orient() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestResize(t *testing.T) {
+	tests := []struct {
+		name string
+		src  [][]uint8
+		size int
+		want [][]uint8
+	}{
+		{
+			name: "small images aren't scaled up",
+			src:  [][]uint8{{10, 20}, {30, 40}},
+			size: 4,
+			want: [][]uint8{{10, 20}, {30, 40}},
+		},
+		{
+			name: "wide image",
+			src:  [][]uint8{{0, 10, 100, 200}, {20, 30, 100, 100}},
+			size: 2,
+			want: [][]uint8{{15, 125}},
+		},
+		{
+			name: "tall image",
+			src:  [][]uint8{{0, 4}, {8, 12}, {50, 50}, {50, 54}},
+			size: 2,
+			want: [][]uint8{{6}, {51}},
+		},
+		{
+			name: "strip keeps a pixel of height",
+			src:  [][]uint8{{0, 2, 4, 6, 8, 10, 12, 14}},
+			size: 2,
+			want: [][]uint8{{3, 11}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := greyValues(resize(grey(test.src), test.size)); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("resize() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+// tiffWithOrientation makes a TIFF header with an IFD holding the image width
+// and, unless it is 0, the orientation.
+func tiffWithOrientation(order binary.ByteOrder, orientation uint16) []byte {
+	tiff := make([]byte, 8, 64)
+
+	if order == binary.LittleEndian {
+		copy(tiff, "II")
+	} else {
+		copy(tiff, "MM")
+	}
+
+	order.PutUint16(tiff[2:], 42)
+	order.PutUint32(tiff[4:], 8)
+
+	tags := [][2]uint16{{0x0100, 640}}
+
+	if orientation != 0 {
+		tags = append(tags, [2]uint16{0x0112, orientation})
+	}
+
+	tiff = append(tiff, 0, 0)
+	order.PutUint16(tiff[8:], uint16(len(tags)))
+
+	for _, tag := range tags {
+		entry := make([]byte, 12)
+		order.PutUint16(entry[0:], tag[0])
+		order.PutUint16(entry[2:], 3)
+		order.PutUint32(entry[4:], 1)
+		order.PutUint16(entry[8:], tag[1])
+		tiff = append(tiff, entry...)
+	}
+
+	return tiff
+}
+
+func TestReadTIFFOrientation(t *testing.T) {
+	tests := []struct {
+		name string
+		tiff []byte
+		want int
+	}{
+		{name: "little endian", tiff: tiffWithOrientation(binary.LittleEndian, 6), want: 6},
+		{name: "big endian", tiff: tiffWithOrientation(binary.BigEndian, 8), want: 8},
+		{name: "no orientation tag", tiff: tiffWithOrientation(binary.LittleEndian, 0), want: 1},
+		{name: "truncated entries", tiff: tiffWithOrientation(binary.BigEndian, 3)[:20], want: 1},
+		{name: "ifd past the end", tiff: []byte("II*\x00\xff\x00\x00\x00"), want: 1},
+		{name: "unknown byte order", tiff: []byte("XX*\x00\x08\x00\x00\x00\x00\x00"), want: 1},
+		{name: "too short", tiff: []byte("II*\x00"), want: 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := readTIFFOrientation(test.tiff); got != test.want {
+				t.Errorf("readTIFFOrientation() = %d, want %d", got, test.want)
+			}
+		})
+	}
+}
+
+// jpegSegment makes a JPEG marker segment with the given payload.
+func jpegSegment(marker byte, data []byte) []byte {
+	segment := []byte{0xFF, marker, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(data)+2))
+
+	return append(segment, data...)
+}
+
+func TestReadOrientation(t *testing.T) {
+	start := []byte{0xFF, 0xD8}
+	jfif := jpegSegment(0xE0, []byte("JFIF\x00\x01\x01\x00\x00\x01\x00\x01\x00\x00"))
+	exif := jpegSegment(0xE1, append([]byte("Exif\x00\x00"), tiffWithOrientation(binary.BigEndian, 6)...))
+	scan := jpegSegment(0xDA, []byte{0, 0})
+
+	join := func(parts ...[]byte) []byte {
+		var data []byte
+
+		for _, part := range parts {
+			data = append(data, part...)
+		}
+
+		return data
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+		want int
+	}{
+		{name: "exif segment", data: join(start, exif, scan), want: 6},
+		{name: "exif after jfif", data: join(start, jfif, exif, scan), want: 6},
+		{name: "no exif segment", data: join(start, jfif, scan), want: 1},
+		{name: "exif after the scan", data: join(start, scan, exif), want: 1},
+		{name: "other app1 segment", data: join(start, jpegSegment(0xE1, []byte("http://ns.adobe.com/xap/1.0/\x00")), scan), want: 1},
+		{name: "truncated segment", data: join(start, exif[:10]), want: 1},
+		{name: "not a jpeg", data: []byte("\x89PNG\r\n\x1a\n"), want: 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := readOrientation(test.data); got != test.want {
+				t.Errorf("readOrientation() = %d, want %d", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"encoding/binary"
+	"os"
+
+	"github.com/KristijanPill/Nishtagram/media-service/payload"
+)
+
+const (
+	imageMediaType    = "image"
+	videoMediaType    = "video"
+	documentMediaType = "document"
+)
+
+// readVideoMetadata describes a stored video. Dimensions and duration are best
+// effort: they are read from the movie and track headers of MP4 and QuickTime
+// files, and WebM videos are stored without them.
+func readVideoMetadata(path string, mediaType string) *payload.MediaMetadata {
+	metadata := &payload.MediaMetadata{Path: path, Type: videoMediaType}
+
+	if mediaType != "video/mp4" && mediaType != "video/quicktime" {
+		return metadata
+	}
+
+	file, err := os.Open("." + path)
+
+	if err != nil {
+		return metadata
+	}
+
+	defer file.Close()
+
+	info, err := file.Stat()
+
+	if err != nil {
+		return metadata
+	}
+
+	walkBoxes(file, 0, info.Size(), metadata)
+
+	return metadata
+}
+
+func walkBoxes(file *os.File, start int64, end int64, metadata *payload.MediaMetadata) {
+	header := make([]byte, 16)
+
+	for offset := start; offset+8 <= end; {
+		if _, err := file.ReadAt(header[:8], offset); err != nil {
+			return
+		}
+
+		size := int64(binary.BigEndian.Uint32(header[:4]))
+		boxType := string(header[4:8])
+		headerSize := int64(8)
+
+		switch size {
+		case 0:
+			size = end - offset
+		case 1:
+			if _, err := file.ReadAt(header[8:16], offset+8); err != nil {
+				return
+			}
+
+			size = int64(binary.BigEndian.Uint64(header[8:16]))
+			headerSize = 16
+		}
+
+		if size < headerSize || offset+size > end {
+			return
+		}
+
+		switch boxType {
+		case "moov", "trak":
+			walkBoxes(file, offset+headerSize, offset+size, metadata)
+		case "mvhd":
+			readMovieHeader(file, offset+headerSize, metadata)
+		case "tkhd":
+			if metadata.Width == 0 {
+				readTrackHeader(file, offset+headerSize, metadata)
+			}
+		}
+
+		offset += size
+	}
+}
+
+func readMovieHeader(file *os.File, offset int64, metadata *payload.MediaMetadata) {
+	box := make([]byte, 32)
+
+	if _, err := file.ReadAt(box, offset); err != nil {
+		return
+	}
+
+	var timescale uint32
+	var duration uint64
+
+	if box[0] == 1 {
+		timescale = binary.BigEndian.Uint32(box[20:24])
+		duration = binary.BigEndian.Uint64(box[24:32])
+	} else {
+		timescale = binary.BigEndian.Uint32(box[12:16])
+		duration = uint64(binary.BigEndian.Uint32(box[16:20]))
+	}
+
+	if timescale != 0 {
+		metadata.Duration = float64(duration) / float64(timescale)
+	}
+}
+
+func readTrackHeader(file *os.File, offset int64, metadata *payload.MediaMetadata) {
+	box := make([]byte, 96)
+
+	if _, err := file.ReadAt(box, offset); err != nil {
+		return
+	}
+
+	sizeOffset := 76
+
+	if box[0] == 1 {
+		sizeOffset = 88
+	}
+
+	metadata.Width = int(binary.BigEndian.Uint32(box[sizeOffset:sizeOffset+4]) >> 16)
+	metadata.Height = int(binary.BigEndian.Uint32(box[sizeOffset+4:sizeOffset+8]) >> 16)
+}
@@ -1,105 +1,243 @@
 package service
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
 	"os"
-	"strings"
+	"path"
 
+	"github.com/KristijanPill/Nishtagram/media-service/payload"
 	"github.com/google/uuid"
 )
 
-type MediaService struct{}
+type MediaService struct {
+	quota *StorageQuota
+}
 
 const (
-	postPath           = "/storage/posts/"
-	storyPath          = "/storage/stories/"
-	profilePicturePath = "/storage/profile_pictures/"
+	postPath            = "/storage/posts/"
+	storyPath           = "/storage/stories/"
+	profilePicturePath  = "/storage/profile_pictures/"
 	documentPicturePath = "/storage/documents/"
 )
 
-func NewMediaService() *MediaService {
-	return &MediaService{}
+var (
+	ErrInvalidMediaPath     = errors.New("invalid media path")
+	ErrNoMedia              = errors.New("no media was uploaded")
+	ErrTooManyFiles         = errors.New("too many files")
+	ErrFileTooLarge         = errors.New("the file is too large")
+	ErrUnsupportedMediaType = errors.New("unsupported media type")
+	ErrInvalidImage         = errors.New("the image could not be read")
+)
+
+func NewMediaService(quota *StorageQuota) *MediaService {
+	return &MediaService{quota: quota}
 }
 
-func (service *MediaService) CreatePost(image *multipart.File, filename string) (string, error) {
-	mediaName := uuid.New().String() + "." + service.extractFileExtension(filename)
+// Upload checks the files against the policy of the upload kind and the
+// user's storage quota, and stores them named after their detected type rather
+// than the name the client gave them. Images are processed before they are
+// described, so the metadata is that of the stored copy.
+func (service *MediaService) Upload(kind UploadKind, userID string, files []*multipart.FileHeader) ([]payload.MediaMetadata, error) {
+	policy := uploadPolicies[kind]
 
-	destination, err := os.Create("." + postPath + mediaName)
-	defer destination.Close()
+	if len(files) == 0 {
+		return nil, ErrNoMedia
+	}
 
-	if err != nil {
-		fmt.Println(err.Error())
-		return "", err
+	if len(files) > policy.maxFiles {
+		return nil, fmt.Errorf("%w: at most %d per %s", ErrTooManyFiles, policy.maxFiles, kind)
+	}
+
+	var mediaTypes []string
+	var extensions []string
+	var totalSize int64
+
+	for _, file := range files {
+		if file.Size > policy.maxFileSize {
+			return nil, fmt.Errorf("%w: a %s can be at most %d MB", ErrFileTooLarge, kind, policy.maxFileSize/megabyte)
+		}
+
+		mediaType, err := sniffFile(file)
+
+		if err != nil {
+			return nil, err
+		}
+
+		extension, allowed := policy.formats[mediaType]
+
+		if !allowed {
+			return nil, fmt.Errorf("%w: %s is not allowed for a %s", ErrUnsupportedMediaType, mediaType, kind)
+		}
+
+		mediaTypes = append(mediaTypes, mediaType)
+		extensions = append(extensions, extension)
+		totalSize += file.Size
+	}
+
+	if err := service.quota.Reserve(userID, totalSize); err != nil {
+		return nil, err
 	}
 
-	if _, err := io.Copy(destination, *image); err != nil {
+	var media []payload.MediaMetadata
+	stored := make(map[string]int64)
+
+	for i, file := range files {
+		mediaPath := policy.directory + uuid.New().String() + "." + extensions[i]
+		metadata, err := storeMedia(file, mediaPath, mediaTypes[i], policy, stored)
+
+		if err != nil {
+			for storedPath := range stored {
+				removeMedia(storedPath)
+			}
+
+			service.quota.Release(userID, totalSize)
+
+			return nil, err
+		}
+
+		media = append(media, *metadata)
+	}
+
+	if err := service.quota.Record(userID, totalSize, stored); err != nil {
 		fmt.Println(err.Error())
-		return "", err
 	}
 
-	return postPath + mediaName, nil
+	return media, nil
 }
 
-func (service *MediaService) CreateStory(image *multipart.File, filename string) (string, error) {
-	mediaName := uuid.New().String() + "." + service.extractFileExtension(filename)
+func (service *MediaService) StorageUsage(userID string) *payload.StorageUsage {
+	return &payload.StorageUsage{Used: service.quota.Usage(userID), Quota: UserStorageQuota}
+}
 
-	destination, err := os.Create("." + storyPath + mediaName)
-	defer destination.Close()
+// DeleteStories removes story media together with their renditions. Only files
+// directly in the stories folder can be deleted, and files that are already
+// gone are skipped.
+func (service *MediaService) DeleteStories(paths []string) error {
+	for _, mediaPath := range paths {
+		cleanPath := path.Clean(mediaPath)
+
+		if path.Dir(cleanPath)+"/" != storyPath {
+			return ErrInvalidMediaPath
+		}
+	}
+
+	var removed []string
+
+	for _, mediaPath := range paths {
+		mediaPaths, err := removeMedia(path.Clean(mediaPath))
+
+		if err != nil {
+			return err
+		}
+
+		removed = append(removed, mediaPaths...)
+	}
+
+	return service.quota.Remove(removed)
+}
+
+// storeMedia stores a single upload and describes it, adding every file it
+// wrote to stored.
+func storeMedia(file *multipart.FileHeader, mediaPath string, mediaType string, policy uploadPolicy, stored map[string]int64) (*payload.MediaMetadata, error) {
+	size, err := storeFile(file, mediaPath, policy.maxFileSize)
 
 	if err != nil {
-		fmt.Println(err.Error())
-		return "", err
+		return nil, err
 	}
 
-	if _, err := io.Copy(destination, *image); err != nil {
-		fmt.Println(err.Error())
-		return "", err
+	stored[mediaPath] = size
+
+	if _, isVideo := videoFormats[mediaType]; isVideo {
+		return readVideoMetadata(mediaPath, mediaType), nil
+	}
+
+	if _, isImage := imageFormats[mediaType]; !isImage {
+		return &payload.MediaMetadata{Path: mediaPath, Type: documentMediaType}, nil
 	}
 
-	return storyPath + mediaName, nil
+	metadata, written, err := processImage(mediaPath, policy.renditions)
+
+	for writtenPath, size := range written {
+		stored[writtenPath] = size
+	}
+
+	return metadata, err
 }
 
-func (service *MediaService) UploadProfilePicture(image *multipart.File, filename string) (string, error) {
-	mediaName := uuid.New().String() + "." + service.extractFileExtension(filename)
+// removeMedia removes a stored file and its renditions, and returns the paths
+// of the files that were there.
+func removeMedia(mediaPath string) ([]string, error) {
+	var removed []string
 
-	destination, err := os.Create("." + profilePicturePath + mediaName)
-	defer destination.Close()
+	for _, filePath := range append([]string{mediaPath}, renditionPaths(mediaPath)...) {
+		err := os.Remove("." + filePath)
+
+		if os.IsNotExist(err) {
+			continue
+		}
+
+		if err != nil {
+			return removed, err
+		}
+
+		removed = append(removed, filePath)
+	}
+
+	return removed, nil
+}
+
+func sniffFile(file *multipart.FileHeader) (string, error) {
+	source, err := file.Open()
 
 	if err != nil {
-		fmt.Println(err.Error())
 		return "", err
 	}
 
-	if _, err := io.Copy(destination, *image); err != nil {
-		fmt.Println(err.Error())
-		return "", err
+	defer source.Close()
+
+	header := make([]byte, sniffLength)
+	n, err := io.ReadFull(source, header)
+
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return "", ErrNoMedia
 	}
 
-	return profilePicturePath + mediaName, nil
+	return detectMediaType(header[:n]), nil
 }
 
-func (*MediaService) extractFileExtension(filename string) string {
-	parts := strings.Split(filename, ".")
-	return parts[len(parts)-1]
-}
+// storeFile copies the upload to the storage. The copy stops at the size limit
+// in case the size the client declared for the file was wrong.
+func storeFile(file *multipart.FileHeader, mediaPath string, maxSize int64) (int64, error) {
+	source, err := file.Open()
+
+	if err != nil {
+		return 0, err
+	}
 
-func (service *MediaService) UploadDocumentPicture(image *multipart.File, filename string) (string, error) {
-	mediaName := uuid.New().String() + "." + service.extractFileExtension(filename)
+	defer source.Close()
 
-	destination, err := os.Create("." + documentPicturePath + mediaName)
-	defer destination.Close()
+	destination, err := os.Create("." + mediaPath)
 
 	if err != nil {
-		fmt.Println(err.Error())
-		return "", err
+		return 0, err
 	}
 
-	if _, err := io.Copy(destination, *image); err != nil {
-		fmt.Println(err.Error())
-		return "", err
+	defer destination.Close()
+
+	size, err := io.Copy(destination, io.LimitReader(source, maxSize+1))
+
+	if err == nil && size > maxSize {
+		err = ErrFileTooLarge
+	}
+
+	if err != nil {
+		os.Remove("." + mediaPath)
+
+		return 0, err
 	}
 
-	return documentPicturePath + mediaName, nil
+	return size, nil
 }
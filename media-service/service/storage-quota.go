@@ -0,0 +1,144 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// UserStorageQuota is how much storage the media of a single user can take.
+const UserStorageQuota = 1024 * megabyte
+
+var ErrQuotaExceeded = errors.New("you have used up your storage quota")
+
+type storedFile struct {
+	UserID string `json:"user_id"`
+	Size   int64  `json:"size"`
+}
+
+// StorageQuota keeps track of who uploaded which file and how big it is, so
+// the storage used by each user is known. The record is kept in a JSON file,
+// since media-service has no database. It must stay out of the storage folder,
+// which is served to anyone, as it tells who uploaded which file. Files
+// uploaded before the record existed don't count towards anyone's quota.
+type StorageQuota struct {
+	mutex    sync.Mutex
+	filePath string
+	files    map[string]storedFile
+	usage    map[string]int64
+}
+
+func NewStorageQuota(filePath string) (*StorageQuota, error) {
+	quota := &StorageQuota{
+		filePath: filePath,
+		files:    make(map[string]storedFile),
+		usage:    make(map[string]int64),
+	}
+
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return nil, err
+	}
+
+	data, err := ioutil.ReadFile(filePath)
+
+	if os.IsNotExist(err) {
+		return quota, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &quota.files); err != nil {
+		return nil, err
+	}
+
+	for _, file := range quota.files {
+		quota.usage[file.UserID] += file.Size
+	}
+
+	return quota, nil
+}
+
+func (quota *StorageQuota) Usage(userID string) int64 {
+	quota.mutex.Lock()
+	defer quota.mutex.Unlock()
+
+	return quota.usage[userID]
+}
+
+// Reserve sets aside space for an upload before it is stored, so uploads
+// running at the same time can't go over the quota together.
+func (quota *StorageQuota) Reserve(userID string, size int64) error {
+	quota.mutex.Lock()
+	defer quota.mutex.Unlock()
+
+	if quota.usage[userID]+size > UserStorageQuota {
+		return ErrQuotaExceeded
+	}
+
+	quota.usage[userID] += size
+
+	return nil
+}
+
+// Release gives back space reserved for an upload that failed.
+func (quota *StorageQuota) Release(userID string, size int64) {
+	quota.mutex.Lock()
+	defer quota.mutex.Unlock()
+
+	quota.usage[userID] -= size
+}
+
+// Record replaces the reservation of an upload with the files it stored.
+func (quota *StorageQuota) Record(userID string, reserved int64, files map[string]int64) error {
+	quota.mutex.Lock()
+	defer quota.mutex.Unlock()
+
+	quota.usage[userID] -= reserved
+
+	for path, size := range files {
+		quota.files[path] = storedFile{UserID: userID, Size: size}
+		quota.usage[userID] += size
+	}
+
+	return quota.save()
+}
+
+// Remove frees the space of deleted files.
+func (quota *StorageQuota) Remove(paths []string) error {
+	quota.mutex.Lock()
+	defer quota.mutex.Unlock()
+
+	for _, path := range paths {
+		file, found := quota.files[path]
+
+		if !found {
+			continue
+		}
+
+		quota.usage[file.UserID] -= file.Size
+		delete(quota.files, path)
+	}
+
+	return quota.save()
+}
+
+// save writes the record to a temporary file first, so a crash while saving
+// can't leave it half written.
+func (quota *StorageQuota) save() error {
+	data, err := json.Marshal(quota.files)
+
+	if err != nil {
+		return err
+	}
+
+	if err := ioutil.WriteFile(quota.filePath+".tmp", data, 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(quota.filePath+".tmp", quota.filePath)
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestStorageQuotaReserve(t *testing.T) {
+	tests := []struct {
+		name      string
+		used      int64
+		size      int64
+		wantErr   error
+		wantUsage int64
+	}{
+		{name: "fits", used: 0, size: 10 * megabyte, wantErr: nil, wantUsage: 10 * megabyte},
+		{name: "fills the quota exactly", used: UserStorageQuota - megabyte, size: megabyte, wantErr: nil, wantUsage: UserStorageQuota},
+		{name: "over the quota", used: UserStorageQuota - megabyte, size: megabyte + 1, wantErr: ErrQuotaExceeded, wantUsage: UserStorageQuota - megabyte},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			quota, err := NewStorageQuota(filepath.Join(t.TempDir(), "quota.json"))
+
+			if err != nil {
+				t.Fatalf("NewStorageQuota() error = %v", err)
+			}
+
+			if err := quota.Reserve("user", test.used); err != nil {
+				t.Fatalf("Reserve() error = %v", err)
+			}
+
+			if err := quota.Reserve("user", test.size); err != test.wantErr {
+				t.Errorf("Reserve() error = %v, want %v", err, test.wantErr)
+			}
+
+			if got := quota.Usage("user"); got != test.wantUsage {
+				t.Errorf("Usage() = %d, want %d", got, test.wantUsage)
+			}
+
+			if got := quota.Usage("someone else"); got != 0 {
+				t.Errorf("Usage() of another user = %d, want 0", got)
+			}
+		})
+	}
+}
+
+func TestStorageQuotaRelease(t *testing.T) {
+	quota, err := NewStorageQuota(filepath.Join(t.TempDir(), "quota.json"))
+
+	if err != nil {
+		t.Fatalf("NewStorageQuota() error = %v", err)
+	}
+
+	if err := quota.Reserve("user", UserStorageQuota); err != nil {
+		t.Fatalf("Reserve() error = %v", err)
+	}
+
+	quota.Release("user", UserStorageQuota)
+
+	if got := quota.Usage("user"); got != 0 {
+		t.Errorf("Usage() = %d, want 0", got)
+	}
+
+	if err := quota.Reserve("user", megabyte); err != nil {
+		t.Errorf("Reserve() after Release() error = %v", err)
+	}
+}
+
+func TestStorageQuotaRecordAndRemove(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "quota", "files.json")
+	quota, err := NewStorageQuota(filePath)
+
+	if err != nil {
+		t.Fatalf("NewStorageQuota() error = %v", err)
+	}
+
+	if err := quota.Reserve("alice", 300); err != nil {
+		t.Fatalf("Reserve() error = %v", err)
+	}
+
+	if err := quota.Record("alice", 300, map[string]int64{"post/a.jpg": 120, "post/a_thumb.jpg": 20}); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+
+	if err := quota.Reserve("bob", 50); err != nil {
+		t.Fatalf("Reserve() error = %v", err)
+	}
+
+	if err := quota.Record("bob", 50, map[string]int64{"story/b.mp4": 50}); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+
+	if got := quota.Usage("alice"); got != 140 {
+		t.Errorf("Usage() after Record() = %d, want 140", got)
+	}
+
+	reloaded, err := NewStorageQuota(filePath)
+
+	if err != nil {
+		t.Fatalf("NewStorageQuota() reload error = %v", err)
+	}
+
+	if got := reloaded.Usage("alice"); got != 140 {
+		t.Errorf("Usage() after reload = %d, want 140", got)
+	}
+
+	if got := reloaded.Usage("bob"); got != 50 {
+		t.Errorf("Usage() of bob after reload = %d, want 50", got)
+	}
+
+	if err := reloaded.Remove([]string{"post/a_thumb.jpg", "post/unknown.jpg", "story/b.mp4"}); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+
+	if got := reloaded.Usage("alice"); got != 120 {
+		t.Errorf("Usage() after Remove() = %d, want 120", got)
+	}
+
+	if got := reloaded.Usage("bob"); got != 0 {
+		t.Errorf("Usage() of bob after Remove() = %d, want 0", got)
+	}
+
+	again, err := NewStorageQuota(filePath)
+
+	if err != nil {
+		t.Fatalf("NewStorageQuota() reload error = %v", err)
+	}
+
+	if got := again.Usage("alice"); got != 120 {
+		t.Errorf("Usage() after Remove() and reload = %d, want 120", got)
+	}
+}
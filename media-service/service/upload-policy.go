@@ -0,0 +1,111 @@
+package service
+
+import (
+	"net/http"
+	"strings"
+)
+
+type UploadKind string
+
+const (
+	PostUpload           UploadKind = "post"
+	StoryUpload          UploadKind = "story"
+	ProfilePictureUpload UploadKind = "profile picture"
+	DocumentUpload       UploadKind = "document"
+)
+
+const megabyte = 1 << 20
+
+// MaxRequestSize matches client_max_body_size of the api gateway.
+const MaxRequestSize = 50 * megabyte
+
+const sniffLength = 512
+
+var (
+	imageFormats = map[string]string{
+		"image/jpeg": "jpg",
+		"image/png":  "png",
+		"image/gif":  "gif",
+	}
+	videoFormats = map[string]string{
+		"video/mp4":       "mp4",
+		"video/quicktime": "mov",
+		"video/webm":      "webm",
+	}
+)
+
+// uploadPolicy says what can be uploaded as one kind of media. Formats map
+// the allowed MIME types to the extension the file is stored with. Images of
+// kinds with renditions also get a thumbnail and a feed sized copy.
+type uploadPolicy struct {
+	directory   string
+	formats     map[string]string
+	maxFiles    int
+	maxFileSize int64
+	renditions  bool
+}
+
+var uploadPolicies = map[UploadKind]uploadPolicy{
+	PostUpload: {
+		directory:   postPath,
+		formats:     mergeFormats(imageFormats, videoFormats),
+		maxFiles:    10,
+		maxFileSize: 50 * megabyte,
+		renditions:  true,
+	},
+	StoryUpload: {
+		directory:   storyPath,
+		formats:     mergeFormats(imageFormats, videoFormats),
+		maxFiles:    10,
+		maxFileSize: 50 * megabyte,
+		renditions:  true,
+	},
+	ProfilePictureUpload: {
+		directory:   profilePicturePath,
+		formats:     map[string]string{"image/jpeg": "jpg", "image/png": "png"},
+		maxFiles:    1,
+		maxFileSize: 5 * megabyte,
+	},
+	DocumentUpload: {
+		directory:   documentPicturePath,
+		formats:     map[string]string{"image/jpeg": "jpg", "image/png": "png", "application/pdf": "pdf"},
+		maxFiles:    1,
+		maxFileSize: 10 * megabyte,
+	},
+}
+
+func mergeFormats(formatSets ...map[string]string) map[string]string {
+	merged := make(map[string]string)
+
+	for _, formats := range formatSets {
+		for mimeType, extension := range formats {
+			merged[mimeType] = extension
+		}
+	}
+
+	return merged
+}
+
+// detectMediaType tells the type of a file from its first bytes. ISO media
+// files are told apart by their brand, since http.DetectContentType only
+// recognises some of the MP4 brands and none of QuickTime, HEIC or AVIF.
+func detectMediaType(header []byte) string {
+	if len(header) >= 12 && string(header[4:8]) == "ftyp" {
+		switch brand := string(header[8:12]); {
+		case brand == "qt  ":
+			return "video/quicktime"
+		case brand == "heic" || brand == "heix" || brand == "mif1" || brand == "msf1":
+			return "image/heic"
+		case brand == "avif" || brand == "avis":
+			return "image/avif"
+		case strings.HasPrefix(brand, "3g"):
+			return "video/3gpp"
+		default:
+			return "video/mp4"
+		}
+	}
+
+	mediaType := http.DetectContentType(header)
+
+	return strings.TrimSpace(strings.Split(mediaType, ";")[0])
+}
@@ -0,0 +1,41 @@
+package service
+
+import "testing"
+
+func TestDetectMediaType(t *testing.T) {
+	ftyp := func(brand string) []byte {
+		return append([]byte("\x00\x00\x00\x18ftyp"), brand+"\x00\x00\x02\x00"...)
+	}
+
+	tests := []struct {
+		name   string
+		header []byte
+		want   string
+	}{
+		{name: "quicktime", header: ftyp("qt  "), want: "video/quicktime"},
+		{name: "heic", header: ftyp("heic"), want: "image/heic"},
+		{name: "heic sequence", header: ftyp("msf1"), want: "image/heic"},
+		{name: "heif", header: ftyp("mif1"), want: "image/heic"},
+		{name: "avif", header: ftyp("avif"), want: "image/avif"},
+		{name: "3gp", header: ftyp("3gp5"), want: "video/3gpp"},
+		{name: "mp4", header: ftyp("isom"), want: "video/mp4"},
+		{name: "other iso brand", header: ftyp("M4V "), want: "video/mp4"},
+		{name: "truncated ftyp box", header: []byte("\x00\x00\x00\x18ftyp"), want: "application/octet-stream"},
+		{name: "jpeg", header: []byte("\xff\xd8\xff\xe0\x00\x10JFIF\x00"), want: "image/jpeg"},
+		{name: "png", header: []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"), want: "image/png"},
+		{name: "gif", header: []byte("GIF89a\x01\x00\x01\x00"), want: "image/gif"},
+		{name: "pdf", header: []byte("%PDF-1.4\n"), want: "application/pdf"},
+		{name: "webm", header: []byte("\x1a\x45\xdf\xa3\x9f\x42\x86\x81\x01"), want: "video/webm"},
+		{name: "plain text", header: []byte("just some text"), want: "text/plain"},
+		{name: "charset of html is dropped", header: []byte("<!DOCTYPE html><html><body>hi</body></html>"), want: "text/html"},
+		{name: "empty", header: nil, want: "text/plain"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := detectMediaType(test.header); got != test.want {
+				t.Errorf("detectMediaType() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
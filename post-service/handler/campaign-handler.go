@@ -0,0 +1,248 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/KristijanPill/Nishtagram/post-service/helpers"
+	"github.com/KristijanPill/Nishtagram/post-service/middleware"
+	"github.com/KristijanPill/Nishtagram/post-service/payload"
+	"github.com/KristijanPill/Nishtagram/post-service/service"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+const maxSponsoredStories = 10
+
+type CampaignHandler struct {
+	service *service.CampaignService
+}
+
+func NewCampaignHandler(service *service.CampaignService) *CampaignHandler {
+	return &CampaignHandler{service: service}
+}
+
+func (handler *CampaignHandler) Create(w http.ResponseWriter, r *http.Request) {
+	dto := &payload.CampaignCreate{}
+	err := helpers.FromJSON(&dto, r.Body)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	claims := r.Context().Value(middleware.TokenKey{}).(jwt.MapClaims)
+
+	agentIDString := helpers.ExtractClaim("sub", claims)
+	agentID, err := uuid.Parse(agentIDString)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	campaign, err := handler.service.Create(agentID, dto)
+
+	if writeCampaignError(w, err) {
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	helpers.ToJSON(campaign, w)
+}
+
+func (handler *CampaignHandler) FindAll(w http.ResponseWriter, r *http.Request) {
+	claims := r.Context().Value(middleware.TokenKey{}).(jwt.MapClaims)
+
+	agentIDString := helpers.ExtractClaim("sub", claims)
+	agentID, err := uuid.Parse(agentIDString)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	page, size := helpers.ExtractPagination(r)
+
+	campaigns, err := handler.service.FindAll(agentID, page, size)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	helpers.ToJSON(&campaigns, w)
+}
+
+func (handler *CampaignHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	campaignID, err := uuid.Parse(vars["id"])
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	claims := r.Context().Value(middleware.TokenKey{}).(jwt.MapClaims)
+
+	agentIDString := helpers.ExtractClaim("sub", claims)
+	agentID, err := uuid.Parse(agentIDString)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	err = handler.service.Delete(campaignID, agentID)
+
+	if writeCampaignError(w, err) {
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (handler *CampaignHandler) GetStats(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	campaignID, err := uuid.Parse(vars["id"])
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	claims := r.Context().Value(middleware.TokenKey{}).(jwt.MapClaims)
+
+	agentIDString := helpers.ExtractClaim("sub", claims)
+	agentID, err := uuid.Parse(agentIDString)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	stats, err := handler.service.GetStats(campaignID, agentID)
+
+	if writeCampaignError(w, err) {
+		return
+	}
+
+	helpers.ToJSON(stats, w)
+}
+
+func (handler *CampaignHandler) Click(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	campaignID, err := uuid.Parse(vars["id"])
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	dto := &payload.CampaignClick{}
+	err = helpers.FromJSON(&dto, r.Body)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	claims := r.Context().Value(middleware.TokenKey{}).(jwt.MapClaims)
+
+	userIDString := helpers.ExtractClaim("sub", claims)
+	userID, err := uuid.Parse(userIDString)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	err = handler.service.Click(campaignID, userID, dto.Placement)
+
+	if writeCampaignError(w, err) {
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// FindSponsoredStories is called by story-service while building the story tray
+// of the viewer.
+func (handler *CampaignHandler) FindSponsoredStories(w http.ResponseWriter, r *http.Request) {
+	if r.Context().Value(middleware.LoggedInUser{}) == nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+
+		return
+	}
+
+	viewerID := r.Context().Value(middleware.LoggedInUser{}).(uuid.UUID)
+
+	count, err := strconv.Atoi(r.URL.Query().Get("count"))
+
+	if err != nil || count < 1 || count > maxSponsoredStories {
+		count = 1
+	}
+
+	stories, err := handler.service.FindSponsoredStories(viewerID, count, helpers.ExtractTokenFromHeader(r.Header["Authorization"][0]))
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	helpers.ToJSON(&stories, w)
+}
+
+func (handler *CampaignHandler) FindPromotedStories(w http.ResponseWriter, r *http.Request) {
+	dto := &payload.ContentIDs{}
+	err := helpers.FromJSON(&dto, r.Body)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	promoted, err := handler.service.FindPromotedStories(dto.IDs)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	helpers.ToJSON(&payload.ContentIDs{IDs: promoted}, w)
+}
+
+func writeCampaignError(w http.ResponseWriter, err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if writeAccessError(w, err) {
+		return true
+	}
+
+	switch err {
+	case service.ErrInvalidCampaignContent, service.ErrInvalidCampaignSchedule,
+		service.ErrInvalidCampaignTargeting, service.ErrInvalidCampaignPlacement:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+
+	return true
+}
@@ -0,0 +1,298 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/KristijanPill/Nishtagram/post-service/helpers"
+	"github.com/KristijanPill/Nishtagram/post-service/middleware"
+	"github.com/KristijanPill/Nishtagram/post-service/payload"
+	"github.com/KristijanPill/Nishtagram/post-service/service"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+type CollectionHandler struct {
+	service *service.CollectionService
+}
+
+func NewCollectionHandler(service *service.CollectionService) *CollectionHandler {
+	return &CollectionHandler{service: service}
+}
+
+func (handler *CollectionHandler) Create(w http.ResponseWriter, r *http.Request) {
+	dto := &payload.CollectionCreate{}
+	err := helpers.FromJSON(&dto, r.Body)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	claims := r.Context().Value(middleware.TokenKey{}).(jwt.MapClaims)
+
+	userIDString := helpers.ExtractClaim("sub", claims)
+	userID, err := uuid.Parse(userIDString)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	collection, err := handler.service.Create(dto, userID)
+
+	if writeCollectionError(w, err) {
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	helpers.ToJSON(collection, w)
+}
+
+func (handler *CollectionHandler) FindAllByLoggedInUser(w http.ResponseWriter, r *http.Request) {
+	claims := r.Context().Value(middleware.TokenKey{}).(jwt.MapClaims)
+
+	userIDString := helpers.ExtractClaim("sub", claims)
+	userID, err := uuid.Parse(userIDString)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	collections, err := handler.service.FindAllByUser(userID)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	helpers.ToJSON(&collections, w)
+}
+
+func (handler *CollectionHandler) Update(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	collectionID, err := uuid.Parse(vars["id"])
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	dto := &payload.CollectionUpdate{}
+	err = helpers.FromJSON(&dto, r.Body)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	claims := r.Context().Value(middleware.TokenKey{}).(jwt.MapClaims)
+
+	userIDString := helpers.ExtractClaim("sub", claims)
+	userID, err := uuid.Parse(userIDString)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	collection, err := handler.service.Update(collectionID, dto, userID)
+
+	if writeCollectionError(w, err) {
+		return
+	}
+
+	helpers.ToJSON(collection, w)
+}
+
+func (handler *CollectionHandler) Reorder(w http.ResponseWriter, r *http.Request) {
+	dto := &payload.CollectionOrder{}
+	err := helpers.FromJSON(&dto, r.Body)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	claims := r.Context().Value(middleware.TokenKey{}).(jwt.MapClaims)
+
+	userIDString := helpers.ExtractClaim("sub", claims)
+	userID, err := uuid.Parse(userIDString)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	err = handler.service.Reorder(dto, userID)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+}
+
+func (handler *CollectionHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	collectionID, err := uuid.Parse(vars["id"])
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	claims := r.Context().Value(middleware.TokenKey{}).(jwt.MapClaims)
+
+	userIDString := helpers.ExtractClaim("sub", claims)
+	userID, err := uuid.Parse(userIDString)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	err = handler.service.Delete(collectionID, userID)
+
+	writeCollectionError(w, err)
+}
+
+func (handler *CollectionHandler) AddPost(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	collectionID, err := uuid.Parse(vars["id"])
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	dto := &payload.CollectionPostCreate{}
+	err = helpers.FromJSON(&dto, r.Body)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	claims := r.Context().Value(middleware.TokenKey{}).(jwt.MapClaims)
+
+	userIDString := helpers.ExtractClaim("sub", claims)
+	userID, err := uuid.Parse(userIDString)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	tokenString := helpers.ExtractTokenFromHeader(r.Header["Authorization"][0])
+
+	err = handler.service.AddPost(collectionID, dto.PostID, userID, tokenString)
+
+	writeCollectionError(w, err)
+}
+
+func (handler *CollectionHandler) RemovePost(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	collectionID, err := uuid.Parse(vars["id"])
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	postID, err := uuid.Parse(vars["postId"])
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	claims := r.Context().Value(middleware.TokenKey{}).(jwt.MapClaims)
+
+	userIDString := helpers.ExtractClaim("sub", claims)
+	userID, err := uuid.Parse(userIDString)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	err = handler.service.RemovePost(collectionID, postID, userID)
+
+	writeCollectionError(w, err)
+}
+
+func (handler *CollectionHandler) FindPosts(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	collectionID, err := uuid.Parse(vars["id"])
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	claims := r.Context().Value(middleware.TokenKey{}).(jwt.MapClaims)
+
+	userIDString := helpers.ExtractClaim("sub", claims)
+	userID, err := uuid.Parse(userIDString)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	page, size := helpers.ExtractPagination(r)
+	tokenString := helpers.ExtractTokenFromHeader(r.Header["Authorization"][0])
+
+	collectionPage, err := handler.service.FindPosts(collectionID, userID, page, size, tokenString)
+
+	if writeCollectionError(w, err) {
+		return
+	}
+
+	helpers.ToJSON(collectionPage, w)
+}
+
+// writeCollectionError writes the response for a failed collection operation
+// and reports whether there was an error to write.
+func writeCollectionError(w http.ResponseWriter, err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if writeAccessError(w, err) {
+		return true
+	}
+
+	switch {
+	case errors.Is(err, service.ErrInvalidCollectionName), errors.Is(err, service.ErrCoverNotInCollection):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, service.ErrCollectionNameTaken):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+
+	return true
+}
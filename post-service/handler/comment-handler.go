@@ -43,9 +43,19 @@ func (handler *CommentHandler) Create(w http.ResponseWriter, r *http.Request) {
 
 	dto.UserID = userID
 
-	_, err = handler.service.Create(dto)
+	_, err = handler.service.Create(dto, helpers.ExtractTokenFromHeader(r.Header["Authorization"][0]))
 
 	if err != nil {
+		if writeAccessError(w, err) {
+			return
+		}
+
+		if err == service.ErrCommentsDisabled {
+			http.Error(w, err.Error(), http.StatusForbidden)
+
+			return
+		}
+
 		http.Error(w, err.Error(), http.StatusBadRequest)
 
 		return
@@ -64,9 +74,23 @@ func (handler *CommentHandler) FindAllByPostID(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	comments, err := handler.service.FindAllByPostID(postID)
+	var loggedInUserID uuid.UUID
+	if r.Context().Value(middleware.LoggedInUser{}) != nil {
+		loggedInUserID = r.Context().Value(middleware.LoggedInUser{}).(uuid.UUID)
+	}
+
+	var tokenString string
+	if loggedInUserID != uuid.Nil {
+		tokenString = helpers.ExtractTokenFromHeader(r.Header["Authorization"][0])
+	}
+
+	comments, err := handler.service.FindAllByPostID(postID, loggedInUserID, tokenString)
 
 	if err != nil {
+		if writeAccessError(w, err) {
+			return
+		}
+
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 
 		return
@@ -74,3 +98,134 @@ func (handler *CommentHandler) FindAllByPostID(w http.ResponseWriter, r *http.Re
 
 	helpers.ToJSON(comments, w)
 }
+
+func (handler *CommentHandler) FindHeld(w http.ResponseWriter, r *http.Request) {
+	claims := r.Context().Value(middleware.TokenKey{}).(jwt.MapClaims)
+
+	userIDString := helpers.ExtractClaim("sub", claims)
+	userID, err := uuid.Parse(userIDString)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	var postID uuid.UUID
+
+	if r.URL.Query().Get("post_id") != "" {
+		postID, err = uuid.Parse(r.URL.Query().Get("post_id"))
+
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+
+			return
+		}
+	}
+
+	page, size := helpers.ExtractPagination(r)
+	tokenString := helpers.ExtractTokenFromHeader(r.Header["Authorization"][0])
+
+	comments, err := handler.service.FindHeld(userID, postID, page, size, tokenString)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	helpers.ToJSON(&comments, w)
+}
+
+func (handler *CommentHandler) ReviewHeld(w http.ResponseWriter, r *http.Request) {
+	dto := &payload.HeldCommentReview{}
+	err := helpers.FromJSON(&dto, r.Body)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	claims := r.Context().Value(middleware.TokenKey{}).(jwt.MapClaims)
+
+	userIDString := helpers.ExtractClaim("sub", claims)
+	userID, err := uuid.Parse(userIDString)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	result, err := handler.service.ReviewHeld(userID, dto)
+
+	if err == service.ErrInvalidHeldCommentAction {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	helpers.ToJSON(result, w)
+}
+
+func (handler *CommentHandler) GetKeywords(w http.ResponseWriter, r *http.Request) {
+	claims := r.Context().Value(middleware.TokenKey{}).(jwt.MapClaims)
+
+	userIDString := helpers.ExtractClaim("sub", claims)
+	userID, err := uuid.Parse(userIDString)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	keywords, err := handler.service.GetKeywords(userID)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	helpers.ToJSON(keywords, w)
+}
+
+func (handler *CommentHandler) SetKeywords(w http.ResponseWriter, r *http.Request) {
+	dto := &payload.FilteredKeywords{}
+	err := helpers.FromJSON(&dto, r.Body)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	claims := r.Context().Value(middleware.TokenKey{}).(jwt.MapClaims)
+
+	userIDString := helpers.ExtractClaim("sub", claims)
+	userID, err := uuid.Parse(userIDString)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	keywords, err := handler.service.SetKeywords(userID, dto)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	helpers.ToJSON(keywords, w)
+}
@@ -0,0 +1,169 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/KristijanPill/Nishtagram/post-service/helpers"
+	"github.com/KristijanPill/Nishtagram/post-service/middleware"
+	"github.com/KristijanPill/Nishtagram/post-service/model"
+	"github.com/KristijanPill/Nishtagram/post-service/payload"
+	"github.com/KristijanPill/Nishtagram/post-service/service"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+type DraftHandler struct {
+	service *service.DraftService
+}
+
+func NewDraftHandler(service *service.DraftService) *DraftHandler {
+	return &DraftHandler{service: service}
+}
+
+func (handler *DraftHandler) FindAllByLoggedInUser(w http.ResponseWriter, r *http.Request) {
+	claims := r.Context().Value(middleware.TokenKey{}).(jwt.MapClaims)
+
+	userIDString := helpers.ExtractClaim("sub", claims)
+	userID, err := uuid.Parse(userIDString)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	status := model.PostStatus(r.URL.Query().Get("status"))
+
+	if status != "" && status != model.DRAFT && status != model.SCHEDULED {
+		http.Error(w, "status must be DRAFT or SCHEDULED", http.StatusBadRequest)
+
+		return
+	}
+
+	page, size := helpers.ExtractPagination(r)
+
+	drafts, err := handler.service.FindAll(userID, status, page, size)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	helpers.ToJSON(&drafts, w)
+}
+
+func (handler *DraftHandler) Update(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	postID, err := uuid.Parse(vars["id"])
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	dto := &payload.DraftUpdate{}
+	err = helpers.FromJSON(&dto, r.Body)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	claims := r.Context().Value(middleware.TokenKey{}).(jwt.MapClaims)
+
+	userIDString := helpers.ExtractClaim("sub", claims)
+	userID, err := uuid.Parse(userIDString)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	draft, err := handler.service.Update(postID, dto, userID)
+
+	if writeDraftError(w, err) {
+		return
+	}
+
+	helpers.ToJSON(draft, w)
+}
+
+func (handler *DraftHandler) Publish(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	postID, err := uuid.Parse(vars["id"])
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	claims := r.Context().Value(middleware.TokenKey{}).(jwt.MapClaims)
+
+	userIDString := helpers.ExtractClaim("sub", claims)
+	userID, err := uuid.Parse(userIDString)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	err = handler.service.Publish(postID, userID)
+
+	writeDraftError(w, err)
+}
+
+func (handler *DraftHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	postID, err := uuid.Parse(vars["id"])
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	claims := r.Context().Value(middleware.TokenKey{}).(jwt.MapClaims)
+
+	userIDString := helpers.ExtractClaim("sub", claims)
+	userID, err := uuid.Parse(userIDString)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	err = handler.service.Delete(postID, userID)
+
+	writeDraftError(w, err)
+}
+
+// writeDraftError writes the response for a failed draft operation and reports
+// whether there was an error to write.
+func writeDraftError(w http.ResponseWriter, err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if writeAccessError(w, err) {
+		return true
+	}
+
+	switch err {
+	case service.ErrInvalidSchedule:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case service.ErrAlreadyPublished:
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+
+	return true
+}
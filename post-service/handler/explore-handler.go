@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/KristijanPill/Nishtagram/post-service/helpers"
+	"github.com/KristijanPill/Nishtagram/post-service/middleware"
+	"github.com/KristijanPill/Nishtagram/post-service/service"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/google/uuid"
+)
+
+type ExploreHandler struct {
+	service *service.ExploreService
+}
+
+func NewExploreHandler(service *service.ExploreService) *ExploreHandler {
+	return &ExploreHandler{service: service}
+}
+
+func (handler *ExploreHandler) Explore(w http.ResponseWriter, r *http.Request) {
+	claims := r.Context().Value(middleware.TokenKey{}).(jwt.MapClaims)
+
+	userIDString := helpers.ExtractClaim("sub", claims)
+	userID, err := uuid.Parse(userIDString)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	tokenString := helpers.ExtractTokenFromHeader(r.Header["Authorization"][0])
+
+	page, err := handler.service.Explore(userID, r.URL.Query().Get("cursor"), helpers.ExtractLimit(r), tokenString)
+
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidCursor) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+
+			return
+		}
+
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	helpers.ToJSON(page, w)
+}
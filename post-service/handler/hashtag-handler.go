@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/KristijanPill/Nishtagram/post-service/helpers"
+	"github.com/KristijanPill/Nishtagram/post-service/service"
+)
+
+const defaultTrendingLimit = 10
+
+type HashtagHandler struct {
+	service *service.HashtagService
+}
+
+func NewHashtagHandler(service *service.HashtagService) *HashtagHandler {
+	return &HashtagHandler{service: service}
+}
+
+func (handler *HashtagHandler) GetTrending(w http.ResponseWriter, r *http.Request) {
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+
+	if err != nil || limit <= 0 {
+		limit = defaultTrendingLimit
+	}
+
+	trending, err := handler.service.GetTrending(limit)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	helpers.ToJSON(&trending, w)
+}
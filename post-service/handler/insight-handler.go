@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/KristijanPill/Nishtagram/post-service/helpers"
+	"github.com/KristijanPill/Nishtagram/post-service/middleware"
+	"github.com/KristijanPill/Nishtagram/post-service/payload"
+	"github.com/KristijanPill/Nishtagram/post-service/service"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+const defaultInsightDays = 30
+
+const maxInsightDays = 90
+
+type InsightHandler struct {
+	service *service.InsightService
+}
+
+func NewInsightHandler(service *service.InsightService) *InsightHandler {
+	return &InsightHandler{service: service}
+}
+
+func (handler *InsightHandler) RecordEvents(w http.ResponseWriter, r *http.Request) {
+	dto := &payload.PostEvents{}
+	err := helpers.FromJSON(&dto, r.Body)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	claims := r.Context().Value(middleware.TokenKey{}).(jwt.MapClaims)
+
+	userIDString := helpers.ExtractClaim("sub", claims)
+	userID, err := uuid.Parse(userIDString)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	tokenString := helpers.ExtractTokenFromHeader(r.Header["Authorization"][0])
+
+	err = handler.service.Record(userID, dto, tokenString)
+
+	if err == service.ErrInvalidPostEvent || err == service.ErrTooManyPostEvents {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (handler *InsightHandler) GetInsights(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	postIDString := vars["id"]
+	postID, err := uuid.Parse(postIDString)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	claims := r.Context().Value(middleware.TokenKey{}).(jwt.MapClaims)
+
+	userIDString := helpers.ExtractClaim("sub", claims)
+	userID, err := uuid.Parse(userIDString)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	days := defaultInsightDays
+
+	if value := r.URL.Query().Get("days"); value != "" {
+		days, err = strconv.Atoi(value)
+
+		if err != nil || days < 1 || days > maxInsightDays {
+			http.Error(w, "days must be a number between 1 and 90", http.StatusBadRequest)
+
+			return
+		}
+	}
+
+	insights, err := handler.service.GetInsights(postID, userID, days)
+
+	if writeAccessError(w, err) {
+		return
+	}
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	helpers.ToJSON(insights, w)
+}
@@ -2,10 +2,15 @@ package handler
 
 import (
 	"net/http"
+	"strconv"
 
 	"github.com/KristijanPill/Nishtagram/post-service/helpers"
+	"github.com/KristijanPill/Nishtagram/post-service/middleware"
+	"github.com/KristijanPill/Nishtagram/post-service/payload"
 	"github.com/KristijanPill/Nishtagram/post-service/service"
+	"github.com/google/uuid"
 	"github.com/gorilla/mux"
+	"gorm.io/gorm"
 )
 
 type LocationHandler struct {
@@ -30,3 +35,119 @@ func (handler *LocationHandler) GetByQuery(w http.ResponseWriter, r *http.Reques
 
 	helpers.ToJSON(&locations, w)
 }
+
+func (handler *LocationHandler) Create(w http.ResponseWriter, r *http.Request) {
+	dto := &payload.LocationCreate{}
+	err := helpers.FromJSON(&dto, r.Body)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	location, created, err := handler.service.Create(dto)
+
+	if err == service.ErrInvalidLocation || err == service.ErrInvalidCoordinates {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	if created {
+		w.WriteHeader(http.StatusCreated)
+	}
+
+	helpers.ToJSON(location, w)
+}
+
+func (handler *LocationHandler) GetNearby(w http.ResponseWriter, r *http.Request) {
+	values := r.URL.Query()
+
+	latitude, err := strconv.ParseFloat(values.Get("lat"), 64)
+
+	if err != nil {
+		http.Error(w, "lat must be a number", http.StatusBadRequest)
+
+		return
+	}
+
+	longitude, err := strconv.ParseFloat(values.Get("lng"), 64)
+
+	if err != nil {
+		http.Error(w, "lng must be a number", http.StatusBadRequest)
+
+		return
+	}
+
+	var radius float64
+
+	if values.Get("radius") != "" {
+		if radius, err = strconv.ParseFloat(values.Get("radius"), 64); err != nil {
+			http.Error(w, "radius must be a number", http.StatusBadRequest)
+
+			return
+		}
+	}
+
+	locations, err := handler.service.FindNearby(latitude, longitude, radius, helpers.ExtractLimit(r))
+
+	if err == service.ErrInvalidCoordinates || err == service.ErrInvalidRadius {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	helpers.ToJSON(&locations, w)
+}
+
+func (handler *LocationHandler) GetPage(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	locationID, err := uuid.Parse(vars["id"])
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	var loggedInUserID uuid.UUID
+	if r.Context().Value(middleware.LoggedInUser{}) != nil {
+		loggedInUserID = r.Context().Value(middleware.LoggedInUser{}).(uuid.UUID)
+	}
+
+	var tokenString string
+	if loggedInUserID != uuid.Nil {
+		tokenString = helpers.ExtractTokenFromHeader(r.Header["Authorization"][0])
+	}
+
+	page, size := helpers.ExtractPagination(r)
+
+	locationPage, err := handler.service.GetPage(locationID, loggedInUserID, page, size, tokenString)
+
+	if err == gorm.ErrRecordNotFound {
+		http.Error(w, err.Error(), http.StatusNotFound)
+
+		return
+	}
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	helpers.ToJSON(locationPage, w)
+}
@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/KristijanPill/Nishtagram/post-service/helpers"
+	"github.com/KristijanPill/Nishtagram/post-service/middleware"
+	"github.com/KristijanPill/Nishtagram/post-service/model"
+	"github.com/KristijanPill/Nishtagram/post-service/payload"
+	"github.com/KristijanPill/Nishtagram/post-service/service"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+type ModerationHandler struct {
+	service *service.ModerationService
+}
+
+func NewModerationHandler(service *service.ModerationService) *ModerationHandler {
+	return &ModerationHandler{service: service}
+}
+
+func (handler *ModerationHandler) FindQueue(w http.ResponseWriter, r *http.Request) {
+	status := model.ReportStatus(r.URL.Query().Get("status"))
+
+	if status == "" {
+		status = model.PENDING
+	}
+
+	page, size := helpers.ExtractPagination(r)
+
+	queue, err := handler.service.FindQueue(status, page, size)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	helpers.ToJSON(&queue, w)
+}
+
+func (handler *ModerationHandler) FindReports(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	targetID, err := uuid.Parse(vars["id"])
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	reports, err := handler.service.FindReports(vars["type"], targetID)
+
+	if err == service.ErrInvalidReportTarget {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	helpers.ToJSON(&reports, w)
+}
+
+func (handler *ModerationHandler) TakeAction(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	targetID, err := uuid.Parse(vars["id"])
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	dto := &payload.ModerationActionCreate{}
+	err = helpers.FromJSON(&dto, r.Body)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	claims := r.Context().Value(middleware.TokenKey{}).(jwt.MapClaims)
+
+	adminIDString := helpers.ExtractClaim("sub", claims)
+	adminID, err := uuid.Parse(adminIDString)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	tokenString := helpers.ExtractTokenFromHeader(r.Header["Authorization"][0])
+
+	action, err := handler.service.TakeAction(vars["type"], targetID, adminID, dto, tokenString)
+
+	if err == gorm.ErrRecordNotFound {
+		http.Error(w, err.Error(), http.StatusNotFound)
+
+		return
+	}
+
+	if err == service.ErrInvalidModerationAction || err == service.ErrNoPendingReports || err == service.ErrInvalidReportTarget {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	helpers.ToJSON(action, w)
+}
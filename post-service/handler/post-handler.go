@@ -2,10 +2,13 @@ package handler
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/KristijanPill/Nishtagram/post-service/helpers"
 	"github.com/KristijanPill/Nishtagram/post-service/middleware"
@@ -15,6 +18,7 @@ import (
 	"github.com/dgrijalva/jwt-go"
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
+	"gorm.io/gorm"
 )
 
 type PostHandler struct {
@@ -34,6 +38,74 @@ func (handler *PostHandler) Create(w http.ResponseWriter, r *http.Request) {
 	}
 
 	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	claims := r.Context().Value(middleware.TokenKey{}).(jwt.MapClaims)
+
+	userIDString := helpers.ExtractClaim("sub", claims)
+	userID, err := uuid.Parse(userIDString)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	err = r.ParseMultipartForm(1024 * 128)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		fmt.Println(err.Error())
+		return
+	}
+
+	formData := r.MultipartForm
+
+	locationID, _ := uuid.Parse(r.FormValue("location_id"))
+
+	var taggedUsers []string
+
+	for _, taggedUser := range formData.Value["tagged_users"] {
+		taggedUserID, err := uuid.Parse(taggedUser)
+
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+
+			return
+		}
+
+		taggedUsers = append(taggedUsers, taggedUserID.String())
+	}
+
+	post := &model.Post{
+		UserID:      userID,
+		Description: r.FormValue("description"),
+		Tags:        formData.Value["tags"],
+		LocationID:  locationID,
+		TaggedUsers: taggedUsers,
+	}
+
+	if r.FormValue("scheduled_at") != "" {
+		scheduledAt, err := time.Parse(time.RFC3339, r.FormValue("scheduled_at"))
+
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+
+			return
+		}
+
+		post.Status = model.SCHEDULED
+		post.ScheduledAt = &scheduledAt
+	} else if r.FormValue("draft") == "true" {
+		post.Status = model.DRAFT
+	}
+
+	// The schedule is checked before the files are uploaded, so a rejected post
+	// leaves no media behind counting against the user's storage quota.
+	if err := service.CheckSchedule(post.Status, post.ScheduledAt); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
 	requestURL := fmt.Sprintf("http://%s:%s/upload/post", os.Getenv("MEDIA_SERVICE_DOMAIN"), os.Getenv("MEDIA_SERVICE_PORT"))
 	proxyReq, err := http.NewRequest(http.MethodPost, requestURL, bytes.NewReader(body))
 
@@ -58,47 +130,35 @@ func (handler *PostHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	postPaths := &payload.PostUploadResponse{}
+	defer response.Body.Close()
 
-	err = helpers.FromJSON(&postPaths, response.Body)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if response.StatusCode != http.StatusOK {
+		message, _ := ioutil.ReadAll(response.Body)
+		http.Error(w, strings.TrimSpace(string(message)), response.StatusCode)
 
 		return
 	}
 
-	claims := r.Context().Value(middleware.TokenKey{}).(jwt.MapClaims)
-
-	userIDString := helpers.ExtractClaim("sub", claims)
-	userID, err := uuid.Parse(userIDString)
+	postPaths := &payload.PostUploadResponse{}
 
+	err = helpers.FromJSON(&postPaths, response.Body)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
 
 		return
 	}
 
-	err = r.ParseMultipartForm(1024 * 128)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
-		fmt.Println(err.Error())
-		return
-	}
+	post.Content = postPaths.PostPaths
+	post.Media = newPostMedia(postPaths.Media, formData.Value["alt_text"])
 
-	formData := r.MultipartForm
+	_, err = handler.service.Create(post)
 
-	locationID, _ := uuid.Parse(r.FormValue("location_id"))
+	if err == service.ErrInvalidSchedule {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 
-	post := &model.Post{
-		UserID:      userID,
-		Description: r.FormValue("description"),
-		Tags:        formData.Value["tags"],
-		Content:     postPaths.PostPaths,
-		LocationID:  locationID,
+		return
 	}
 
-	_, err = handler.service.Create(post)
-
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 
@@ -122,9 +182,18 @@ func (handler *PostHandler) FindByOtherUser(w http.ResponseWriter, r *http.Reque
 		loggedInUserID = r.Context().Value(middleware.LoggedInUser{}).(uuid.UUID)
 	}
 
-	posts, err := handler.service.FindByOtherUser(userID, loggedInUserID)
+	var tokenString string
+	if loggedInUserID != uuid.Nil {
+		tokenString = helpers.ExtractTokenFromHeader(r.Header["Authorization"][0])
+	}
+
+	posts, err := handler.service.FindByOtherUser(userID, loggedInUserID, tokenString)
 
 	if err != nil {
+		if writeAccessError(w, err) {
+			return
+		}
+
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 
 		return
@@ -145,7 +214,7 @@ func (handler *PostHandler) FindByUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	posts, err := handler.service.FindByOtherUser(userID, userID)
+	posts, err := handler.service.FindByOtherUser(userID, userID, helpers.ExtractTokenFromHeader(r.Header["Authorization"][0]))
 
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -168,7 +237,7 @@ func (handler *PostHandler) GetLikedPosts(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	posts, err := handler.service.GetReviewsByUserIDAndStatus(userID, 1)
+	posts, err := handler.service.GetReviewsByUserIDAndStatus(userID, 1, helpers.ExtractTokenFromHeader(r.Header["Authorization"][0]))
 
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -192,7 +261,7 @@ func (handler *PostHandler) GetDislikedPosts(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	posts, err := handler.service.GetReviewsByUserIDAndStatus(userID, 0)
+	posts, err := handler.service.GetReviewsByUserIDAndStatus(userID, 0, helpers.ExtractTokenFromHeader(r.Header["Authorization"][0]))
 
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -245,7 +314,12 @@ func (handler *PostHandler) SearchPostsByLocation(w http.ResponseWriter, r *http
 		loggedInUserID = r.Context().Value(middleware.LoggedInUser{}).(uuid.UUID)
 	}
 
-	posts, err := handler.service.SearchPostsByLocation(query, loggedInUserID)
+	var tokenString string
+	if loggedInUserID != uuid.Nil {
+		tokenString = helpers.ExtractTokenFromHeader(r.Header["Authorization"][0])
+	}
+
+	posts, err := handler.service.SearchPostsByLocation(query, loggedInUserID, tokenString)
 
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -264,7 +338,12 @@ func (handler *PostHandler) SearchPostsByTags(w http.ResponseWriter, r *http.Req
 		loggedInUserID = r.Context().Value(middleware.LoggedInUser{}).(uuid.UUID)
 	}
 
-	posts, err := handler.service.SearchPostsByTags(query, loggedInUserID)
+	var tokenString string
+	if loggedInUserID != uuid.Nil {
+		tokenString = helpers.ExtractTokenFromHeader(r.Header["Authorization"][0])
+	}
+
+	posts, err := handler.service.SearchPostsByTags(query, loggedInUserID, tokenString)
 
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -274,3 +353,430 @@ func (handler *PostHandler) SearchPostsByTags(w http.ResponseWriter, r *http.Req
 
 	helpers.ToJSON(&posts, w)
 }
+
+func (handler *PostHandler) FindByHashtag(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	name := vars["name"]
+
+	var loggedInUserID uuid.UUID
+	if r.Context().Value(middleware.LoggedInUser{}) != nil {
+		loggedInUserID = r.Context().Value(middleware.LoggedInUser{}).(uuid.UUID)
+	}
+
+	var tokenString string
+	if loggedInUserID != uuid.Nil {
+		tokenString = helpers.ExtractTokenFromHeader(r.Header["Authorization"][0])
+	}
+
+	page, size := helpers.ExtractPagination(r)
+
+	hashtagPage, err := handler.service.FindByHashtag(name, loggedInUserID, tokenString, page, size)
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+
+			return
+		}
+
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	helpers.ToJSON(&hashtagPage, w)
+}
+
+func (handler *PostHandler) SearchPosts(w http.ResponseWriter, r *http.Request) {
+	values := r.URL.Query()
+
+	query := &payload.PostSearchQuery{
+		Query:     values.Get("query"),
+		MediaType: model.MediaType(values.Get("media_type")),
+		Cursor:    values.Get("cursor"),
+		Limit:     helpers.ExtractLimit(r),
+	}
+
+	if query.MediaType != "" && query.MediaType != model.IMAGE && query.MediaType != model.VIDEO {
+		http.Error(w, "unknown media type", http.StatusBadRequest)
+
+		return
+	}
+
+	var err error
+
+	if query.From, err = parseSearchTime(values.Get("from")); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	if query.To, err = parseSearchTime(values.Get("to")); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	if values.Get("location_id") != "" {
+		if query.LocationID, err = uuid.Parse(values.Get("location_id")); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+
+			return
+		}
+	}
+
+	var loggedInUserID uuid.UUID
+	if r.Context().Value(middleware.LoggedInUser{}) != nil {
+		loggedInUserID = r.Context().Value(middleware.LoggedInUser{}).(uuid.UUID)
+	}
+
+	var tokenString string
+	if loggedInUserID != uuid.Nil {
+		tokenString = helpers.ExtractTokenFromHeader(r.Header["Authorization"][0])
+	}
+
+	result, err := handler.service.SearchPosts(query, loggedInUserID, tokenString)
+
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidCursor) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+
+			return
+		}
+
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	helpers.ToJSON(&result, w)
+}
+
+func parseSearchTime(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	parsed, err := time.Parse(time.RFC3339, value)
+
+	if err != nil {
+		parsed, err = time.Parse("2006-01-02", value)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &parsed, nil
+}
+
+// writeAccessError answers requests for content the logged in user may not see.
+// Private accounts get a 403 with a hint to send a follow request, owner-only
+// actions a plain 403, while blocked or missing content is reported as not found.
+func writeAccessError(w http.ResponseWriter, err error) bool {
+	switch {
+	case errors.Is(err, service.ErrPrivateAccount):
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		helpers.ToJSON(&payload.AccessDenied{Error: err.Error(), RequestToFollow: true}, w)
+
+		return true
+	case errors.Is(err, service.ErrNotPostOwner):
+		http.Error(w, err.Error(), http.StatusForbidden)
+
+		return true
+	case errors.Is(err, service.ErrContentUnavailable), errors.Is(err, gorm.ErrRecordNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+
+		return true
+	}
+
+	return false
+}
+
+func (handler *PostHandler) FindByID(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	postIDString := vars["id"]
+	postID, err := uuid.Parse(postIDString)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	var loggedInUserID uuid.UUID
+	if r.Context().Value(middleware.LoggedInUser{}) != nil {
+		loggedInUserID = r.Context().Value(middleware.LoggedInUser{}).(uuid.UUID)
+	}
+
+	var tokenString string
+	if loggedInUserID != uuid.Nil {
+		tokenString = helpers.ExtractTokenFromHeader(r.Header["Authorization"][0])
+	}
+
+	post, err := handler.service.FindByID(postID, loggedInUserID, tokenString)
+
+	if err != nil {
+		if writeAccessError(w, err) {
+			return
+		}
+
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	helpers.ToJSON(&post, w)
+}
+
+func (handler *PostHandler) FindShared(w http.ResponseWriter, r *http.Request) {
+	dto := &payload.SharedPostsRequest{}
+	err := helpers.FromJSON(&dto, r.Body)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	var loggedInUserID uuid.UUID
+	if r.Context().Value(middleware.LoggedInUser{}) != nil {
+		loggedInUserID = r.Context().Value(middleware.LoggedInUser{}).(uuid.UUID)
+	}
+
+	var tokenString string
+	if loggedInUserID != uuid.Nil {
+		tokenString = helpers.ExtractTokenFromHeader(r.Header["Authorization"][0])
+	}
+
+	posts, err := handler.service.FindShared(dto, loggedInUserID, tokenString)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	helpers.ToJSON(&posts, w)
+}
+
+func (handler *PostHandler) FindByShareSlug(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	slug := vars["slug"]
+
+	var loggedInUserID uuid.UUID
+	if r.Context().Value(middleware.LoggedInUser{}) != nil {
+		loggedInUserID = r.Context().Value(middleware.LoggedInUser{}).(uuid.UUID)
+	}
+
+	var tokenString string
+	if loggedInUserID != uuid.Nil {
+		tokenString = helpers.ExtractTokenFromHeader(r.Header["Authorization"][0])
+	}
+
+	post, err := handler.service.FindByShareSlug(slug, loggedInUserID, tokenString)
+
+	if err != nil {
+		if writeAccessError(w, err) {
+			return
+		}
+
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	helpers.ToJSON(&post, w)
+}
+
+func (handler *PostHandler) GetShareLink(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	postIDString := vars["id"]
+	postID, err := uuid.Parse(postIDString)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	var loggedInUserID uuid.UUID
+	if r.Context().Value(middleware.LoggedInUser{}) != nil {
+		loggedInUserID = r.Context().Value(middleware.LoggedInUser{}).(uuid.UUID)
+	}
+
+	var tokenString string
+	if loggedInUserID != uuid.Nil {
+		tokenString = helpers.ExtractTokenFromHeader(r.Header["Authorization"][0])
+	}
+
+	shareLink, err := handler.service.GetShareLink(postID, loggedInUserID, tokenString)
+
+	if err != nil {
+		if writeAccessError(w, err) {
+			return
+		}
+
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	helpers.ToJSON(&shareLink, w)
+}
+
+func (handler *PostHandler) Archive(w http.ResponseWriter, r *http.Request) {
+	handler.setArchived(w, r, handler.service.Archive)
+}
+
+func (handler *PostHandler) Unarchive(w http.ResponseWriter, r *http.Request) {
+	handler.setArchived(w, r, handler.service.Unarchive)
+}
+
+func (handler *PostHandler) setArchived(w http.ResponseWriter, r *http.Request, update func(uuid.UUID, uuid.UUID) error) {
+	vars := mux.Vars(r)
+	postIDString := vars["id"]
+	postID, err := uuid.Parse(postIDString)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	claims := r.Context().Value(middleware.TokenKey{}).(jwt.MapClaims)
+
+	userIDString := helpers.ExtractClaim("sub", claims)
+	userID, err := uuid.Parse(userIDString)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	err = update(postID, userID)
+
+	if err != nil {
+		if writeAccessError(w, err) {
+			return
+		}
+
+		if err == service.ErrNotPublished {
+			http.Error(w, err.Error(), http.StatusConflict)
+
+			return
+		}
+
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+}
+
+func (handler *PostHandler) FindArchived(w http.ResponseWriter, r *http.Request) {
+	claims := r.Context().Value(middleware.TokenKey{}).(jwt.MapClaims)
+
+	userIDString := helpers.ExtractClaim("sub", claims)
+	userID, err := uuid.Parse(userIDString)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	page, size := helpers.ExtractPagination(r)
+	tokenString := helpers.ExtractTokenFromHeader(r.Header["Authorization"][0])
+
+	posts, err := handler.service.FindArchived(userID, page, size, tokenString)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	helpers.ToJSON(&posts, w)
+}
+
+func (handler *PostHandler) UpdateSettings(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	postIDString := vars["id"]
+	postID, err := uuid.Parse(postIDString)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	dto := &payload.PostSettings{}
+	err = helpers.FromJSON(&dto, r.Body)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	claims := r.Context().Value(middleware.TokenKey{}).(jwt.MapClaims)
+
+	userIDString := helpers.ExtractClaim("sub", claims)
+	userID, err := uuid.Parse(userIDString)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	err = handler.service.UpdateSettings(postID, dto, userID)
+
+	if err != nil {
+		if writeAccessError(w, err) {
+			return
+		}
+
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+}
+
+// newPostMedia builds the carousel of a new post from media-service's upload
+// response, keeping the upload order. Alt texts are matched to the media by
+// their position in the form.
+func newPostMedia(uploaded []payload.MediaMetadata, altTexts []string) []model.PostMedia {
+	var media []model.PostMedia
+
+	for position, metadata := range uploaded {
+		postMedia := model.PostMedia{
+			Path:     metadata.Path,
+			Blurhash: metadata.Blurhash,
+			Type:     metadata.Type,
+			Width:    metadata.Width,
+			Height:   metadata.Height,
+			Duration: metadata.Duration,
+			Position: position,
+		}
+
+		for _, rendition := range metadata.Renditions {
+			switch rendition.Name {
+			case "thumbnail":
+				postMedia.ThumbnailPath = rendition.Path
+			case "feed":
+				postMedia.FeedPath = rendition.Path
+			}
+		}
+
+		if position < len(altTexts) {
+			postMedia.AltText = altTexts[position]
+		}
+
+		media = append(media, postMedia)
+	}
+
+	return media
+}
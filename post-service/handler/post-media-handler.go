@@ -0,0 +1,168 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/KristijanPill/Nishtagram/post-service/helpers"
+	"github.com/KristijanPill/Nishtagram/post-service/middleware"
+	"github.com/KristijanPill/Nishtagram/post-service/payload"
+	"github.com/KristijanPill/Nishtagram/post-service/service"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+type PostMediaHandler struct {
+	service *service.PostMediaService
+}
+
+func NewPostMediaHandler(service *service.PostMediaService) *PostMediaHandler {
+	return &PostMediaHandler{service: service}
+}
+
+func (handler *PostMediaHandler) Reorder(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	postID, err := uuid.Parse(vars["id"])
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	dto := &payload.MediaOrder{}
+	err = helpers.FromJSON(&dto, r.Body)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	claims := r.Context().Value(middleware.TokenKey{}).(jwt.MapClaims)
+
+	userIDString := helpers.ExtractClaim("sub", claims)
+	userID, err := uuid.Parse(userIDString)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	media, err := handler.service.Reorder(postID, dto, userID)
+
+	if writeMediaError(w, err) {
+		return
+	}
+
+	helpers.ToJSON(&media, w)
+}
+
+func (handler *PostMediaHandler) Update(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	postID, err := uuid.Parse(vars["id"])
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	mediaID, err := uuid.Parse(vars["mediaId"])
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	dto := &payload.MediaUpdate{}
+	err = helpers.FromJSON(&dto, r.Body)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	claims := r.Context().Value(middleware.TokenKey{}).(jwt.MapClaims)
+
+	userIDString := helpers.ExtractClaim("sub", claims)
+	userID, err := uuid.Parse(userIDString)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	media, err := handler.service.Update(postID, mediaID, dto, userID)
+
+	if writeMediaError(w, err) {
+		return
+	}
+
+	helpers.ToJSON(media, w)
+}
+
+func (handler *PostMediaHandler) Remove(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	postID, err := uuid.Parse(vars["id"])
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	mediaID, err := uuid.Parse(vars["mediaId"])
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	claims := r.Context().Value(middleware.TokenKey{}).(jwt.MapClaims)
+
+	userIDString := helpers.ExtractClaim("sub", claims)
+	userID, err := uuid.Parse(userIDString)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	media, err := handler.service.Remove(postID, mediaID, userID)
+
+	if writeMediaError(w, err) {
+		return
+	}
+
+	helpers.ToJSON(&media, w)
+}
+
+// writeMediaError writes the response for a failed media operation and reports
+// whether there was an error to write.
+func writeMediaError(w http.ResponseWriter, err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if writeAccessError(w, err) {
+		return true
+	}
+
+	switch err {
+	case service.ErrInvalidMediaOrder:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case service.ErrLastMedia:
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+
+	return true
+}
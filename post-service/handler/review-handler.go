@@ -5,10 +5,12 @@ import (
 
 	"github.com/KristijanPill/Nishtagram/post-service/helpers"
 	"github.com/KristijanPill/Nishtagram/post-service/middleware"
+	"github.com/KristijanPill/Nishtagram/post-service/model"
 	"github.com/KristijanPill/Nishtagram/post-service/payload"
 	"github.com/KristijanPill/Nishtagram/post-service/service"
 	"github.com/dgrijalva/jwt-go"
 	"github.com/google/uuid"
+	"github.com/gorilla/mux"
 )
 
 type ReviewHandler struct {
@@ -42,11 +44,61 @@ func (handler *ReviewHandler) ReviewPost(w http.ResponseWriter, r *http.Request)
 
 	dto.UserID = userID
 
-	_, err = handler.service.ReviewPost(dto)
+	_, err = handler.service.ReviewPost(dto, helpers.ExtractTokenFromHeader(r.Header["Authorization"][0]))
+
+	if err != nil {
+		if writeAccessError(w, err) {
+			return
+		}
+
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+}
+
+func (handler *ReviewHandler) GetLikes(w http.ResponseWriter, r *http.Request) {
+	handler.findReviewers(w, r, model.LIKE)
+}
+
+func (handler *ReviewHandler) GetDislikes(w http.ResponseWriter, r *http.Request) {
+	handler.findReviewers(w, r, model.DISLIKE)
+}
+
+func (handler *ReviewHandler) findReviewers(w http.ResponseWriter, r *http.Request, status model.ReviewStatus) {
+	vars := mux.Vars(r)
+	postIDString := vars["id"]
+	postID, err := uuid.Parse(postIDString)
 
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 
 		return
 	}
+
+	var loggedInUserID uuid.UUID
+	if r.Context().Value(middleware.LoggedInUser{}) != nil {
+		loggedInUserID = r.Context().Value(middleware.LoggedInUser{}).(uuid.UUID)
+	}
+
+	var tokenString string
+	if loggedInUserID != uuid.Nil {
+		tokenString = helpers.ExtractTokenFromHeader(r.Header["Authorization"][0])
+	}
+
+	page, size := helpers.ExtractPagination(r)
+
+	reviewers, err := handler.service.FindReviewers(postID, status, loggedInUserID, tokenString, page, size)
+
+	if err != nil {
+		if writeAccessError(w, err) {
+			return
+		}
+
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	helpers.ToJSON(&reviewers, w)
 }
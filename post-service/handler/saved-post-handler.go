@@ -9,6 +9,8 @@ import (
 	"github.com/KristijanPill/Nishtagram/post-service/service"
 	"github.com/dgrijalva/jwt-go"
 	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
 )
 
 type SavedPostHandler struct {
@@ -42,7 +44,13 @@ func (handler *SavedPostHandler) SavePost(w http.ResponseWriter, r *http.Request
 
 	dto.UserID = userID
 
-	_, err = handler.service.SavePost(dto)
+	tokenString := helpers.ExtractTokenFromHeader(r.Header["Authorization"][0])
+
+	_, err = handler.service.SavePost(dto, tokenString)
+
+	if writeAccessError(w, err) {
+		return
+	}
 
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
@@ -51,6 +59,42 @@ func (handler *SavedPostHandler) SavePost(w http.ResponseWriter, r *http.Request
 	}
 }
 
+func (handler *SavedPostHandler) RemoveSavedPost(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	postID, err := uuid.Parse(vars["id"])
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	claims := r.Context().Value(middleware.TokenKey{}).(jwt.MapClaims)
+
+	userIDString := helpers.ExtractClaim("sub", claims)
+	userID, err := uuid.Parse(userIDString)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	err = handler.service.RemoveSavedPost(postID, userID)
+
+	if err == gorm.ErrRecordNotFound {
+		http.Error(w, err.Error(), http.StatusNotFound)
+
+		return
+	}
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+}
+
 func (handler *SavedPostHandler) GetAllCollectionNames(w http.ResponseWriter, r *http.Request) {
 	claims := r.Context().Value(middleware.TokenKey{}).(jwt.MapClaims)
 
@@ -86,7 +130,10 @@ func (handler *SavedPostHandler) GetAllByLoggedInUser(w http.ResponseWriter, r *
 		return
 	}
 
-	savedPosts, err := handler.service.GetAllByLoggedInUser(userID)
+	page, size := helpers.ExtractPagination(r)
+	tokenString := helpers.ExtractTokenFromHeader(r.Header["Authorization"][0])
+
+	savedPosts, err := handler.service.GetAllByLoggedInUser(userID, page, size, tokenString)
 
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
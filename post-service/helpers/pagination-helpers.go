@@ -0,0 +1,67 @@
+package helpers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+const (
+	defaultPageSize = 12
+	maxPageSize     = 50
+)
+
+func ExtractPagination(r *http.Request) (int, int) {
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+
+	if err != nil || page < 0 {
+		page = 0
+	}
+
+	size, err := strconv.Atoi(r.URL.Query().Get("size"))
+
+	if err != nil || size <= 0 {
+		size = defaultPageSize
+	}
+
+	if size > maxPageSize {
+		size = maxPageSize
+	}
+
+	return page, size
+}
+
+func ExtractLimit(r *http.Request) int {
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+
+	if err != nil || limit <= 0 {
+		return defaultPageSize
+	}
+
+	if limit > maxPageSize {
+		return maxPageSize
+	}
+
+	return limit
+}
+
+func EncodeCursor(i interface{}) (string, error) {
+	cursorJSON, err := json.Marshal(i)
+
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(cursorJSON), nil
+}
+
+func DecodeCursor(cursor string, i interface{}) error {
+	cursorJSON, err := base64.RawURLEncoding.DecodeString(cursor)
+
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(cursorJSON, i)
+}
@@ -0,0 +1,89 @@
+package helpers_test
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/KristijanPill/Nishtagram/post-service/helpers"
+	"github.com/KristijanPill/Nishtagram/post-service/repository"
+	"github.com/google/uuid"
+)
+
+func TestSearchCursorRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		hit  repository.PostSearchHit
+	}{
+		{
+			name: "ranked hit",
+			hit: repository.PostSearchHit{
+				ID:        uuid.MustParse("8f5e2c1a-3b4d-4e6f-9a0b-1c2d3e4f5a6b"),
+				CreatedAt: time.Date(2021, 6, 14, 9, 30, 15, 123456789, time.UTC),
+				Rank:      0.6079271,
+			},
+		},
+		{
+			name: "unranked hit",
+			hit: repository.PostSearchHit{
+				ID:        uuid.MustParse("00000000-0000-0000-0000-000000000001"),
+				CreatedAt: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "boosted author",
+			hit: repository.PostSearchHit{
+				ID:        uuid.MustParse("ffffffff-ffff-ffff-ffff-ffffffffffff"),
+				CreatedAt: time.Date(2021, 12, 31, 23, 59, 59, 999999999, time.FixedZone("CET", 3600)),
+				Rank:      1.0000001,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cursor, err := helpers.EncodeCursor(&test.hit)
+
+			if err != nil {
+				t.Fatalf("EncodeCursor() error = %v", err)
+			}
+
+			if strings.ContainsAny(cursor, "+/=") {
+				t.Errorf("EncodeCursor() = %q, want it safe to put in a URL", cursor)
+			}
+
+			var decoded repository.PostSearchHit
+
+			if err := helpers.DecodeCursor(cursor, &decoded); err != nil {
+				t.Fatalf("DecodeCursor() error = %v", err)
+			}
+
+			if decoded.ID != test.hit.ID || !decoded.CreatedAt.Equal(test.hit.CreatedAt) || decoded.Rank != test.hit.Rank {
+				t.Errorf("DecodeCursor() = %+v, want %+v", decoded, test.hit)
+			}
+		})
+	}
+}
+
+func TestDecodeCursorRejectsInvalidCursors(t *testing.T) {
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{name: "not base64", cursor: "not a cursor!"},
+		{name: "padded base64", cursor: base64.URLEncoding.EncodeToString([]byte(`{"rank":1}`))},
+		{name: "not json", cursor: base64.RawURLEncoding.EncodeToString([]byte("rank=1"))},
+		{name: "wrong types", cursor: base64.RawURLEncoding.EncodeToString([]byte(`{"id":"abc","rank":"high"}`))},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var decoded repository.PostSearchHit
+
+			if err := helpers.DecodeCursor(test.cursor, &decoded); err == nil {
+				t.Errorf("DecodeCursor(%q) = %+v, want an error", test.cursor, decoded)
+			}
+		})
+	}
+}
@@ -25,6 +25,8 @@ import (
 
 var publicKey *rsa.PublicKey
 
+const uuidPattern = "[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}"
+
 func init() {
 	fetchPublicKey()
 }
@@ -45,31 +47,70 @@ func initDatabase() *gorm.DB {
 	}
 
 	db.AutoMigrate(&model.Post{})
+	db.AutoMigrate(&model.PostMedia{})
 	db.AutoMigrate(&model.Comment{})
 	db.AutoMigrate(&model.Review{})
 	db.AutoMigrate(&model.SavedPost{})
+	db.AutoMigrate(&model.Collection{})
+	db.AutoMigrate(&model.CollectionPost{})
 	db.AutoMigrate(&model.Location{})
 	db.AutoMigrate(&model.Report{})
+	db.AutoMigrate(&model.Hashtag{})
+	db.AutoMigrate(&model.PostHashtag{})
+	db.AutoMigrate(&model.ModerationAction{})
+	db.AutoMigrate(&model.FilteredKeyword{})
+	db.AutoMigrate(&model.PostEvent{})
+	db.AutoMigrate(&model.Campaign{})
+	db.AutoMigrate(&model.CampaignEvent{})
+
+	if err := repository.NewCollectionRepository(db).MigrateCollectionNames(); err != nil {
+		panic(err.Error())
+	}
+
+	if err := repository.NewPostMediaRepository(db).MigrateContent(); err != nil {
+		panic(err.Error())
+	}
+
+	if err := repository.NewModerationRepository(db).MigrateReports(); err != nil {
+		panic(err.Error())
+	}
 
 	return db
 }
 
 func handleFunc(postHandler *handler.PostHandler, commentHandler *handler.CommentHandler, reviewHandler *handler.ReviewHandler,
-	savedPostHandler *handler.SavedPostHandler, locationHandler *handler.LocationHandler, securityMiddleware *middleware.SecurityMiddleware, sm *mux.Router) {
+	savedPostHandler *handler.SavedPostHandler, collectionHandler *handler.CollectionHandler, draftHandler *handler.DraftHandler, locationHandler *handler.LocationHandler, hashtagHandler *handler.HashtagHandler, moderationHandler *handler.ModerationHandler, insightHandler *handler.InsightHandler, exploreHandler *handler.ExploreHandler, postMediaHandler *handler.PostMediaHandler, campaignHandler *handler.CampaignHandler, securityMiddleware *middleware.SecurityMiddleware, sm *mux.Router) {
 	postRouterRestricted := sm.Methods(http.MethodPost).Subrouter()
 	postRouterRestricted.HandleFunc("/", postHandler.Create)
 	postRouterRestricted.HandleFunc("/save", savedPostHandler.SavePost)
 	postRouterRestricted.HandleFunc("/comment", commentHandler.Create)
 	postRouterRestricted.HandleFunc("/review", reviewHandler.ReviewPost)
 	postRouterRestricted.HandleFunc("/report", postHandler.CreateReport)
+	postRouterRestricted.HandleFunc("/comment/held/review", commentHandler.ReviewHeld)
+	postRouterRestricted.HandleFunc("/locations", locationHandler.Create)
+	postRouterRestricted.HandleFunc("/events", insightHandler.RecordEvents)
+	postRouterRestricted.HandleFunc("/campaigns/{id:"+uuidPattern+"}/click", campaignHandler.Click)
+	postRouterRestricted.HandleFunc("/drafts/{id:"+uuidPattern+"}/publish", draftHandler.Publish)
+	postRouterRestricted.HandleFunc("/collections", collectionHandler.Create)
+	postRouterRestricted.HandleFunc("/collections/{id:"+uuidPattern+"}/posts", collectionHandler.AddPost)
 	postRouterRestricted.Use(securityMiddleware.Authenticate)
 
 	getRouterPublic := sm.Methods(http.MethodGet).Subrouter()
 	getRouterPublic.HandleFunc("/user/{id}", postHandler.FindByOtherUser)
+	getRouterPublic.HandleFunc("/p/{slug}", postHandler.FindByShareSlug)
+	getRouterPublic.HandleFunc("/{id:"+uuidPattern+"}", postHandler.FindByID)
+	getRouterPublic.HandleFunc("/{id:"+uuidPattern+"}/share", postHandler.GetShareLink)
+	getRouterPublic.HandleFunc("/{id:"+uuidPattern+"}/likes", reviewHandler.GetLikes)
+	getRouterPublic.HandleFunc("/{id:"+uuidPattern+"}/dislikes", reviewHandler.GetDislikes)
 	getRouterPublic.HandleFunc("/location/{query}", locationHandler.GetByQuery)
-	getRouterPublic.HandleFunc("/comment/{id}", commentHandler.FindAllByPostID)
+	getRouterPublic.HandleFunc("/locations/nearby", locationHandler.GetNearby)
+	getRouterPublic.HandleFunc("/locations/{id:"+uuidPattern+"}", locationHandler.GetPage)
+	getRouterPublic.HandleFunc("/comment/{id:"+uuidPattern+"}", commentHandler.FindAllByPostID)
 	getRouterPublic.HandleFunc("/search/location", postHandler.SearchPostsByLocation)
 	getRouterPublic.HandleFunc("/search/tags", postHandler.SearchPostsByTags)
+	getRouterPublic.HandleFunc("/search/posts", postHandler.SearchPosts)
+	getRouterPublic.HandleFunc("/hashtag/trending", hashtagHandler.GetTrending)
+	getRouterPublic.HandleFunc("/hashtag/{name}", postHandler.FindByHashtag)
 	getRouterPublic.Use(securityMiddleware.UserContext)
 
 	getRouterRestricted := sm.Methods(http.MethodGet).Subrouter()
@@ -78,7 +119,68 @@ func handleFunc(postHandler *handler.PostHandler, commentHandler *handler.Commen
 	getRouterRestricted.HandleFunc("/disliked", postHandler.GetDislikedPosts)
 	getRouterRestricted.HandleFunc("/saved/names", savedPostHandler.GetAllCollectionNames)
 	getRouterRestricted.HandleFunc("/saved", savedPostHandler.GetAllByLoggedInUser)
+	getRouterRestricted.HandleFunc("/explore", exploreHandler.Explore)
+	getRouterRestricted.HandleFunc("/archive", postHandler.FindArchived)
+	getRouterRestricted.HandleFunc("/{id:"+uuidPattern+"}/insights", insightHandler.GetInsights)
+	getRouterRestricted.HandleFunc("/drafts", draftHandler.FindAllByLoggedInUser)
+	getRouterRestricted.HandleFunc("/comment/held", commentHandler.FindHeld)
+	getRouterRestricted.HandleFunc("/comment/keywords", commentHandler.GetKeywords)
+	getRouterRestricted.HandleFunc("/collections", collectionHandler.FindAllByLoggedInUser)
+	getRouterRestricted.HandleFunc("/collections/{id:"+uuidPattern+"}/posts", collectionHandler.FindPosts)
 	getRouterRestricted.Use(securityMiddleware.Authenticate)
+
+	putRouterRestricted := sm.Methods(http.MethodPut).Subrouter()
+	putRouterRestricted.HandleFunc("/{id:"+uuidPattern+"}/settings", postHandler.UpdateSettings)
+	putRouterRestricted.HandleFunc("/{id:"+uuidPattern+"}/archive", postHandler.Archive)
+	putRouterRestricted.HandleFunc("/comment/keywords", commentHandler.SetKeywords)
+	putRouterRestricted.HandleFunc("/{id:"+uuidPattern+"}/unarchive", postHandler.Unarchive)
+	putRouterRestricted.HandleFunc("/{id:"+uuidPattern+"}/media/order", postMediaHandler.Reorder)
+	putRouterRestricted.HandleFunc("/{id:"+uuidPattern+"}/media/{mediaId:"+uuidPattern+"}", postMediaHandler.Update)
+	putRouterRestricted.HandleFunc("/drafts/{id:"+uuidPattern+"}", draftHandler.Update)
+	putRouterRestricted.HandleFunc("/collections/order", collectionHandler.Reorder)
+	putRouterRestricted.HandleFunc("/collections/{id:"+uuidPattern+"}", collectionHandler.Update)
+	putRouterRestricted.Use(securityMiddleware.Authenticate)
+
+	deleteRouterRestricted := sm.Methods(http.MethodDelete).Subrouter()
+	deleteRouterRestricted.HandleFunc("/save/{id:"+uuidPattern+"}", savedPostHandler.RemoveSavedPost)
+	deleteRouterRestricted.HandleFunc("/{id:"+uuidPattern+"}/media/{mediaId:"+uuidPattern+"}", postMediaHandler.Remove)
+	deleteRouterRestricted.HandleFunc("/drafts/{id:"+uuidPattern+"}", draftHandler.Delete)
+	deleteRouterRestricted.HandleFunc("/collections/{id:"+uuidPattern+"}", collectionHandler.Delete)
+	deleteRouterRestricted.HandleFunc("/collections/{id:"+uuidPattern+"}/posts/{postId:"+uuidPattern+"}", collectionHandler.RemovePost)
+	deleteRouterRestricted.Use(securityMiddleware.Authenticate)
+
+	getRouterAdmin := sm.Methods(http.MethodGet).Subrouter()
+	getRouterAdmin.HandleFunc("/admin/reports", moderationHandler.FindQueue)
+	getRouterAdmin.HandleFunc("/admin/reports/{type:post|story}/{id:"+uuidPattern+"}", moderationHandler.FindReports)
+	getRouterAdmin.Use(securityMiddleware.AuthorizeAdmin)
+
+	postRouterAdmin := sm.Methods(http.MethodPost).Subrouter()
+	postRouterAdmin.HandleFunc("/admin/reports/{type:post|story}/{id:"+uuidPattern+"}/action", moderationHandler.TakeAction)
+	postRouterAdmin.Use(securityMiddleware.AuthorizeAdmin)
+
+	getRouterInternalUser := sm.Methods(http.MethodGet).Subrouter()
+	getRouterInternalUser.HandleFunc("/internal/campaigns/stories", campaignHandler.FindSponsoredStories)
+	getRouterInternalUser.Use(securityMiddleware.UserContext)
+
+	postRouterInternal := sm.Methods(http.MethodPost).Subrouter()
+	postRouterInternal.HandleFunc("/internal/campaigns/stories/promoted", campaignHandler.FindPromotedStories)
+
+	postRouterInternalUser := sm.Methods(http.MethodPost).Subrouter()
+	postRouterInternalUser.HandleFunc("/internal/posts/shared", postHandler.FindShared)
+	postRouterInternalUser.Use(securityMiddleware.UserContext)
+
+	getRouterAgent := sm.Methods(http.MethodGet).Subrouter()
+	getRouterAgent.HandleFunc("/campaigns", campaignHandler.FindAll)
+	getRouterAgent.HandleFunc("/campaigns/{id:"+uuidPattern+"}/stats", campaignHandler.GetStats)
+	getRouterAgent.Use(securityMiddleware.AuthorizeAgent)
+
+	postRouterAgent := sm.Methods(http.MethodPost).Subrouter()
+	postRouterAgent.HandleFunc("/campaigns", campaignHandler.Create)
+	postRouterAgent.Use(securityMiddleware.AuthorizeAgent)
+
+	deleteRouterAgent := sm.Methods(http.MethodDelete).Subrouter()
+	deleteRouterAgent.HandleFunc("/campaigns/{id:"+uuidPattern+"}", campaignHandler.Delete)
+	deleteRouterAgent.Use(securityMiddleware.AuthorizeAgent)
 }
 
 func main() {
@@ -88,24 +190,48 @@ func main() {
 	commentRepository := repository.NewCommentRepository(database)
 	reviewRepository := repository.NewReviewRepository(database)
 	savedPostRepository := repository.NewSavedPostRepository(database)
+	collectionRepository := repository.NewCollectionRepository(database)
 	locationRepository := repository.NewLocationRepository(database)
-
-	postService := service.NewPostService(postRepository, reviewRepository, commentRepository)
-	commentService := service.NewCommentService(commentRepository)
-	reviewService := service.NewReviewService(reviewRepository)
-	savedPostService := service.NewSavedPostService(savedPostRepository, reviewRepository, commentRepository)
-	locationService := service.NewLocationService(locationRepository)
+	hashtagRepository := repository.NewHashtagRepository(database)
+	keywordRepository := repository.NewKeywordRepository(database)
+	moderationRepository := repository.NewModerationRepository(database)
+	insightRepository := repository.NewInsightRepository(database)
+	exploreRepository := repository.NewExploreRepository(database)
+	postMediaRepository := repository.NewPostMediaRepository(database)
+	campaignRepository := repository.NewCampaignRepository(database)
+
+	draftService := service.NewDraftService(postRepository)
+	postService := service.NewPostService(postRepository, reviewRepository, commentRepository, hashtagRepository, savedPostRepository)
+	commentService := service.NewCommentService(commentRepository, postRepository, keywordRepository)
+	reviewService := service.NewReviewService(reviewRepository, postRepository)
+	savedPostService := service.NewSavedPostService(savedPostRepository, collectionRepository, postRepository, reviewRepository, commentRepository)
+	collectionService := service.NewCollectionService(collectionRepository, savedPostRepository, postRepository, reviewRepository, commentRepository)
+	locationService := service.NewLocationService(locationRepository, postRepository, reviewRepository, commentRepository)
+	hashtagService := service.NewHashtagService(hashtagRepository)
+	moderationService := service.NewModerationService(moderationRepository, postRepository)
+	insightService := service.NewInsightService(insightRepository, postRepository, reviewRepository)
+	campaignService := service.NewCampaignService(campaignRepository, postRepository, reviewRepository, commentRepository)
+	exploreService := service.NewExploreService(exploreRepository, postRepository, reviewRepository, commentRepository, campaignService)
+	postMediaService := service.NewPostMediaService(postMediaRepository, postRepository)
 
 	securityMiddleware := middleware.NewSecurityMiddleware(publicKey)
 	postHandler := handler.NewPostHandler(postService)
 	commentHandler := handler.NewCommentHandler(commentService)
 	reviewHandler := handler.NewReviewHandler(reviewService)
 	savedPostHandler := handler.NewSavedPostHandler(savedPostService)
+	draftHandler := handler.NewDraftHandler(draftService)
+	collectionHandler := handler.NewCollectionHandler(collectionService)
 	locationHandler := handler.NewLocationHandler(locationService)
+	hashtagHandler := handler.NewHashtagHandler(hashtagService)
+	moderationHandler := handler.NewModerationHandler(moderationService)
+	insightHandler := handler.NewInsightHandler(insightService)
+	exploreHandler := handler.NewExploreHandler(exploreService)
+	postMediaHandler := handler.NewPostMediaHandler(postMediaService)
+	campaignHandler := handler.NewCampaignHandler(campaignService)
 
 	sm := mux.NewRouter()
 
-	handleFunc(postHandler, commentHandler, reviewHandler, savedPostHandler, locationHandler, securityMiddleware, sm)
+	handleFunc(postHandler, commentHandler, reviewHandler, savedPostHandler, collectionHandler, draftHandler, locationHandler, hashtagHandler, moderationHandler, insightHandler, exploreHandler, postMediaHandler, campaignHandler, securityMiddleware, sm)
 
 	bindAddress := fmt.Sprintf(":%s", os.Getenv("POST_SERVICE_PORT"))
 
@@ -131,6 +257,14 @@ func main() {
 		}
 	}()
 
+	// publish scheduled posts that are due every 30 seconds
+	go func() {
+		for {
+			draftService.PublishDue()
+			time.Sleep(30 * time.Second)
+		}
+	}()
+
 	// start the server
 	go func() {
 
@@ -106,3 +106,57 @@ func (middleware *SecurityMiddleware) UserContext(next http.Handler) http.Handle
 		next.ServeHTTP(w, r)
 	})
 }
+
+const adminRole = "ROLE_ADMIN"
+
+const agentRole = "ROLE_AGENT"
+
+func (middleware *SecurityMiddleware) AuthorizeAdmin(next http.Handler) http.Handler {
+	return middleware.authorizeRole(adminRole, next)
+}
+
+func (middleware *SecurityMiddleware) AuthorizeAgent(next http.Handler) http.Handler {
+	return middleware.authorizeRole(agentRole, next)
+}
+
+func (middleware *SecurityMiddleware) authorizeRole(requiredRole string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header["Authorization"] == nil {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+
+			return
+		}
+
+		tokenString := helpers.ExtractTokenFromHeader(r.Header["Authorization"][0])
+
+		claims := jwt.MapClaims{}
+		token, err := jwt.ParseWithClaims(tokenString, &claims, func(token *jwt.Token) (interface{}, error) {
+			return middleware.PublicKey, nil
+		})
+
+		if err != nil {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+
+			return
+		}
+
+		if !token.Valid {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+
+			return
+		}
+
+		role, _ := claims["role"].(string)
+
+		if role != requiredRole {
+			http.Error(w, "forbidden", http.StatusForbidden)
+
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), TokenKey{}, claims)
+		r = r.WithContext(ctx)
+
+		next.ServeHTTP(w, r)
+	})
+}
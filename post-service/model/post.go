@@ -1,6 +1,7 @@
 package model
 
 import (
+	"crypto/rand"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,11 +15,56 @@ type Post struct {
 	CreatedAt   time.Time
 	Description string
 	Content     pq.StringArray `gorm:"type:varchar(1000)[]"`
+	Media       []PostMedia
 	Tags        pq.StringArray `gorm:"type:varchar(100)[]"`
 	LocationID  uuid.UUID      `gorm:"foreign_key; not_unique; default:null"`
 	Location    Location
+	TaggedUsers pq.StringArray `gorm:"type:uuid[]"`
+	ShareSlug   *string        `gorm:"uniqueIndex"`
+	Status      PostStatus     `gorm:"index; default:PUBLISHED"`
+	ScheduledAt *time.Time     `gorm:"index"`
+	ArchivedAt  *time.Time     `gorm:"index"`
+	DeletedAt   gorm.DeletedAt `gorm:"index"`
+
+	CommentsDisabled bool
+	HideLikeCounts   bool
+}
+
+// PostMedia describes a single item of the post's carousel. Post.Content keeps
+// the paths in the same order for the views that only need those. Images also
+// have smaller renditions made by media-service, and a blurhash to show while
+// they load.
+type PostMedia struct {
+	ID            uuid.UUID `gorm:"primaryKey; type:uuid"`
+	PostID        uuid.UUID `gorm:"type:uuid; index"`
+	Path          string    `gorm:"type:varchar(1000)"`
+	ThumbnailPath string    `gorm:"type:varchar(1000)"`
+	FeedPath      string    `gorm:"type:varchar(1000)"`
+	Blurhash      string
+	Type          MediaType
+	Width         int
+	Height        int
+	Duration      float64
+	AltText       string
+	TaggedUsers   pq.StringArray `gorm:"type:uuid[]"`
+	Position      int
 }
 
+type PostStatus string
+
+const (
+	PUBLISHED PostStatus = "PUBLISHED"
+	DRAFT     PostStatus = "DRAFT"
+	SCHEDULED PostStatus = "SCHEDULED"
+)
+
+type MediaType string
+
+const (
+	IMAGE MediaType = "image"
+	VIDEO MediaType = "video"
+)
+
 type ReviewStatus int
 
 const (
@@ -28,44 +74,304 @@ const (
 )
 
 type Review struct {
-	PostID uuid.UUID `gorm:"primaryKey; type:uuid"`
-	Post   Post
-	UserID uuid.UUID `gorm:"primaryKey; type:uuid"`
-	Status ReviewStatus
+	PostID    uuid.UUID `gorm:"primaryKey; type:uuid"`
+	Post      Post
+	UserID    uuid.UUID `gorm:"primaryKey; type:uuid"`
+	Status    ReviewStatus
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type ReportReason string
+
+const (
+	SPAM                  ReportReason = "SPAM"
+	NUDITY                ReportReason = "NUDITY"
+	HATE_SPEECH           ReportReason = "HATE_SPEECH"
+	VIOLENCE              ReportReason = "VIOLENCE"
+	HARASSMENT            ReportReason = "HARASSMENT"
+	FALSE_INFORMATION     ReportReason = "FALSE_INFORMATION"
+	INTELLECTUAL_PROPERTY ReportReason = "INTELLECTUAL_PROPERTY"
+	SELF_HARM             ReportReason = "SELF_HARM"
+	OTHER                 ReportReason = "OTHER"
+)
+
+func (reason ReportReason) IsValid() bool {
+	switch reason {
+	case SPAM, NUDITY, HATE_SPEECH, VIOLENCE, HARASSMENT, FALSE_INFORMATION, INTELLECTUAL_PROPERTY, SELF_HARM, OTHER:
+		return true
+	}
+
+	return false
 }
 
+type ReportStatus string
+
+const (
+	PENDING   ReportStatus = "PENDING"
+	DISMISSED ReportStatus = "DISMISSED"
+	ACTIONED  ReportStatus = "ACTIONED"
+)
+
 type Report struct {
 	ID          uuid.UUID `gorm:"primaryKey; unique; type:uuid"`
 	UserID      uuid.UUID `gorm:"type:uuid"`
 	PostID      uuid.UUID `gorm:"foreign_key; not_unique; type:uuid;"`
+	Reason      ReportReason
 	Description string
+	Status      ReportStatus `gorm:"index; default:PENDING"`
+	CreatedAt   time.Time
+	ResolvedAt  *time.Time
+}
+
+type ModerationActionType string
+
+const (
+	DISMISS         ModerationActionType = "DISMISS"
+	REMOVE_CONTENT  ModerationActionType = "REMOVE_CONTENT"
+	WARN_USER       ModerationActionType = "WARN_USER"
+	SUSPEND_ACCOUNT ModerationActionType = "SUSPEND_ACCOUNT"
+)
+
+func (action ModerationActionType) IsValid() bool {
+	switch action {
+	case DISMISS, REMOVE_CONTENT, WARN_USER, SUSPEND_ACCOUNT:
+		return true
+	}
+
+	return false
 }
 
+// ModerationAction is a decision on the reports of a post or, since the queue
+// of story-service is kept here too, of a story.
+type ModerationAction struct {
+	ID        uuid.UUID  `gorm:"primaryKey; unique; type:uuid"`
+	AdminID   uuid.UUID  `gorm:"type:uuid"`
+	PostID    *uuid.UUID `gorm:"type:uuid; index"`
+	StoryID   *uuid.UUID `gorm:"type:uuid; index"`
+	OwnerID   uuid.UUID  `gorm:"type:uuid"`
+	Action    ModerationActionType
+	Note      string
+	CreatedAt time.Time
+}
+
+type CommentStatus string
+
+const (
+	VISIBLE CommentStatus = "VISIBLE"
+	HELD    CommentStatus = "HELD"
+)
+
 type Comment struct {
 	ID                 uuid.UUID `gorm:"primaryKey; unique; type:uuid"`
 	PostID             uuid.UUID `gorm:"type:uuid"`
 	Post               Post
 	UserID             uuid.UUID `gorm:"type:uuid"`
 	Content            string
-	RepliedToCommentID uuid.UUID `gorm:"type:uuid"`
+	RepliedToCommentID uuid.UUID     `gorm:"type:uuid"`
+	Status             CommentStatus `gorm:"index; default:VISIBLE"`
+	CreatedAt          time.Time
+}
+
+type HeldCommentAction string
+
+const (
+	APPROVE HeldCommentAction = "APPROVE"
+	REMOVE  HeldCommentAction = "REMOVE"
+)
+
+type FilteredKeyword struct {
+	UserID    uuid.UUID `gorm:"primaryKey; type:uuid"`
+	Keyword   string    `gorm:"primaryKey"`
+	CreatedAt time.Time
 }
 
 type SavedPost struct {
-	UserID         uuid.UUID `gorm:"primaryKey; type:uuid"`
-	PostID         uuid.UUID `gorm:"primaryKey; type:uuid"`
-	Post           Post
-	CreatedAt      time.Time
-	CollectionName string
+	UserID    uuid.UUID `gorm:"primaryKey; type:uuid"`
+	PostID    uuid.UUID `gorm:"primaryKey; type:uuid"`
+	Post      Post
+	CreatedAt time.Time
+}
+
+type Collection struct {
+	ID          uuid.UUID  `gorm:"primaryKey; unique; type:uuid"`
+	UserID      uuid.UUID  `gorm:"type:uuid; uniqueIndex:idx_collection_user_name"`
+	Name        string     `gorm:"uniqueIndex:idx_collection_user_name"`
+	CoverPostID *uuid.UUID `gorm:"type:uuid"`
+	Position    int
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+type CollectionPost struct {
+	CollectionID uuid.UUID `gorm:"primaryKey; type:uuid"`
+	Collection   Collection
+	PostID       uuid.UUID `gorm:"primaryKey; type:uuid"`
+	Post         Post
+	CreatedAt    time.Time
+}
+
+type Hashtag struct {
+	ID         uuid.UUID `gorm:"primaryKey; unique; type:uuid" json:"id"`
+	Name       string    `gorm:"uniqueIndex" json:"name"`
+	UsageCount int64     `json:"usage_count"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type PostHashtag struct {
+	PostID    uuid.UUID `gorm:"primaryKey; type:uuid"`
+	Post      Post
+	HashtagID uuid.UUID `gorm:"primaryKey; type:uuid"`
+	Hashtag   Hashtag
+	CreatedAt time.Time
+}
+
+type PostEventType string
+
+const (
+	IMPRESSION    PostEventType = "IMPRESSION"
+	PROFILE_VISIT PostEventType = "PROFILE_VISIT"
+	SHARE         PostEventType = "SHARE"
+)
+
+func (eventType PostEventType) IsValid() bool {
+	switch eventType {
+	case IMPRESSION, PROFILE_VISIT, SHARE:
+		return true
+	}
+
+	return false
+}
+
+// PostEvent is keyed by viewer and day, so repeated events of the same viewer
+// on the same day are only counted once.
+type PostEvent struct {
+	PostID    uuid.UUID     `gorm:"primaryKey; type:uuid"`
+	ViewerID  uuid.UUID     `gorm:"primaryKey; type:uuid"`
+	Type      PostEventType `gorm:"primaryKey"`
+	Day       time.Time     `gorm:"primaryKey; type:date"`
+	Follower  bool
+	CreatedAt time.Time
 }
 
 type Location struct {
-	ID      uuid.UUID `gorm:"primaryKey; unique; type:uuid" json:"id"`
-	Country string    `json:"country"`
-	City    string    `json:"city"`
+	ID        uuid.UUID `gorm:"primaryKey; unique; type:uuid" json:"id"`
+	Name      string    `json:"name,omitempty"`
+	Address   string    `json:"address,omitempty"`
+	Country   string    `json:"country"`
+	City      string    `json:"city"`
+	Latitude  *float64  `gorm:"index:idx_location_coordinates" json:"latitude,omitempty"`
+	Longitude *float64  `gorm:"index:idx_location_coordinates" json:"longitude,omitempty"`
+	CreatedAt time.Time `json:"-"`
+}
+
+type CampaignContentType string
+
+const (
+	CAMPAIGN_POST  CampaignContentType = "POST"
+	CAMPAIGN_STORY CampaignContentType = "STORY"
+)
+
+func (contentType CampaignContentType) IsValid() bool {
+	return contentType == CAMPAIGN_POST || contentType == CAMPAIGN_STORY
+}
+
+type CampaignSchedule string
+
+const (
+	ONE_TIME  CampaignSchedule = "ONE_TIME"
+	RECURRING CampaignSchedule = "RECURRING"
+)
+
+// Campaign promotes one of the agent's posts or stories. A one-time campaign is
+// shown once to each viewer within a day of its start, a recurring one up to
+// TimesPerDay times a day to each viewer until it ends. Empty targeting
+// fields and zero ages match everyone.
+type Campaign struct {
+	ID          uuid.UUID `gorm:"primaryKey; type:uuid"`
+	AgentID     uuid.UUID `gorm:"type:uuid; index"`
+	Name        string
+	ContentType CampaignContentType
+	ContentID   uuid.UUID      `gorm:"type:uuid"`
+	Content     pq.StringArray `gorm:"type:varchar(1000)[]"`
+	Schedule    CampaignSchedule
+	StartsAt    time.Time `gorm:"index"`
+	EndsAt      time.Time `gorm:"index"`
+	TimesPerDay int
+	MinAge      int
+	MaxAge      int
+	Genders     pq.Int64Array  `gorm:"type:integer[]"`
+	Countries   pq.StringArray `gorm:"type:varchar(100)[]"`
+	Cities      pq.StringArray `gorm:"type:varchar(100)[]"`
+	CreatedAt   time.Time
+	DeletedAt   gorm.DeletedAt `gorm:"index"`
+}
+
+type CampaignEventType string
+
+const (
+	CAMPAIGN_IMPRESSION CampaignEventType = "IMPRESSION"
+	CAMPAIGN_CLICK      CampaignEventType = "CLICK"
+)
+
+type CampaignPlacement string
+
+const (
+	FEED       CampaignPlacement = "FEED"
+	STORY_TRAY CampaignPlacement = "STORY_TRAY"
+)
+
+func (placement CampaignPlacement) IsValid() bool {
+	return placement == FEED || placement == STORY_TRAY
+}
+
+type CampaignEvent struct {
+	ID         uuid.UUID         `gorm:"primaryKey; type:uuid"`
+	CampaignID uuid.UUID         `gorm:"type:uuid; index:idx_campaign_event_viewer"`
+	ViewerID   uuid.UUID         `gorm:"type:uuid; index:idx_campaign_event_viewer"`
+	Type       CampaignEventType `gorm:"index:idx_campaign_event_viewer"`
+	Placement  CampaignPlacement
+	CreatedAt  time.Time `gorm:"index"`
 }
 
 func (p *Post) BeforeCreate(tx *gorm.DB) (err error) {
 	p.ID = uuid.New()
+
+	if p.ShareSlug == nil {
+		slug, err := NewShareSlug()
+
+		if err != nil {
+			return err
+		}
+
+		p.ShareSlug = &slug
+	}
+
+	return nil
+}
+
+const shareSlugAlphabet = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+const shareSlugLength = 10
+
+func NewShareSlug() (string, error) {
+	randomBytes := make([]byte, shareSlugLength)
+
+	if _, err := rand.Read(randomBytes); err != nil {
+		return "", err
+	}
+
+	slug := make([]byte, shareSlugLength)
+
+	for i, b := range randomBytes {
+		slug[i] = shareSlugAlphabet[int(b)%len(shareSlugAlphabet)]
+	}
+
+	return string(slug), nil
+}
+
+func (m *PostMedia) BeforeCreate(tx *gorm.DB) (err error) {
+	m.ID = uuid.New()
 	return nil
 }
 
@@ -79,7 +385,32 @@ func (l *Location) BeforeCreate(tx *gorm.DB) (err error) {
 	return nil
 }
 
+func (c *Collection) BeforeCreate(tx *gorm.DB) (err error) {
+	c.ID = uuid.New()
+	return nil
+}
+
+func (h *Hashtag) BeforeCreate(tx *gorm.DB) (err error) {
+	h.ID = uuid.New()
+	return nil
+}
+
 func (r *Report) BeforeCreate(tx *gorm.DB) (err error) {
 	r.ID = uuid.New()
 	return nil
 }
+
+func (m *ModerationAction) BeforeCreate(tx *gorm.DB) (err error) {
+	m.ID = uuid.New()
+	return nil
+}
+
+func (c *Campaign) BeforeCreate(tx *gorm.DB) (err error) {
+	c.ID = uuid.New()
+	return nil
+}
+
+func (e *CampaignEvent) BeforeCreate(tx *gorm.DB) (err error) {
+	e.ID = uuid.New()
+	return nil
+}
@@ -1,12 +1,32 @@
 package payload
 
 import (
+	"time"
+
 	"github.com/KristijanPill/Nishtagram/post-service/model"
 	"github.com/google/uuid"
 )
 
 type PostUploadResponse struct {
-	PostPaths []string `json:"mediaPaths"`
+	PostPaths []string        `json:"mediaPaths"`
+	Media     []MediaMetadata `json:"media"`
+}
+
+type MediaMetadata struct {
+	Path       string           `json:"path"`
+	Type       model.MediaType  `json:"type"`
+	Width      int              `json:"width"`
+	Height     int              `json:"height"`
+	Duration   float64          `json:"duration"`
+	Blurhash   string           `json:"blurhash"`
+	Renditions []MediaRendition `json:"renditions"`
+}
+
+type MediaRendition struct {
+	Name   string `json:"name"`
+	Path   string `json:"path"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
 }
 
 type CommentCreate struct {
@@ -23,15 +43,33 @@ type ReviewCreate struct {
 }
 
 type ReportCreate struct {
-	UserID      uuid.UUID `json:"user_id"`
-	PostID      uuid.UUID `json:"post_id"`
-	Description string    `json:"description"`
+	UserID      uuid.UUID          `json:"user_id"`
+	PostID      uuid.UUID          `json:"post_id"`
+	Reason      model.ReportReason `json:"reason"`
+	Description string             `json:"description"`
 }
 
 type SavedPostCreate struct {
-	UserID         uuid.UUID `json:"user_id"`
-	PostID         uuid.UUID `json:"post_id"`
-	CollectionName string    `json:"collection_name"`
+	UserID        uuid.UUID   `json:"user_id"`
+	PostID        uuid.UUID   `json:"post_id"`
+	CollectionIDs []uuid.UUID `json:"collection_ids"`
+}
+
+type CollectionCreate struct {
+	Name string `json:"name"`
+}
+
+type CollectionUpdate struct {
+	Name        string     `json:"name"`
+	CoverPostID *uuid.UUID `json:"cover_post_id"`
+}
+
+type CollectionOrder struct {
+	IDs []uuid.UUID `json:"ids"`
+}
+
+type CollectionPostCreate struct {
+	PostID uuid.UUID `json:"post_id"`
 }
 
 type PostView struct {
@@ -40,17 +78,38 @@ type PostView struct {
 	Username         string         `json:"username"`
 	ProfilePicture   string         `json:"profile_picture,omitempty"`
 	Content          []string       `json:"content"`
+	Media            []MediaView    `json:"media"`
 	NumberOfLikes    int64          `json:"number_of_likes"`
 	NumberOfDislikes int64          `json:"number_of_dislikes"`
 	NumberOfComments int64          `json:"number_of_comments"`
 	Status           int            `json:"review_status"`
 	Location         model.Location `json:"location,omitempty"`
 	Description      string         `json:"description,omitempty"`
+	ArchivedAt       *time.Time     `json:"archived_at,omitempty"`
+	CommentsDisabled bool           `json:"comments_disabled"`
+	LikeCountsHidden bool           `json:"like_counts_hidden,omitempty"`
+	Sponsored        bool           `json:"sponsored,omitempty"`
+	CampaignID       *uuid.UUID     `json:"campaign_id,omitempty"`
 }
 
 type SavedPostView struct {
-	PostView       PostView `json:"post"`
-	CollectionName string   `json:"collection_name"`
+	PostView PostView  `json:"post"`
+	SavedAt  time.Time `json:"saved_at"`
+}
+
+type CollectionView struct {
+	ID            uuid.UUID `json:"id"`
+	Name          string    `json:"name"`
+	CoverImage    string    `json:"cover_image,omitempty"`
+	NumberOfPosts int64     `json:"number_of_posts"`
+	Position      int       `json:"position"`
+}
+
+type CollectionPage struct {
+	Collection CollectionView `json:"collection"`
+	Page       int            `json:"page"`
+	Size       int            `json:"size"`
+	Posts      []PostView     `json:"posts"`
 }
 
 type CommentView struct {
@@ -60,6 +119,31 @@ type CommentView struct {
 	ProfilePicture     string    `json:"profile_picture"`
 	Content            string    `json:"content"`
 	RepliedToCommentID uuid.UUID `json:"replied_to_comment_id"`
+	Held               bool      `json:"held,omitempty"`
+}
+
+type HeldCommentView struct {
+	CommentView
+	PostID    uuid.UUID `json:"post_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type HeldCommentReview struct {
+	CommentIDs []uuid.UUID             `json:"comment_ids"`
+	Action     model.HeldCommentAction `json:"action"`
+}
+
+type HeldCommentReviewResult struct {
+	Reviewed int64 `json:"reviewed"`
+}
+
+type FilteredKeywords struct {
+	Keywords []string `json:"keywords"`
+}
+
+type PostSettings struct {
+	CommentsDisabled bool `json:"comments_disabled"`
+	HideLikeCounts   bool `json:"hide_like_counts"`
 }
 
 type UserID struct {
@@ -79,5 +163,310 @@ type UserDetails struct {
 	Username       string    `json:"username"`
 	Private        bool      `json:"private"`
 	Followed       bool      `json:"followed"`
+	Blocked        bool      `json:"blocked"`
 	ProfilePicture string    `json:"profile_picture"`
 }
+
+type UserView struct {
+	ID       uuid.UUID `json:"id"`
+	Username string    `json:"username"`
+}
+
+type HashtagPage struct {
+	Name       string     `json:"name"`
+	UsageCount int64      `json:"usage_count"`
+	Page       int        `json:"page"`
+	Size       int        `json:"size"`
+	Posts      []PostView `json:"posts"`
+}
+
+type TrendingHashtag struct {
+	Name     string  `json:"name"`
+	LastHour int64   `json:"last_hour"`
+	LastDay  int64   `json:"last_day"`
+	LastWeek int64   `json:"last_week"`
+	Score    float64 `json:"score"`
+}
+
+type PostSearchQuery struct {
+	Query      string
+	From       *time.Time
+	To         *time.Time
+	MediaType  model.MediaType
+	LocationID uuid.UUID
+	Cursor     string
+	Limit      int
+}
+
+type PostSearchResult struct {
+	Posts      []PostView `json:"posts"`
+	NextCursor string     `json:"next_cursor,omitempty"`
+}
+
+type AccessDenied struct {
+	Error           string `json:"error"`
+	RequestToFollow bool   `json:"request_to_follow"`
+}
+
+type PostDetailView struct {
+	PostView
+	CreatedAt   time.Time     `json:"created_at"`
+	Tags        []string      `json:"tags"`
+	TaggedUsers []UserView    `json:"tagged_users"`
+	TopComments []CommentView `json:"top_comments"`
+	Saved       bool          `json:"saved"`
+	ShareLink   string        `json:"share_link,omitempty"`
+}
+
+type ShareLink struct {
+	Slug string `json:"slug"`
+	Link string `json:"link"`
+}
+
+type ReviewerView struct {
+	ID             uuid.UUID `json:"id"`
+	Username       string    `json:"username"`
+	ProfilePicture string    `json:"profile_picture,omitempty"`
+	Followed       bool      `json:"followed"`
+}
+
+type ReviewersPage struct {
+	Total       int64          `json:"total"`
+	TotalHidden bool           `json:"total_hidden,omitempty"`
+	Page        int            `json:"page"`
+	Size        int            `json:"size"`
+	Users       []ReviewerView `json:"users"`
+}
+
+type ContentPreview struct {
+	Description      string   `json:"description,omitempty"`
+	Content          []string `json:"content"`
+	CloseFriendsOnly bool     `json:"close_friends_only,omitempty"`
+	Removed          bool     `json:"removed"`
+}
+
+type ReportGroup struct {
+	TargetID        uuid.UUID                    `json:"target_id"`
+	TargetType      string                       `json:"target_type"`
+	OwnerID         uuid.UUID                    `json:"owner_id"`
+	Reports         int64                        `json:"reports"`
+	Reasons         map[model.ReportReason]int64 `json:"reasons"`
+	FirstReportedAt time.Time                    `json:"first_reported_at"`
+	LastReportedAt  time.Time                    `json:"last_reported_at"`
+	Preview         ContentPreview               `json:"preview"`
+}
+
+type ReportView struct {
+	ID          uuid.UUID          `json:"id"`
+	UserID      uuid.UUID          `json:"user_id"`
+	Reason      model.ReportReason `json:"reason"`
+	Description string             `json:"description"`
+	Status      model.ReportStatus `json:"status"`
+	CreatedAt   time.Time          `json:"created_at"`
+	ResolvedAt  *time.Time         `json:"resolved_at,omitempty"`
+}
+
+type PendingReports struct {
+	OwnerID     uuid.UUID   `json:"owner_id"`
+	ReporterIDs []uuid.UUID `json:"reporter_ids"`
+}
+
+type ReportResolve struct {
+	Action model.ModerationActionType `json:"action"`
+}
+
+type ModerationActionCreate struct {
+	Action model.ModerationActionType `json:"action"`
+	Note   string                     `json:"note"`
+}
+
+type NotificationCreate struct {
+	UserIDs []uuid.UUID `json:"user_ids"`
+	Type    string      `json:"type"`
+	Message string      `json:"message"`
+}
+
+type DraftUpdate struct {
+	Description string      `json:"description"`
+	Tags        []string    `json:"tags"`
+	TaggedUsers []uuid.UUID `json:"tagged_users"`
+	ScheduledAt *time.Time  `json:"scheduled_at"`
+}
+
+type DraftView struct {
+	ID          uuid.UUID        `json:"id"`
+	Content     []string         `json:"content"`
+	Media       []MediaView      `json:"media"`
+	Description string           `json:"description"`
+	Tags        []string         `json:"tags"`
+	TaggedUsers []string         `json:"tagged_users"`
+	Location    model.Location   `json:"location,omitempty"`
+	Status      model.PostStatus `json:"status"`
+	ScheduledAt *time.Time       `json:"scheduled_at,omitempty"`
+	CreatedAt   time.Time        `json:"created_at"`
+}
+
+type LocationCreate struct {
+	Name      string   `json:"name"`
+	Address   string   `json:"address"`
+	City      string   `json:"city"`
+	Country   string   `json:"country"`
+	Latitude  *float64 `json:"latitude"`
+	Longitude *float64 `json:"longitude"`
+}
+
+type NearbyLocation struct {
+	Location model.Location `json:"location"`
+	Distance float64        `json:"distance"`
+}
+
+type LocationPage struct {
+	Location model.Location `json:"location"`
+	Page     int            `json:"page"`
+	Size     int            `json:"size"`
+	Posts    []PostView     `json:"posts"`
+}
+
+type PostEventCreate struct {
+	PostID uuid.UUID           `json:"post_id"`
+	Type   model.PostEventType `json:"type"`
+}
+
+type PostEvents struct {
+	Events []PostEventCreate `json:"events"`
+}
+
+type InsightDay struct {
+	Day         string `json:"day"`
+	Impressions int64  `json:"impressions"`
+	Reach       int64  `json:"reach"`
+	Likes       int64  `json:"likes"`
+	Dislikes    int64  `json:"dislikes"`
+}
+
+type PostInsights struct {
+	PostID           uuid.UUID    `json:"post_id"`
+	Impressions      int64        `json:"impressions"`
+	Reach            int64        `json:"reach"`
+	FollowerReach    int64        `json:"follower_reach"`
+	NonFollowerReach int64        `json:"non_follower_reach"`
+	ProfileVisits    int64        `json:"profile_visits"`
+	Saves            int64        `json:"saves"`
+	Shares           int64        `json:"shares"`
+	Likes            int64        `json:"likes"`
+	Dislikes         int64        `json:"dislikes"`
+	LikeRatio        float64      `json:"like_ratio"`
+	Timeline         []InsightDay `json:"timeline"`
+}
+
+type ExplorePage struct {
+	Posts      []PostView `json:"posts"`
+	NextCursor string     `json:"next_cursor,omitempty"`
+}
+
+type MediaView struct {
+	ID            uuid.UUID       `json:"id"`
+	Path          string          `json:"path"`
+	ThumbnailPath string          `json:"thumbnail_path,omitempty"`
+	FeedPath      string          `json:"feed_path,omitempty"`
+	Blurhash      string          `json:"blurhash,omitempty"`
+	Type          model.MediaType `json:"type"`
+	Width         int             `json:"width,omitempty"`
+	Height        int             `json:"height,omitempty"`
+	Duration      float64         `json:"duration,omitempty"`
+	AltText       string          `json:"alt_text,omitempty"`
+	TaggedUsers   []string        `json:"tagged_users,omitempty"`
+	Position      int             `json:"position"`
+}
+
+type MediaOrder struct {
+	MediaIDs []uuid.UUID `json:"media_ids"`
+}
+
+type MediaUpdate struct {
+	AltText     string      `json:"alt_text"`
+	TaggedUsers []uuid.UUID `json:"tagged_users"`
+}
+
+type CampaignCreate struct {
+	Name        string                    `json:"name"`
+	ContentType model.CampaignContentType `json:"content_type"`
+	ContentID   uuid.UUID                 `json:"content_id"`
+	Schedule    model.CampaignSchedule    `json:"schedule"`
+	StartsAt    time.Time                 `json:"starts_at"`
+	EndsAt      time.Time                 `json:"ends_at"`
+	TimesPerDay int                       `json:"times_per_day"`
+	MinAge      int                       `json:"min_age"`
+	MaxAge      int                       `json:"max_age"`
+	Genders     []int64                   `json:"genders"`
+	Countries   []string                  `json:"countries"`
+	Cities      []string                  `json:"cities"`
+}
+
+type CampaignView struct {
+	ID          uuid.UUID                 `json:"id"`
+	Name        string                    `json:"name"`
+	ContentType model.CampaignContentType `json:"content_type"`
+	ContentID   uuid.UUID                 `json:"content_id"`
+	Content     []string                  `json:"content"`
+	Schedule    model.CampaignSchedule    `json:"schedule"`
+	StartsAt    time.Time                 `json:"starts_at"`
+	EndsAt      time.Time                 `json:"ends_at"`
+	TimesPerDay int                       `json:"times_per_day"`
+	MinAge      int                       `json:"min_age,omitempty"`
+	MaxAge      int                       `json:"max_age,omitempty"`
+	Genders     []int64                   `json:"genders"`
+	Countries   []string                  `json:"countries"`
+	Cities      []string                  `json:"cities"`
+	CreatedAt   time.Time                 `json:"created_at"`
+}
+
+type CampaignClick struct {
+	Placement model.CampaignPlacement `json:"placement"`
+}
+
+type CampaignDay struct {
+	Day         string `json:"day"`
+	Impressions int64  `json:"impressions"`
+	Clicks      int64  `json:"clicks"`
+}
+
+type CampaignStats struct {
+	CampaignID       uuid.UUID     `json:"campaign_id"`
+	Impressions      int64         `json:"impressions"`
+	Reach            int64         `json:"reach"`
+	Clicks           int64         `json:"clicks"`
+	ClickThroughRate float64       `json:"click_through_rate"`
+	FeedImpressions  int64         `json:"feed_impressions"`
+	StoryImpressions int64         `json:"story_impressions"`
+	Timeline         []CampaignDay `json:"timeline"`
+}
+
+type ContentIDs struct {
+	IDs []uuid.UUID `json:"ids"`
+}
+
+type SharedPostsRequest struct {
+	IDs []uuid.UUID `json:"ids"`
+}
+
+type SponsoredStory struct {
+	CampaignID uuid.UUID `json:"campaign_id"`
+	AgentID    uuid.UUID `json:"agent_id"`
+	StoryID    uuid.UUID `json:"story_id"`
+	Content    []string  `json:"content"`
+}
+
+type Audience struct {
+	Age     int    `json:"age"`
+	Gender  int64  `json:"gender"`
+	City    string `json:"city"`
+	Country string `json:"country"`
+}
+
+type StoryDetails struct {
+	ID               uuid.UUID `json:"id"`
+	UserID           uuid.UUID `json:"user_id"`
+	Content          []string  `json:"content"`
+	CloseFriendsOnly bool      `json:"close_friends_only"`
+}
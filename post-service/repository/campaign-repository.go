@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/KristijanPill/Nishtagram/post-service/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type CampaignRepository struct {
+	database *gorm.DB
+}
+
+type CampaignAudience struct {
+	ViewerID uuid.UUID
+	Age      int
+	Gender   int64
+	Country  string
+	City     string
+}
+
+type CampaignTotals struct {
+	Impressions      int64
+	Reach            int64
+	Clicks           int64
+	FeedImpressions  int64
+	StoryImpressions int64
+}
+
+type CampaignDailyEvents struct {
+	Day         time.Time
+	Impressions int64
+	Clicks      int64
+}
+
+func NewCampaignRepository(database *gorm.DB) *CampaignRepository {
+	return &CampaignRepository{database: database}
+}
+
+func (repository *CampaignRepository) Create(campaign *model.Campaign) (*model.Campaign, error) {
+	result := repository.database.Create(campaign)
+
+	return campaign, result.Error
+}
+
+func (repository *CampaignRepository) FindByID(id uuid.UUID) (*model.Campaign, error) {
+	var campaign model.Campaign
+	result := repository.database.First(&campaign, "id = ?", id)
+
+	return &campaign, result.Error
+}
+
+func (repository *CampaignRepository) FindPromotedContentIDs(contentType model.CampaignContentType, contentIDs []uuid.UUID, now time.Time) ([]uuid.UUID, error) {
+	var promoted []uuid.UUID
+
+	if len(contentIDs) == 0 {
+		return promoted, nil
+	}
+
+	result := repository.database.Model(&model.Campaign{}).Distinct("content_id").
+		Where("content_type = ? AND content_id IN ? AND ends_at > ?", contentType, contentIDs, now).
+		Pluck("content_id", &promoted)
+
+	return promoted, result.Error
+}
+
+func (repository *CampaignRepository) FindAllByAgentID(agentID uuid.UUID, page int, size int) ([]model.Campaign, error) {
+	var campaigns []model.Campaign
+	result := repository.database.Where("agent_id = ?", agentID).
+		Order("created_at desc").
+		Offset(page * size).Limit(size).
+		Find(&campaigns)
+
+	return campaigns, result.Error
+}
+
+func (repository *CampaignRepository) Delete(campaign *model.Campaign) error {
+	result := repository.database.Delete(campaign)
+
+	return result.Error
+}
+
+// FindEligible picks random running campaigns of the given content type that
+// target the viewer and have not yet reached the number of times the viewer may
+// see them, which is once ever for one-time campaigns and per day otherwise.
+func (repository *CampaignRepository) FindEligible(contentType model.CampaignContentType, audience *CampaignAudience, now time.Time, limit int) ([]model.Campaign, error) {
+	var campaigns []model.Campaign
+	dayStart := now.UTC().Truncate(24 * time.Hour)
+
+	result := repository.database.
+		Where("content_type = ? AND starts_at <= ? AND ends_at > ? AND agent_id <> ?", contentType, now, now, audience.ViewerID).
+		Where("(min_age = 0 OR min_age <= ?) AND (max_age = 0 OR max_age >= ?)", audience.Age, audience.Age).
+		Where("(coalesce(cardinality(genders), 0) = 0 OR ? = ANY(genders))", audience.Gender).
+		Where("(coalesce(cardinality(countries), 0) = 0 OR ? = ANY(countries))", audience.Country).
+		Where("(coalesce(cardinality(cities), 0) = 0 OR ? = ANY(cities))", audience.City).
+		Where("(SELECT COUNT(*) FROM campaign_events WHERE campaign_events.campaign_id = campaigns.id "+
+			"AND campaign_events.viewer_id = ? AND campaign_events.type = ? "+
+			"AND (campaigns.schedule = ? OR campaign_events.created_at >= ?)) < campaigns.times_per_day",
+			audience.ViewerID, model.CAMPAIGN_IMPRESSION, model.ONE_TIME, dayStart).
+		Order("random()").
+		Limit(limit).
+		Find(&campaigns)
+
+	return campaigns, result.Error
+}
+
+func (repository *CampaignRepository) RecordEvents(events []model.CampaignEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	result := repository.database.Create(&events)
+
+	return result.Error
+}
+
+// RecordClick stores a click of the viewer on the campaign, unless the viewer
+// was never shown the campaign or already clicked it that day, so clicks can't
+// outnumber the viewers the campaign reached.
+func (repository *CampaignRepository) RecordClick(campaignID uuid.UUID, viewerID uuid.UUID, placement model.CampaignPlacement, now time.Time) error {
+	dayStart := now.UTC().Truncate(24 * time.Hour)
+
+	return repository.database.Exec(`INSERT INTO campaign_events (id, campaign_id, viewer_id, type, placement, created_at)
+		SELECT gen_random_uuid(), ?, ?, ?, ?, ?
+		WHERE EXISTS (SELECT 1 FROM campaign_events WHERE campaign_id = ? AND viewer_id = ? AND type = ?)
+		AND NOT EXISTS (SELECT 1 FROM campaign_events WHERE campaign_id = ? AND viewer_id = ? AND type = ? AND created_at >= ?)`,
+		campaignID, viewerID, model.CAMPAIGN_CLICK, placement, now,
+		campaignID, viewerID, model.CAMPAIGN_IMPRESSION,
+		campaignID, viewerID, model.CAMPAIGN_CLICK, dayStart).Error
+}
+
+func (repository *CampaignRepository) FindTotals(campaignID uuid.UUID) (*CampaignTotals, error) {
+	var totals CampaignTotals
+	result := repository.database.Model(&model.CampaignEvent{}).
+		Select("COUNT(*) FILTER (WHERE type = ?) AS impressions, "+
+			"COUNT(DISTINCT viewer_id) FILTER (WHERE type = ?) AS reach, "+
+			"COUNT(*) FILTER (WHERE type = ?) AS clicks, "+
+			"COUNT(*) FILTER (WHERE type = ? AND placement = ?) AS feed_impressions, "+
+			"COUNT(*) FILTER (WHERE type = ? AND placement = ?) AS story_impressions",
+			model.CAMPAIGN_IMPRESSION, model.CAMPAIGN_IMPRESSION, model.CAMPAIGN_CLICK,
+			model.CAMPAIGN_IMPRESSION, model.FEED, model.CAMPAIGN_IMPRESSION, model.STORY_TRAY).
+		Where("campaign_id = ?", campaignID).
+		Scan(&totals)
+
+	return &totals, result.Error
+}
+
+func (repository *CampaignRepository) FindDailyEvents(campaignID uuid.UUID, since time.Time) ([]CampaignDailyEvents, error) {
+	var days []CampaignDailyEvents
+	result := repository.database.Model(&model.CampaignEvent{}).
+		Select("date(created_at AT TIME ZONE 'UTC') AS day, COUNT(*) FILTER (WHERE type = ?) AS impressions, COUNT(*) FILTER (WHERE type = ?) AS clicks",
+			model.CAMPAIGN_IMPRESSION, model.CAMPAIGN_CLICK).
+		Where("campaign_id = ? AND created_at >= ?", campaignID, since).
+		Group("date(created_at AT TIME ZONE 'UTC')").
+		Order("day").
+		Scan(&days)
+
+	return days, result.Error
+}
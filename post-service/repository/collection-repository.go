@@ -0,0 +1,219 @@
+package repository
+
+import (
+	"github.com/KristijanPill/Nishtagram/post-service/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CollectionRepository struct {
+	database *gorm.DB
+}
+
+type CollectionPostCount struct {
+	CollectionID uuid.UUID
+	Posts        int64
+	LastPostID   uuid.UUID
+}
+
+func NewCollectionRepository(database *gorm.DB) *CollectionRepository {
+	return &CollectionRepository{database: database}
+}
+
+func (repository *CollectionRepository) Create(collection *model.Collection) (*model.Collection, error) {
+	result := repository.database.Create(collection)
+
+	return collection, result.Error
+}
+
+func (repository *CollectionRepository) FindByIDAndUserID(id string, userID string) (*model.Collection, error) {
+	var collection model.Collection
+	result := repository.database.First(&collection, "id = ? AND user_id = ?", id, userID)
+
+	return &collection, result.Error
+}
+
+func (repository *CollectionRepository) ExistsByNameAndUserID(name string, userID string) (bool, error) {
+	var collection model.Collection
+	result := repository.database.Where("name = ? AND user_id = ?", name, userID).Find(&collection)
+
+	return result.RowsAffected != 0, result.Error
+}
+
+func (repository *CollectionRepository) FindAllByUserID(userID string) ([]model.Collection, error) {
+	var collections []model.Collection
+	result := repository.database.Where("user_id = ?", userID).Order("position, created_at").Find(&collections)
+
+	return collections, result.Error
+}
+
+func (repository *CollectionRepository) FindNextPosition(userID string) (int, error) {
+	var position int
+	result := repository.database.Model(&model.Collection{}).Select("COALESCE(MAX(position) + 1, 0)").Where("user_id = ?", userID).Scan(&position)
+
+	return position, result.Error
+}
+
+func (repository *CollectionRepository) Update(collection *model.Collection) (*model.Collection, error) {
+	result := repository.database.Save(collection)
+
+	return collection, result.Error
+}
+
+// UpdatePositions orders the collections of the user as given; collections
+// missing from the list keep their relative order after the listed ones.
+func (repository *CollectionRepository) UpdatePositions(userID string, collectionIDs []uuid.UUID) error {
+	return repository.database.Transaction(func(tx *gorm.DB) error {
+		var current []uuid.UUID
+
+		if err := tx.Model(&model.Collection{}).Where("user_id = ?", userID).Order("position, created_at").Pluck("id", &current).Error; err != nil {
+			return err
+		}
+
+		for position, collectionID := range reorder(current, collectionIDs) {
+			if err := tx.Model(&model.Collection{}).Where("id = ?", collectionID).Update("position", position).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// reorder puts the listed ids first, in the order given, followed by the rest
+// of the current ids in their current order. Listed ids that aren't current,
+// such as ids of someone else's collections, and repeated ids are skipped.
+func reorder(current []uuid.UUID, listed []uuid.UUID) []uuid.UUID {
+	isCurrent := make(map[uuid.UUID]bool)
+
+	for _, id := range current {
+		isCurrent[id] = true
+	}
+
+	var ordered []uuid.UUID
+	placed := make(map[uuid.UUID]bool)
+
+	for _, id := range listed {
+		if isCurrent[id] && !placed[id] {
+			placed[id] = true
+			ordered = append(ordered, id)
+		}
+	}
+
+	for _, id := range current {
+		if !placed[id] {
+			ordered = append(ordered, id)
+		}
+	}
+
+	return ordered
+}
+
+func (repository *CollectionRepository) Delete(collection *model.Collection) error {
+	return repository.database.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("collection_id = ?", collection.ID).Delete(&model.CollectionPost{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Delete(collection).Error
+	})
+}
+
+func (repository *CollectionRepository) AddPost(collectionID uuid.UUID, postID uuid.UUID) error {
+	collectionPost := &model.CollectionPost{CollectionID: collectionID, PostID: postID}
+	result := repository.database.Clauses(clause.OnConflict{DoNothing: true}).Create(collectionPost)
+
+	return result.Error
+}
+
+func (repository *CollectionRepository) RemovePost(collection *model.Collection, postID uuid.UUID) error {
+	return repository.database.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("collection_id = ? AND post_id = ?", collection.ID, postID).Delete(&model.CollectionPost{})
+
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		if collection.CoverPostID != nil && *collection.CoverPostID == postID {
+			return tx.Model(collection).Update("cover_post_id", nil).Error
+		}
+
+		return nil
+	})
+}
+
+func (repository *CollectionRepository) ContainsPost(collectionID uuid.UUID, postID uuid.UUID) (bool, error) {
+	var collectionPost model.CollectionPost
+	result := repository.database.Where("collection_id = ? AND post_id = ?", collectionID, postID).Find(&collectionPost)
+
+	return result.RowsAffected != 0, result.Error
+}
+
+func (repository *CollectionRepository) FindPostsByCollectionID(collectionID uuid.UUID, page int, size int) ([]model.Post, error) {
+	var posts []model.Post
+	result := repository.database.Preload("Location").Preload("Media", mediaByPosition).
+		Joins("JOIN collection_posts ON collection_posts.post_id = posts.id").
+		Where("collection_posts.collection_id = ?", collectionID).
+		Order("collection_posts.created_at desc").
+		Offset(page * size).Limit(size).
+		Find(&posts)
+
+	return posts, result.Error
+}
+
+// FindPostCounts returns how many visible posts each collection holds together
+// with the most recently added one, which is the default cover.
+func (repository *CollectionRepository) FindPostCounts(collectionIDs []uuid.UUID) ([]CollectionPostCount, error) {
+	var counts []CollectionPostCount
+	result := repository.database.Raw(`SELECT DISTINCT ON (collection_posts.collection_id)
+			collection_posts.collection_id AS collection_id,
+			COUNT(*) OVER (PARTITION BY collection_posts.collection_id) AS posts,
+			collection_posts.post_id AS last_post_id
+		FROM collection_posts
+		JOIN posts ON posts.id = collection_posts.post_id AND posts.deleted_at IS NULL
+		WHERE collection_posts.collection_id IN ?
+		ORDER BY collection_posts.collection_id, collection_posts.created_at DESC`, collectionIDs).
+		Scan(&counts)
+
+	return counts, result.Error
+}
+
+// MigrateCollectionNames turns the collection names that used to be stored on
+// saved posts into collections and drops the old column.
+func (repository *CollectionRepository) MigrateCollectionNames() error {
+	migrator := repository.database.Migrator()
+
+	if !migrator.HasColumn(&model.SavedPost{}, "collection_name") {
+		return nil
+	}
+
+	return repository.database.Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(`INSERT INTO collections (id, user_id, name, position, created_at, updated_at)
+			SELECT gen_random_uuid(), user_id, collection_name, 0, MIN(created_at), MIN(created_at)
+			FROM saved_posts
+			WHERE collection_name IS NOT NULL AND collection_name <> ''
+			GROUP BY user_id, collection_name
+			ON CONFLICT DO NOTHING`).Error
+
+		if err != nil {
+			return err
+		}
+
+		err = tx.Exec(`INSERT INTO collection_posts (collection_id, post_id, created_at)
+			SELECT collections.id, saved_posts.post_id, saved_posts.created_at
+			FROM saved_posts
+			JOIN collections ON collections.user_id = saved_posts.user_id AND collections.name = saved_posts.collection_name
+			ON CONFLICT DO NOTHING`).Error
+
+		if err != nil {
+			return err
+		}
+
+		return tx.Migrator().DropColumn(&model.SavedPost{}, "collection_name")
+	})
+}
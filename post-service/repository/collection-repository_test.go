@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestReorder(t *testing.T) {
+	travel := uuid.MustParse("3f1c2a9e-0000-4000-8000-000000000001")
+	recipes := uuid.MustParse("3f1c2a9e-0000-4000-8000-000000000002")
+	outfits := uuid.MustParse("3f1c2a9e-0000-4000-8000-000000000003")
+	createdElsewhere := uuid.MustParse("3f1c2a9e-0000-4000-8000-000000000004")
+	deleted := uuid.MustParse("3f1c2a9e-0000-4000-8000-000000000005")
+
+	tests := []struct {
+		name    string
+		current []uuid.UUID
+		listed  []uuid.UUID
+		want    []uuid.UUID
+	}{
+		{
+			name:    "whole screen reordered",
+			current: []uuid.UUID{travel, recipes, outfits},
+			listed:  []uuid.UUID{outfits, travel, recipes},
+			want:    []uuid.UUID{outfits, travel, recipes},
+		},
+		{
+			name:    "collection created on another device goes last",
+			current: []uuid.UUID{travel, createdElsewhere, recipes},
+			listed:  []uuid.UUID{recipes, travel},
+			want:    []uuid.UUID{recipes, travel, createdElsewhere},
+		},
+		{
+			name:    "collection deleted meanwhile is left out",
+			current: []uuid.UUID{travel, recipes},
+			listed:  []uuid.UUID{deleted, recipes, travel},
+			want:    []uuid.UUID{recipes, travel},
+		},
+		{
+			name:    "collection dragged twice counts where it first appears",
+			current: []uuid.UUID{travel, recipes, outfits},
+			listed:  []uuid.UUID{recipes, outfits, recipes},
+			want:    []uuid.UUID{recipes, outfits, travel},
+		},
+		{
+			name:    "empty order changes nothing",
+			current: []uuid.UUID{outfits, travel},
+			listed:  []uuid.UUID{},
+			want:    []uuid.UUID{outfits, travel},
+		},
+		{
+			name:    "nothing saved into collections yet",
+			current: nil,
+			listed:  []uuid.UUID{travel, recipes},
+			want:    nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := reorder(test.current, test.listed); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("reorder() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
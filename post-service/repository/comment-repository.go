@@ -2,6 +2,7 @@ package repository
 
 import (
 	"github.com/KristijanPill/Nishtagram/post-service/model"
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
@@ -26,9 +27,55 @@ func (repository *CommentRepository) FindById(id string) (*model.Comment, error)
 	return &comment, result.Error
 }
 
-func (repository *CommentRepository) FindAllByPostID(commentID string) ([]model.Comment, error) {
+// FindAllByPostID returns the visible comments of the post together with the
+// held comments written by the viewer.
+func (repository *CommentRepository) FindAllByPostID(postID string, viewerID string) ([]model.Comment, error) {
 	var comments []model.Comment
-	result := repository.database.Where("post_id = ?", commentID).Find(&comments)
+	result := repository.database.Where("post_id = ? AND (status = ? OR user_id = ?)", postID, model.VISIBLE, viewerID).Order("created_at").Find(&comments)
+
+	return comments, result.Error
+}
+
+func (repository *CommentRepository) FindHeldByPostOwner(ownerID string, postID string, page int, size int) ([]model.Comment, error) {
+	var comments []model.Comment
+	query := repository.database.Joins("JOIN posts ON posts.id = comments.post_id AND posts.deleted_at IS NULL").
+		Where("posts.user_id = ? AND comments.status = ?", ownerID, model.HELD)
+
+	if postID != "" {
+		query = query.Where("comments.post_id = ?", postID)
+	}
+
+	result := query.Order("comments.created_at desc").Offset(page * size).Limit(size).Find(&comments)
+
+	return comments, result.Error
+}
+
+// ApproveHeld makes the held comments visible; comments on posts of other users
+// are left untouched.
+func (repository *CommentRepository) ApproveHeld(ownerID string, commentIDs []uuid.UUID) (int64, error) {
+	result := repository.database.Model(&model.Comment{}).
+		Where("id IN ? AND status = ? AND post_id IN (?)", commentIDs, model.HELD, repository.postIDsOf(ownerID)).
+		Update("status", model.VISIBLE)
+
+	return result.RowsAffected, result.Error
+}
+
+func (repository *CommentRepository) DeleteHeld(ownerID string, commentIDs []uuid.UUID) (int64, error) {
+	result := repository.database.
+		Where("id IN ? AND status = ? AND post_id IN (?)", commentIDs, model.HELD, repository.postIDsOf(ownerID)).
+		Delete(&model.Comment{})
+
+	return result.RowsAffected, result.Error
+}
+
+func (repository *CommentRepository) postIDsOf(ownerID string) *gorm.DB {
+	return repository.database.Model(&model.Post{}).Select("id").Where("user_id = ?", ownerID)
+}
+
+func (repository *CommentRepository) FindTopByPostID(postID string, limit int) ([]model.Comment, error) {
+	var comments []model.Comment
+	result := repository.database.Where("post_id = ? AND status = ? AND (replied_to_comment_id IS NULL OR replied_to_comment_id = ?)", postID, model.VISIBLE, uuid.Nil).
+		Order("created_at desc").Limit(limit).Find(&comments)
 
 	return comments, result.Error
 }
@@ -40,8 +87,8 @@ func (repository *CommentRepository) Delete(postID string) error {
 }
 
 func (repository *CommentRepository) FindCountByPostID(postID string) int64 {
-	var comments []model.Comment
-	result := repository.database.Where("post_id = ?", postID).Find(&comments)
+	var count int64
+	repository.database.Model(&model.Comment{}).Where("post_id = ? AND status = ?", postID, model.VISIBLE).Count(&count)
 
-	return result.RowsAffected
+	return count
 }
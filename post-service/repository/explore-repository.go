@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/KristijanPill/Nishtagram/post-service/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ExploreRepository struct {
+	database *gorm.DB
+}
+
+type ExploreFilter struct {
+	ViewerID      uuid.UUID
+	At            time.Time
+	Since         time.Time
+	PostsByAuthor int
+	After         *ExploreHit
+	Limit         int
+}
+
+type ExploreHit struct {
+	ID     uuid.UUID `json:"id"`
+	UserID uuid.UUID `json:"-"`
+	Score  float64   `json:"score"`
+}
+
+// exploreQuery scores every published post of the window as its engagement
+// velocity (reviews and comments per hour since it was posted), boosted by how
+// often the viewer liked posts with the same hashtags and weighted by the
+// author's smoothed like ratio. Only each author's best posts are kept.
+const exploreQuery = `WITH affinity AS (
+	SELECT post_hashtags.hashtag_id, COUNT(*) AS weight FROM reviews
+	JOIN post_hashtags ON post_hashtags.post_id = reviews.post_id
+	WHERE reviews.user_id = ? AND reviews.status = ? AND reviews.created_at <= ?
+	GROUP BY post_hashtags.hashtag_id
+), candidates AS (
+	SELECT posts.id, posts.user_id, posts.created_at FROM posts
+	WHERE posts.deleted_at IS NULL AND posts.archived_at IS NULL AND posts.status = 'PUBLISHED'
+	AND posts.user_id <> ? AND posts.created_at > ? AND posts.created_at <= ?
+), authors AS (
+	SELECT posts.user_id, (COUNT(reviews.user_id) FILTER (WHERE reviews.status = ?) + 1)::double precision / (COUNT(reviews.user_id) + 2) AS quality
+	FROM posts LEFT JOIN reviews ON reviews.post_id = posts.id AND reviews.created_at <= ?
+	WHERE posts.deleted_at IS NULL AND posts.user_id IN (SELECT user_id FROM candidates)
+	GROUP BY posts.user_id
+), scored AS (
+	SELECT candidates.id, candidates.user_id,
+	((SELECT COUNT(*) FROM reviews WHERE reviews.post_id = candidates.id AND reviews.created_at <= ?) +
+	(SELECT COUNT(*) FROM comments WHERE comments.post_id = candidates.id AND comments.status = ? AND comments.created_at <= ?))::double precision /
+	(EXTRACT(EPOCH FROM (?::timestamptz - candidates.created_at)) / 3600 + 2) *
+	(1 + ln(1 + coalesce((SELECT SUM(affinity.weight) FROM post_hashtags JOIN affinity ON affinity.hashtag_id = post_hashtags.hashtag_id
+		WHERE post_hashtags.post_id = candidates.id), 0))) *
+	authors.quality AS score
+	FROM candidates JOIN authors ON authors.user_id = candidates.user_id
+), ranked AS (
+	SELECT scored.*, ROW_NUMBER() OVER (PARTITION BY scored.user_id ORDER BY scored.score DESC, scored.id DESC) AS author_rank FROM scored
+)
+SELECT ranked.id, ranked.user_id, ranked.score FROM ranked WHERE ranked.author_rank <= ?`
+
+func NewExploreRepository(database *gorm.DB) *ExploreRepository {
+	return &ExploreRepository{database: database}
+}
+
+// FindRanked scores posts as of filter.At, leaving out every review and comment
+// made after it, so following pages of the same session rank them the same way
+// and the cursor stays valid.
+func (repository *ExploreRepository) FindRanked(filter *ExploreFilter) ([]ExploreHit, error) {
+	query := exploreQuery
+	args := []interface{}{
+		filter.ViewerID, model.LIKE, filter.At,
+		filter.ViewerID, filter.Since, filter.At,
+		model.LIKE, filter.At,
+		filter.At, model.VISIBLE, filter.At, filter.At,
+		filter.PostsByAuthor,
+	}
+
+	if filter.After != nil {
+		query += " AND (ranked.score, ranked.id) < (?, ?)"
+		args = append(args, filter.After.Score, filter.After.ID)
+	}
+
+	query += " ORDER BY ranked.score DESC, ranked.id DESC LIMIT ?"
+	args = append(args, filter.Limit)
+
+	var hits []ExploreHit
+	result := repository.database.Raw(query, args...).Scan(&hits)
+
+	return hits, result.Error
+}
@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/KristijanPill/Nishtagram/post-service/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type HashtagRepository struct {
+	database *gorm.DB
+}
+
+type HashtagUsage struct {
+	HashtagID uuid.UUID
+	Name      string
+	LastHour  int64
+	LastDay   int64
+	LastWeek  int64
+}
+
+func NewHashtagRepository(database *gorm.DB) *HashtagRepository {
+	return &HashtagRepository{database: database}
+}
+
+func (repository *HashtagRepository) FindByName(name string) (*model.Hashtag, error) {
+	var hashtag model.Hashtag
+	result := repository.database.First(&hashtag, "name = ?", name)
+
+	return &hashtag, result.Error
+}
+
+// linkHashtags links the post to its hashtags, creating the ones used for the
+// first time. It runs in the transaction that publishes the post, so a post is
+// never published without its hashtags. Only hashtags the post wasn't linked to
+// yet count the post as another use.
+func linkHashtags(tx *gorm.DB, postID uuid.UUID, names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+
+	var hashtags []model.Hashtag
+
+	for _, name := range names {
+		hashtags = append(hashtags, model.Hashtag{Name: name})
+	}
+
+	err := tx.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "name"}}, DoNothing: true}).Create(&hashtags).Error
+
+	if err != nil {
+		return err
+	}
+
+	var hashtagIDs []uuid.UUID
+	err = tx.Model(&model.Hashtag{}).
+		Where("name IN ?", names).
+		Where("id NOT IN (?)", tx.Model(&model.PostHashtag{}).Select("hashtag_id").Where("post_id = ?", postID)).
+		Pluck("id", &hashtagIDs).Error
+
+	if err != nil || len(hashtagIDs) == 0 {
+		return err
+	}
+
+	var postHashtags []model.PostHashtag
+
+	for _, hashtagID := range hashtagIDs {
+		postHashtags = append(postHashtags, model.PostHashtag{PostID: postID, HashtagID: hashtagID})
+	}
+
+	err = tx.Create(&postHashtags).Error
+
+	if err != nil {
+		return err
+	}
+
+	return tx.Model(&model.Hashtag{}).Where("id IN ?", hashtagIDs).Update("usage_count", gorm.Expr("usage_count + 1")).Error
+}
+
+// countHashtagUses adds the change to the usage count of every hashtag of the
+// post. The count only takes in published posts that are on their owner's
+// profile, so it goes down when a post is archived or deleted.
+func countHashtagUses(tx *gorm.DB, postID uuid.UUID, change int) error {
+	return tx.Model(&model.Hashtag{}).
+		Where("id IN (?)", tx.Model(&model.PostHashtag{}).Select("hashtag_id").Where("post_id = ?", postID)).
+		Update("usage_count", gorm.Expr("usage_count + ?", change)).Error
+}
+
+// unlinkHashtags removes the hashtags of a deleted post.
+func unlinkHashtags(tx *gorm.DB, postID uuid.UUID, counted bool) error {
+	if counted {
+		if err := countHashtagUses(tx, postID, -1); err != nil {
+			return err
+		}
+	}
+
+	return tx.Where("post_id = ?", postID).Delete(&model.PostHashtag{}).Error
+}
+
+func (repository *HashtagRepository) FindPostsByHashtagID(hashtagID uuid.UUID, page int, size int) ([]model.Post, error) {
+	var posts []model.Post
+	result := repository.database.Preload("Location").Preload("Media", mediaByPosition).Scopes(onProfile).
+		Joins("JOIN post_hashtags ON post_hashtags.post_id = posts.id").
+		Where("post_hashtags.hashtag_id = ?", hashtagID).
+		Order("posts.created_at desc").
+		Offset(page * size).Limit(size).
+		Find(&posts)
+
+	return posts, result.Error
+}
+
+func (repository *HashtagRepository) FindRecentUsage() ([]HashtagUsage, error) {
+	var usages []HashtagUsage
+	now := time.Now()
+	result := repository.database.Model(&model.PostHashtag{}).
+		Select("hashtags.id AS hashtag_id, hashtags.name AS name, "+
+			"COUNT(*) FILTER (WHERE post_hashtags.created_at >= ?) AS last_hour, "+
+			"COUNT(*) FILTER (WHERE post_hashtags.created_at >= ?) AS last_day, "+
+			"COUNT(*) AS last_week", now.Add(-time.Hour), now.Add(-24*time.Hour)).
+		Joins("JOIN hashtags ON hashtags.id = post_hashtags.hashtag_id").
+		Joins("JOIN posts ON posts.id = post_hashtags.post_id AND posts.deleted_at IS NULL AND posts.archived_at IS NULL").
+		Where("post_hashtags.created_at >= ?", now.Add(-7*24*time.Hour)).
+		Group("hashtags.id, hashtags.name").
+		Scan(&usages)
+
+	return usages, result.Error
+}
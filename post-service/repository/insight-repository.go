@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/KristijanPill/Nishtagram/post-service/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type InsightRepository struct {
+	database *gorm.DB
+}
+
+type EventTotals struct {
+	Impressions      int64
+	Reach            int64
+	FollowerReach    int64
+	NonFollowerReach int64
+	ProfileVisits    int64
+	Shares           int64
+}
+
+type DailyEvents struct {
+	Day         time.Time
+	Impressions int64
+	Reach       int64
+}
+
+type DailyReviews struct {
+	Day      time.Time
+	Likes    int64
+	Dislikes int64
+}
+
+func NewInsightRepository(database *gorm.DB) *InsightRepository {
+	return &InsightRepository{database: database}
+}
+
+// RecordEvents stores the batch with a single insert, skipping events already
+// recorded for the same viewer on the same day.
+func (repository *InsightRepository) RecordEvents(events []model.PostEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	result := repository.database.Clauses(clause.OnConflict{DoNothing: true}).Create(&events)
+
+	return result.Error
+}
+
+func (repository *InsightRepository) FindTotals(postID string) (*EventTotals, error) {
+	var totals EventTotals
+	result := repository.database.Model(&model.PostEvent{}).
+		Select("COUNT(*) FILTER (WHERE type = ?) AS impressions, "+
+			"COUNT(DISTINCT viewer_id) FILTER (WHERE type = ?) AS reach, "+
+			"COUNT(DISTINCT viewer_id) FILTER (WHERE type = ? AND follower) AS follower_reach, "+
+			"COUNT(DISTINCT viewer_id) FILTER (WHERE type = ? AND NOT follower) AS non_follower_reach, "+
+			"COUNT(*) FILTER (WHERE type = ?) AS profile_visits, "+
+			"COUNT(*) FILTER (WHERE type = ?) AS shares",
+			model.IMPRESSION, model.IMPRESSION, model.IMPRESSION, model.IMPRESSION, model.PROFILE_VISIT, model.SHARE).
+		Where("post_id = ?", postID).
+		Scan(&totals)
+
+	return &totals, result.Error
+}
+
+func (repository *InsightRepository) FindDailyEvents(postID string, since time.Time) ([]DailyEvents, error) {
+	var days []DailyEvents
+	result := repository.database.Model(&model.PostEvent{}).
+		Select("day, COUNT(*) AS impressions, COUNT(DISTINCT viewer_id) AS reach").
+		Where("post_id = ? AND type = ? AND day >= ?", postID, model.IMPRESSION, since).
+		Group("day").
+		Order("day").
+		Scan(&days)
+
+	return days, result.Error
+}
+
+// FindDailyReviews groups the current likes and dislikes by the UTC day they were
+// last changed.
+func (repository *InsightRepository) FindDailyReviews(postID string, since time.Time) ([]DailyReviews, error) {
+	var days []DailyReviews
+	result := repository.database.Model(&model.Review{}).
+		Select("date(updated_at AT TIME ZONE 'UTC') AS day, COUNT(*) FILTER (WHERE status = ?) AS likes, COUNT(*) FILTER (WHERE status = ?) AS dislikes", model.LIKE, model.DISLIKE).
+		Where("post_id = ? AND updated_at >= ?", postID, since).
+		Group("date(updated_at AT TIME ZONE 'UTC')").
+		Order("day").
+		Scan(&days)
+
+	return days, result.Error
+}
+
+func (repository *InsightRepository) FindSaveCount(postID string) (int64, error) {
+	var count int64
+	result := repository.database.Model(&model.SavedPost{}).Where("post_id = ?", postID).Count(&count)
+
+	return count, result.Error
+}
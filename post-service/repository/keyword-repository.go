@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"github.com/KristijanPill/Nishtagram/post-service/model"
+	"gorm.io/gorm"
+)
+
+type KeywordRepository struct {
+	database *gorm.DB
+}
+
+func NewKeywordRepository(database *gorm.DB) *KeywordRepository {
+	return &KeywordRepository{database: database}
+}
+
+func (repository *KeywordRepository) FindByUserID(userID string) ([]string, error) {
+	var keywords []string
+	result := repository.database.Model(&model.FilteredKeyword{}).Where("user_id = ?", userID).Order("keyword").Pluck("keyword", &keywords)
+
+	return keywords, result.Error
+}
+
+// Replace swaps the whole keyword list of the user for the given one.
+func (repository *KeywordRepository) Replace(userID string, keywords []model.FilteredKeyword) error {
+	return repository.database.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&model.FilteredKeyword{}).Error; err != nil {
+			return err
+		}
+
+		if len(keywords) == 0 {
+			return nil
+		}
+
+		return tx.Create(&keywords).Error
+	})
+}
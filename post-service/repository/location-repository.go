@@ -1,6 +1,9 @@
 package repository
 
 import (
+	"math"
+	"strings"
+
 	"github.com/KristijanPill/Nishtagram/post-service/model"
 	"gorm.io/gorm"
 )
@@ -9,13 +12,86 @@ type LocationRepository struct {
 	database *gorm.DB
 }
 
+type LocationDistance struct {
+	model.Location
+	Distance float64
+}
+
+const metersPerDegree = 111320.0
+
+// distance is the haversine great-circle distance in meters between the
+// location and the point given as (latitude, latitude, longitude) arguments.
+const distance = "2 * 6371000 * asin(sqrt(power(sin(radians(latitude - ?) / 2), 2) + " +
+	"cos(radians(?)) * cos(radians(latitude)) * power(sin(radians(longitude - ?) / 2), 2)))"
+
 func NewLocationRepository(database *gorm.DB) *LocationRepository {
 	return &LocationRepository{database: database}
 }
 
+func (repository *LocationRepository) Create(location *model.Location) (*model.Location, error) {
+	result := repository.database.Create(location)
+
+	return location, result.Error
+}
+
+func (repository *LocationRepository) FindByID(id string) (*model.Location, error) {
+	var location model.Location
+	result := repository.database.First(&location, "id = ?", id)
+
+	return &location, result.Error
+}
+
 func (repository *LocationRepository) GetByQuery(query string) ([]model.Location, error) {
 	var locations []model.Location
-	result := repository.database.Limit(5).Where("country ILIKE ?", "%"+query+"%").Or("city ILIKE ?", "%"+query+"%").Find(&locations)
+	result := repository.database.Limit(5).
+		Where("name ILIKE ? OR country ILIKE ? OR city ILIKE ?", "%"+query+"%", "%"+query+"%", "%"+query+"%").
+		Find(&locations)
 
 	return locations, result.Error
 }
+
+// FindNearby returns the locations within radius meters of the point, closest
+// first. A bounding box on the indexed coordinates narrows the candidates
+// before the exact distance is computed.
+func (repository *LocationRepository) FindNearby(latitude float64, longitude float64, radius float64, limit int) ([]LocationDistance, error) {
+	var locations []LocationDistance
+
+	latitudeDelta := radius / metersPerDegree
+	conditions := "latitude BETWEEN ? AND ?"
+	args := []interface{}{latitude, latitude, longitude, latitude - latitudeDelta, latitude + latitudeDelta}
+
+	if cos := math.Cos(latitude * math.Pi / 180); cos > 0.01 {
+		longitudeDelta := radius / (metersPerDegree * cos)
+
+		if longitude-longitudeDelta >= -180 && longitude+longitudeDelta <= 180 {
+			conditions += " AND longitude BETWEEN ? AND ?"
+			args = append(args, longitude-longitudeDelta, longitude+longitudeDelta)
+		}
+	}
+
+	args = append(args, radius, limit)
+
+	result := repository.database.Raw("SELECT * FROM (SELECT locations.*, "+distance+" AS distance FROM locations WHERE "+conditions+") AS nearby "+
+		"WHERE distance <= ? ORDER BY distance LIMIT ?", args...).
+		Scan(&locations)
+
+	return locations, result.Error
+}
+
+// FindDuplicate finds a location with the same name within radius meters, so
+// the same place added twice resolves to one location.
+func (repository *LocationRepository) FindDuplicate(name string, latitude float64, longitude float64, radius float64) (*model.Location, error) {
+	nearby, err := repository.FindNearby(latitude, longitude, radius, 10)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, location := range nearby {
+		if strings.EqualFold(strings.TrimSpace(location.Name), name) {
+			return &location.Location, nil
+		}
+	}
+
+	return nil, gorm.ErrRecordNotFound
+}
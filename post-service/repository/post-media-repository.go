@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"github.com/KristijanPill/Nishtagram/post-service/model"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+const videoContentPattern = `\.(mp4|mov|webm|mkv|avi)$`
+
+type PostMediaRepository struct {
+	database *gorm.DB
+}
+
+func NewPostMediaRepository(database *gorm.DB) *PostMediaRepository {
+	return &PostMediaRepository{database: database}
+}
+
+func (repository *PostMediaRepository) FindByIDAndPostID(id uuid.UUID, postID uuid.UUID) (*model.PostMedia, error) {
+	var media model.PostMedia
+	result := repository.database.First(&media, "id = ? AND post_id = ?", id, postID)
+
+	return &media, result.Error
+}
+
+func (repository *PostMediaRepository) Update(media *model.PostMedia) (*model.PostMedia, error) {
+	result := repository.database.Model(media).Select("alt_text", "tagged_users").Updates(media)
+
+	return media, result.Error
+}
+
+// Reorder stores the given order of the post's media and mirrors it in
+// Post.Content. Media of the post missing from the list are removed.
+func (repository *PostMediaRepository) Reorder(postID uuid.UUID, media []model.PostMedia) error {
+	return repository.database.Transaction(func(tx *gorm.DB) error {
+		var ids []uuid.UUID
+		var paths []string
+
+		for position, item := range media {
+			err := tx.Model(&model.PostMedia{}).Where("id = ?", item.ID).Update("position", position).Error
+
+			if err != nil {
+				return err
+			}
+
+			ids = append(ids, item.ID)
+			paths = append(paths, item.Path)
+		}
+
+		err := tx.Where("post_id = ? AND id NOT IN ?", postID, ids).Delete(&model.PostMedia{}).Error
+
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(&model.Post{}).Where("id = ?", postID).Update("content", pq.StringArray(paths)).Error
+	})
+}
+
+// MigrateContent creates the media of posts made before PostMedia existed from
+// their content paths. Dimensions of those media stay unknown.
+func (repository *PostMediaRepository) MigrateContent() error {
+	return repository.database.Exec(`INSERT INTO post_media (id, post_id, path, type, width, height, duration, alt_text, position)
+		SELECT gen_random_uuid(), posts.id, item.path,
+			CASE WHEN lower(item.path) ~ ? THEN ? ELSE ? END,
+			0, 0, 0, '', item.position - 1
+		FROM posts CROSS JOIN LATERAL unnest(posts.content) WITH ORDINALITY AS item(path, position)
+		WHERE NOT EXISTS (SELECT 1 FROM post_media WHERE post_media.post_id = posts.id)`,
+		videoContentPattern, model.VIDEO, model.IMAGE).Error
+}
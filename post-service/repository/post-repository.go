@@ -2,8 +2,10 @@ package repository
 
 import (
 	"strings"
+	"time"
 
 	"github.com/KristijanPill/Nishtagram/post-service/model"
+	"github.com/google/uuid"
 	"github.com/lib/pq"
 	"gorm.io/gorm"
 )
@@ -12,33 +14,195 @@ type PostRepository struct {
 	database *gorm.DB
 }
 
+type PostSearchFilter struct {
+	Query      string
+	AuthorIDs  []uuid.UUID
+	From       *time.Time
+	To         *time.Time
+	MediaType  model.MediaType
+	LocationID uuid.UUID
+	After      *PostSearchHit
+	Limit      int
+}
+
+type PostSearchHit struct {
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	Rank      float64   `json:"rank"`
+}
+
+const searchDocument = "setweight(to_tsvector('simple', coalesce(posts.description, '')), 'A') || " +
+	"setweight(to_tsvector('simple', coalesce(array_to_string(posts.tags, ' '), '')), 'A') || " +
+	"setweight(to_tsvector('simple', coalesce(locations.name, '') || ' ' || coalesce(locations.address, '') || ' ' || " +
+	"coalesce(locations.country, '') || ' ' || coalesce(locations.city, '')), 'B')"
+
 func NewPostRepository(database *gorm.DB) *PostRepository {
 	return &PostRepository{database: database}
 }
 
+// onProfile keeps only published posts their owners did not take off their
+// profile.
+func onProfile(database *gorm.DB) *gorm.DB {
+	return database.Where("posts.status = ? AND posts.archived_at IS NULL", model.PUBLISHED)
+}
+
+func mediaByPosition(database *gorm.DB) *gorm.DB {
+	return database.Order("position")
+}
+
+// Create stores the post and, if it is published right away, links its
+// hashtags in the same transaction.
 func (repository *PostRepository) Create(post *model.Post) (*model.Post, error) {
-	result := repository.database.Create(post)
+	err := repository.database.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(post).Error; err != nil {
+			return err
+		}
 
-	return post, result.Error
+		if post.Status != model.PUBLISHED {
+			return nil
+		}
+
+		return linkHashtags(tx, post.ID, post.Tags)
+	})
+
+	return post, err
 }
 
 func (repository *PostRepository) FindById(id string) (*model.Post, error) {
 	var post model.Post
-	result := repository.database.Preload("Location").First(&post, "id = ?", id)
+	result := repository.database.Preload("Location").Preload("Media", mediaByPosition).First(&post, "id = ?", id)
 
 	return &post, result.Error
 }
 
+func (repository *PostRepository) FindByShareSlug(slug string) (*model.Post, error) {
+	var post model.Post
+	result := repository.database.Preload("Location").Preload("Media", mediaByPosition).First(&post, "share_slug = ?", slug)
+
+	return &post, result.Error
+}
+
+func (repository *PostRepository) UpdateShareSlug(post *model.Post) (*model.Post, error) {
+	result := repository.database.Model(post).Update("share_slug", post.ShareSlug)
+
+	return post, result.Error
+}
+
 func (repository *PostRepository) FindByUserID(userID string) ([]model.Post, error) {
 	var posts []model.Post
-	result := repository.database.Preload("Location").Where("user_id = ? ", userID).Order("created_at desc").Find(&posts)
+	result := repository.database.Preload("Location").Preload("Media", mediaByPosition).Scopes(onProfile).Where("user_id = ? ", userID).Order("created_at desc").Find(&posts)
+
+	return posts, result.Error
+}
+
+func (repository *PostRepository) FindArchivedByUserID(userID string, page int, size int) ([]model.Post, error) {
+	var posts []model.Post
+	result := repository.database.Preload("Location").Preload("Media", mediaByPosition).
+		Where("user_id = ? AND archived_at IS NOT NULL", userID).
+		Order("archived_at desc").
+		Offset(page * size).Limit(size).
+		Find(&posts)
 
 	return posts, result.Error
 }
 
+func (repository *PostRepository) FindUnpublishedByUserID(userID string, statuses []model.PostStatus, page int, size int) ([]model.Post, error) {
+	var posts []model.Post
+	result := repository.database.Preload("Location").Preload("Media", mediaByPosition).
+		Where("user_id = ? AND status IN ?", userID, statuses).
+		Order("scheduled_at asc nulls last, created_at desc").
+		Offset(page * size).Limit(size).
+		Find(&posts)
+
+	return posts, result.Error
+}
+
+func (repository *PostRepository) FindDue(now time.Time, limit int) ([]model.Post, error) {
+	var posts []model.Post
+	result := repository.database.Where("status = ? AND scheduled_at <= ?", model.SCHEDULED, now).
+		Order("scheduled_at").
+		Limit(limit).
+		Find(&posts)
+
+	return posts, result.Error
+}
+
+func (repository *PostRepository) UpdateDraft(post *model.Post) (*model.Post, error) {
+	result := repository.database.Model(post).Select("description", "tags", "tagged_users", "status", "scheduled_at").Updates(post)
+
+	return post, result.Error
+}
+
+// Publish publishes the post only if it is still in the given status, so a post
+// is never published twice when the scheduler and its owner race for it. Its
+// hashtags are linked in the same transaction.
+func (repository *PostRepository) Publish(post *model.Post, status model.PostStatus) (bool, error) {
+	published := false
+
+	err := repository.database.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Post{}).
+			Where("id = ? AND status = ?", post.ID, status).
+			Updates(map[string]interface{}{"status": model.PUBLISHED, "scheduled_at": nil, "created_at": time.Now()})
+
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		published = true
+
+		return linkHashtags(tx, post.ID, post.Tags)
+	})
+
+	return published && err == nil, err
+}
+
+func (repository *PostRepository) UpdateSettings(post *model.Post) (*model.Post, error) {
+	result := repository.database.Model(post).Select("comments_disabled", "hide_like_counts").Updates(post)
+
+	return post, result.Error
+}
+
+// UpdateArchivedAt archives or unarchives the post. Archived posts don't count
+// as uses of their hashtags, so the counts follow the post off and back onto
+// the profile.
+func (repository *PostRepository) UpdateArchivedAt(post *model.Post) (*model.Post, error) {
+	err := repository.database.Transaction(func(tx *gorm.DB) error {
+		change := 1
+		query := tx.Model(post).Where("archived_at IS NOT NULL")
+
+		if post.ArchivedAt != nil {
+			change = -1
+			query = tx.Model(post).Where("archived_at IS NULL")
+		}
+
+		result := query.Update("archived_at", post.ArchivedAt)
+
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		return countHashtagUses(tx, post.ID, change)
+	})
+
+	return post, err
+}
+
 func (repository *PostRepository) FindLikeLocation(query string) ([]model.Post, error) {
 	var posts []model.Post
-	result := repository.database.Joins("Location").Where("country ILIKE ?", "%"+query+"%").Or("city ILIKE ?", "%"+query+"%").Find(&posts)
+	result := repository.database.Joins("Location").Scopes(onProfile).
+		Where(`"Location".name ILIKE ? OR "Location".country ILIKE ? OR "Location".city ILIKE ?`, "%"+query+"%", "%"+query+"%", "%"+query+"%").
+		Find(&posts)
+
+	return posts, result.Error
+}
+
+func (repository *PostRepository) FindByLocationID(locationID string, page int, size int) ([]model.Post, error) {
+	var posts []model.Post
+	result := repository.database.Preload("Location").Preload("Media", mediaByPosition).Scopes(onProfile).
+		Where("location_id = ?", locationID).
+		Order("created_at desc").
+		Offset(page * size).Limit(size).
+		Find(&posts)
 
 	return posts, result.Error
 }
@@ -46,13 +210,125 @@ func (repository *PostRepository) FindLikeLocation(query string) ([]model.Post,
 func (repository *PostRepository) FindLikeTags(query string) ([]model.Post, error) {
 	var posts []model.Post
 	tags := strings.Split(query, " ")
-	result := repository.database.Where("tags && ?", pq.Array(tags)).Find(&posts)
+	result := repository.database.Scopes(onProfile).Where("tags && ?", pq.Array(tags)).Find(&posts)
 
 	return posts, result.Error
 }
 
+func (repository *PostRepository) FindByIdUnscoped(id string) (*model.Post, error) {
+	var post model.Post
+	result := repository.database.Unscoped().Preload("Location").Preload("Media", mediaByPosition).First(&post, "id = ?", id)
+
+	return &post, result.Error
+}
+
+// Delete removes the post along with its hashtags. A published post on its
+// owner's profile no longer counts as a use of them.
+func (repository *PostRepository) Delete(post *model.Post) error {
+	return repository.database.Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(post)
+
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		return unlinkHashtags(tx, post.ID, post.Status == model.PUBLISHED && post.ArchivedAt == nil)
+	})
+}
+
 func (repository *PostRepository) CreateReport(report *model.Report) (*model.Report, error) {
 	result := repository.database.Create(report)
 
 	return report, result.Error
 }
+
+func (repository *PostRepository) FindAllByIDs(ids []uuid.UUID) ([]model.Post, error) {
+	var posts []model.Post
+
+	if len(ids) == 0 {
+		return posts, nil
+	}
+
+	result := repository.database.Preload("Location").Preload("Media", mediaByPosition).Where("id IN ?", ids).Find(&posts)
+
+	return posts, result.Error
+}
+
+// FindAllByIDsUnscoped also finds deleted posts, which moderators still need
+// to see.
+func (repository *PostRepository) FindAllByIDsUnscoped(ids []uuid.UUID) ([]model.Post, error) {
+	var posts []model.Post
+
+	if len(ids) == 0 {
+		return posts, nil
+	}
+
+	result := repository.database.Unscoped().Where("id IN ?", ids).Find(&posts)
+
+	return posts, result.Error
+}
+
+// Search ranks posts with Postgres full-text search over the description,
+// hashtags and location, boosting posts written by the matched authors. Results
+// are ordered by rank, then recency, and paged with a keyset on the last hit.
+func (repository *PostRepository) Search(filter *PostSearchFilter) ([]PostSearchHit, error) {
+	rank := "0::real"
+	var rankArgs []interface{}
+	conditions := []string{"posts.deleted_at IS NULL", "posts.archived_at IS NULL", "posts.status = 'PUBLISHED'"}
+	var args []interface{}
+
+	if filter.Query != "" {
+		rank = "ts_rank(" + searchDocument + ", websearch_to_tsquery('simple', ?))"
+		rankArgs = append(rankArgs, filter.Query)
+		match := "(" + searchDocument + ") @@ websearch_to_tsquery('simple', ?)"
+
+		if len(filter.AuthorIDs) > 0 {
+			rank += " + CASE WHEN posts.user_id IN ? THEN 1::real ELSE 0::real END"
+			rankArgs = append(rankArgs, filter.AuthorIDs)
+			conditions = append(conditions, "("+match+" OR posts.user_id IN ?)")
+			args = append(args, filter.Query, filter.AuthorIDs)
+		} else {
+			conditions = append(conditions, match)
+			args = append(args, filter.Query)
+		}
+	}
+
+	if filter.From != nil {
+		conditions = append(conditions, "posts.created_at >= ?")
+		args = append(args, *filter.From)
+	}
+
+	if filter.To != nil {
+		conditions = append(conditions, "posts.created_at <= ?")
+		args = append(args, *filter.To)
+	}
+
+	if filter.LocationID != uuid.Nil {
+		conditions = append(conditions, "posts.location_id = ?")
+		args = append(args, filter.LocationID)
+	}
+
+	if filter.MediaType != "" {
+		conditions = append(conditions, "EXISTS (SELECT 1 FROM post_media WHERE post_media.post_id = posts.id AND post_media.type = ?)")
+		args = append(args, filter.MediaType)
+	}
+
+	query := "SELECT posts.id, posts.created_at, " + rank + " AS rank FROM posts LEFT JOIN locations ON locations.id = posts.location_id" +
+		" WHERE " + strings.Join(conditions, " AND ")
+
+	query = "SELECT * FROM (" + query + ") AS ranked"
+	queryArgs := append(rankArgs, args...)
+
+	if filter.After != nil {
+		query += " WHERE (ranked.rank, ranked.created_at, ranked.id) < (?, ?, ?)"
+		queryArgs = append(queryArgs, filter.After.Rank, filter.After.CreatedAt, filter.After.ID)
+	}
+
+	query += " ORDER BY ranked.rank DESC, ranked.created_at DESC, ranked.id DESC LIMIT ?"
+	queryArgs = append(queryArgs, filter.Limit)
+
+	var hits []PostSearchHit
+	result := repository.database.Raw(query, queryArgs...).Scan(&hits)
+
+	return hits, result.Error
+}
@@ -57,8 +57,17 @@ func (repository *ReviewRepository) FindStatusByPostIDAndUserID(postID string, u
 	}
 }
 
+func (repository *ReviewRepository) FindUserIDsByPostIDAndStatus(postID string, status model.ReviewStatus, page int, size int) ([]uuid.UUID, error) {
+	var userIDs []uuid.UUID
+	result := repository.database.Model(&model.Review{}).Where("post_id = ? AND status = ?", postID, status).
+		Order("updated_at desc, user_id").Offset(page*size).Limit(size).Pluck("user_id", &userIDs)
+
+	return userIDs, result.Error
+}
+
 func (repository *ReviewRepository) GetReviewsByUserIDAndStatus(userID uuid.UUID, status int) ([]model.Review, error) {
 	var reviews []model.Review
-	result := repository.database.Preload("Post").Where("user_id = ? AND status = ?", userID, status).Find(&reviews)
+	result := repository.database.Preload("Post").Preload("Post.Media", mediaByPosition).Joins("JOIN posts ON posts.id = reviews.post_id AND posts.deleted_at IS NULL").
+		Where("reviews.user_id = ? AND reviews.status = ?", userID, status).Find(&reviews)
 	return reviews, result.Error
 }
@@ -3,6 +3,7 @@ package repository
 import (
 	"github.com/KristijanPill/Nishtagram/post-service/model"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type SavedPostRepository struct {
@@ -14,21 +15,18 @@ func NewSavedPostRepository(database *gorm.DB) *SavedPostRepository {
 }
 
 func (repository *SavedPostRepository) Create(savedPost *model.SavedPost) (*model.SavedPost, error) {
-	result := repository.database.Create(savedPost)
+	result := repository.database.Clauses(clause.OnConflict{DoNothing: true}).Create(savedPost)
 
 	return savedPost, result.Error
 }
 
-func (repository *SavedPostRepository) FindAllCollectionNames(userID string) ([]string, error) {
-	var collections []string
-	result := repository.database.Model(&model.SavedPost{}).Select("distinct(collection_name)").Where("user_id = ?", userID).Find(&collections)
-
-	return collections, result.Error
-}
-
-func (repository *SavedPostRepository) FindAllByUserID(userID string) ([]model.SavedPost, error) {
+func (repository *SavedPostRepository) FindAllByUserID(userID string, page int, size int) ([]model.SavedPost, error) {
 	var saved []model.SavedPost
-	result := repository.database.Preload("Post").Where("user_id = ?", userID).Order("created_at desc").Find(&saved)
+	result := repository.database.Preload("Post").Preload("Post.Location").Preload("Post.Media", mediaByPosition).
+		Joins("JOIN posts ON posts.id = saved_posts.post_id AND posts.deleted_at IS NULL").
+		Where("saved_posts.user_id = ?", userID).Order("saved_posts.created_at desc").
+		Offset(page * size).Limit(size).
+		Find(&saved)
 
 	return saved, result.Error
 }
@@ -40,14 +38,29 @@ func (repository *SavedPostRepository) ExistsByPostIDAndUserID(postID string, us
 	return result.RowsAffected != 0, result.Error
 }
 
-func (repository *SavedPostRepository) Update(savedPost *model.SavedPost) (*model.SavedPost, error) {
-	result := repository.database.Save(savedPost)
+// Delete unsaves the post and takes it out of every collection of the user.
+func (repository *SavedPostRepository) Delete(savedPost *model.SavedPost) error {
+	return repository.database.Transaction(func(tx *gorm.DB) error {
+		collections := tx.Model(&model.Collection{}).Select("id").Where("user_id = ?", savedPost.UserID)
 
-	return savedPost, result.Error
-}
+		if err := tx.Where("post_id = ? AND collection_id IN (?)", savedPost.PostID, collections).Delete(&model.CollectionPost{}).Error; err != nil {
+			return err
+		}
 
-func (repository *SavedPostRepository) Delete(savedPost *model.SavedPost) error {
-	result := repository.database.Delete(savedPost)
+		if err := tx.Model(&model.Collection{}).Where("user_id = ? AND cover_post_id = ?", savedPost.UserID, savedPost.PostID).Update("cover_post_id", nil).Error; err != nil {
+			return err
+		}
+
+		result := tx.Where("user_id = ? AND post_id = ?", savedPost.UserID, savedPost.PostID).Delete(&model.SavedPost{})
+
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
 
-	return result.Error
+		return nil
+	})
 }
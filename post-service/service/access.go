@@ -0,0 +1,66 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/KristijanPill/Nishtagram/post-service/model"
+	"github.com/KristijanPill/Nishtagram/post-service/repository"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrPrivateAccount     = errors.New("this account is private, request to follow to see its posts")
+	ErrContentUnavailable = errors.New("content unavailable")
+	ErrNotPostOwner       = errors.New("only the owner of the post can do this")
+)
+
+// checkAuthorAccess returns ErrPrivateAccount when the logged in user does not
+// follow the private author, and ErrContentUnavailable when either of them
+// blocked the other or the author no longer exists.
+func checkAuthorAccess(authorID uuid.UUID, loggedInUserID uuid.UUID, token string) error {
+	if authorID == loggedInUserID {
+		return nil
+	}
+
+	details, err := fetchUsersDetails([]uuid.UUID{authorID}, token)
+
+	if err != nil {
+		return err
+	}
+
+	userDetails, found := details[authorID]
+
+	if !found || userDetails.Blocked {
+		return ErrContentUnavailable
+	}
+
+	if userDetails.Private && !userDetails.Followed {
+		return ErrPrivateAccount
+	}
+
+	return nil
+}
+
+func checkPostAccess(postRepository *repository.PostRepository, postID uuid.UUID, loggedInUserID uuid.UUID, token string) (*model.Post, error) {
+	post, err := postRepository.FindById(postID.String())
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkHidden(post, loggedInUserID); err != nil {
+		return nil, err
+	}
+
+	return post, checkAuthorAccess(post.UserID, loggedInUserID, token)
+}
+
+// checkHidden hides drafts, scheduled and archived posts from everyone but
+// their owner.
+func checkHidden(post *model.Post, loggedInUserID uuid.UUID) error {
+	if (post.Status != model.PUBLISHED || post.ArchivedAt != nil) && post.UserID != loggedInUserID {
+		return ErrContentUnavailable
+	}
+
+	return nil
+}
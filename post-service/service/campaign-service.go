@@ -0,0 +1,517 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/KristijanPill/Nishtagram/post-service/helpers"
+	"github.com/KristijanPill/Nishtagram/post-service/model"
+	"github.com/KristijanPill/Nishtagram/post-service/payload"
+	"github.com/KristijanPill/Nishtagram/post-service/repository"
+	"github.com/google/uuid"
+)
+
+var ErrInvalidCampaignContent = errors.New("a campaign must promote one of your published posts or stories")
+
+var ErrInvalidCampaignSchedule = errors.New("invalid campaign schedule")
+
+var ErrInvalidCampaignTargeting = errors.New("invalid campaign targeting")
+
+var ErrInvalidCampaignPlacement = errors.New("invalid campaign placement")
+
+const (
+	defaultCampaignFeedInterval = 5
+	maxCampaignTimesPerDay      = 24
+	maxCampaignAge              = 120
+	campaignStatsDays           = 30
+)
+
+type CampaignService struct {
+	repository        *repository.CampaignRepository
+	postRepository    *repository.PostRepository
+	reviewRepository  *repository.ReviewRepository
+	commentRepository *repository.CommentRepository
+}
+
+func NewCampaignService(repository *repository.CampaignRepository, postRepository *repository.PostRepository,
+	reviewRepository *repository.ReviewRepository, commentRepository *repository.CommentRepository) *CampaignService {
+	return &CampaignService{
+		repository:        repository,
+		postRepository:    postRepository,
+		reviewRepository:  reviewRepository,
+		commentRepository: commentRepository,
+	}
+}
+
+func (service *CampaignService) Create(agentID uuid.UUID, dto *payload.CampaignCreate) (*payload.CampaignView, error) {
+	campaign := &model.Campaign{
+		AgentID:     agentID,
+		Name:        strings.TrimSpace(dto.Name),
+		ContentType: dto.ContentType,
+		ContentID:   dto.ContentID,
+		Schedule:    dto.Schedule,
+		StartsAt:    dto.StartsAt,
+		EndsAt:      dto.EndsAt,
+		TimesPerDay: dto.TimesPerDay,
+		MinAge:      dto.MinAge,
+		MaxAge:      dto.MaxAge,
+		Genders:     dto.Genders,
+		Countries:   normaliseTargets(dto.Countries),
+		Cities:      normaliseTargets(dto.Cities),
+	}
+
+	if err := checkCampaignSchedule(campaign); err != nil {
+		return nil, err
+	}
+
+	if err := checkCampaignTargeting(campaign); err != nil {
+		return nil, err
+	}
+
+	content, err := service.findContent(agentID, dto.ContentType, dto.ContentID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	campaign.Content = content
+
+	campaign, err = service.repository.Create(campaign)
+
+	if err != nil {
+		return nil, err
+	}
+
+	campaignView := toCampaignView(campaign)
+
+	return &campaignView, nil
+}
+
+func (service *CampaignService) FindAll(agentID uuid.UUID, page int, size int) ([]payload.CampaignView, error) {
+	campaigns, err := service.repository.FindAllByAgentID(agentID, page, size)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var campaignsView = []payload.CampaignView{}
+
+	for i := range campaigns {
+		campaignsView = append(campaignsView, toCampaignView(&campaigns[i]))
+	}
+
+	return campaignsView, nil
+}
+
+func (service *CampaignService) Delete(campaignID uuid.UUID, agentID uuid.UUID) error {
+	campaign, err := service.findOwnCampaign(campaignID, agentID)
+
+	if err != nil {
+		return err
+	}
+
+	return service.repository.Delete(campaign)
+}
+
+func (service *CampaignService) GetStats(campaignID uuid.UUID, agentID uuid.UUID) (*payload.CampaignStats, error) {
+	if _, err := service.findOwnCampaign(campaignID, agentID); err != nil {
+		return nil, err
+	}
+
+	totals, err := service.repository.FindTotals(campaignID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	today, _ := time.Parse(dayLayout, time.Now().UTC().Format(dayLayout))
+	since := today.AddDate(0, 0, 1-campaignStatsDays)
+
+	dailyEvents, err := service.repository.FindDailyEvents(campaignID, since)
+
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &payload.CampaignStats{
+		CampaignID:       campaignID,
+		Impressions:      totals.Impressions,
+		Reach:            totals.Reach,
+		Clicks:           totals.Clicks,
+		FeedImpressions:  totals.FeedImpressions,
+		StoryImpressions: totals.StoryImpressions,
+		Timeline:         make([]payload.CampaignDay, campaignStatsDays),
+	}
+
+	if totals.Impressions != 0 {
+		stats.ClickThroughRate = float64(totals.Clicks) / float64(totals.Impressions)
+	}
+
+	indexByDay := make(map[string]int)
+
+	for i := range stats.Timeline {
+		stats.Timeline[i].Day = since.AddDate(0, 0, i).Format(dayLayout)
+		indexByDay[stats.Timeline[i].Day] = i
+	}
+
+	for _, daily := range dailyEvents {
+		if i, found := indexByDay[daily.Day.Format(dayLayout)]; found {
+			stats.Timeline[i].Impressions = daily.Impressions
+			stats.Timeline[i].Clicks = daily.Clicks
+		}
+	}
+
+	return stats, nil
+}
+
+func (service *CampaignService) Click(campaignID uuid.UUID, viewerID uuid.UUID, placement model.CampaignPlacement) error {
+	if !placement.IsValid() {
+		return ErrInvalidCampaignPlacement
+	}
+
+	if _, err := service.repository.FindByID(campaignID); err != nil {
+		return err
+	}
+
+	return service.repository.RecordClick(campaignID, viewerID, placement, time.Now())
+}
+
+// InjectPosts places a sponsored post after every few posts of the feed. The
+// interval is configured with CAMPAIGN_FEED_INTERVAL. Sponsored posts follow
+// the same rules as profile posts, so posts of private agents only reach their
+// followers. Ads are best effort, so the feed is returned unchanged whenever
+// they can't be resolved.
+func (service *CampaignService) InjectPosts(posts []payload.PostView, viewerID uuid.UUID, token string) []payload.PostView {
+	interval := campaignFeedInterval()
+	slots := len(posts) / interval
+
+	if slots == 0 {
+		return posts
+	}
+
+	campaigns, err := service.findEligible(model.CAMPAIGN_POST, viewerID, slots)
+
+	if err != nil || len(campaigns) == 0 {
+		return posts
+	}
+
+	var postIDs []uuid.UUID
+	var agentIDs []uuid.UUID
+
+	for _, campaign := range campaigns {
+		postIDs = append(postIDs, campaign.ContentID)
+		agentIDs = append(agentIDs, campaign.AgentID)
+	}
+
+	promoted, err := service.postRepository.FindAllByIDs(postIDs)
+
+	if err != nil {
+		return posts
+	}
+
+	details, err := fetchUsersDetails(agentIDs, token)
+
+	if err != nil {
+		return posts
+	}
+
+	postsByID := make(map[uuid.UUID]model.Post)
+
+	for _, post := range promoted {
+		postsByID[post.ID] = post
+	}
+
+	var sponsored []payload.PostView
+	var events []model.CampaignEvent
+
+	for i := range campaigns {
+		post, found := postsByID[campaigns[i].ContentID]
+		userDetails, detailsFound := details[campaigns[i].AgentID]
+
+		if !found || !detailsFound || !canViewAuthor(userDetails, viewerID) || checkHidden(&post, viewerID) != nil {
+			continue
+		}
+
+		postView := newPostView(service.reviewRepository, service.commentRepository, &post, &userDetails, viewerID)
+		postView.Sponsored = true
+		postView.CampaignID = &campaigns[i].ID
+
+		sponsored = append(sponsored, postView)
+		events = append(events, model.CampaignEvent{
+			CampaignID: campaigns[i].ID,
+			ViewerID:   viewerID,
+			Type:       model.CAMPAIGN_IMPRESSION,
+			Placement:  model.FEED,
+		})
+	}
+
+	if err := service.repository.RecordEvents(events); err != nil {
+		return posts
+	}
+
+	var feed []payload.PostView
+
+	for i, post := range posts {
+		feed = append(feed, post)
+
+		if (i+1)%interval == 0 && len(sponsored) != 0 {
+			feed = append(feed, sponsored[0])
+			sponsored = sponsored[1:]
+		}
+	}
+
+	return feed
+}
+
+// FindSponsoredStories picks the story campaigns for the viewer's story tray and
+// counts them as seen. Like sponsored posts, stories of private agents only
+// reach their followers.
+func (service *CampaignService) FindSponsoredStories(viewerID uuid.UUID, count int, token string) ([]payload.SponsoredStory, error) {
+	campaigns, err := service.findEligible(model.CAMPAIGN_STORY, viewerID, count)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var agentIDs []uuid.UUID
+
+	for _, campaign := range campaigns {
+		agentIDs = append(agentIDs, campaign.AgentID)
+	}
+
+	details, err := fetchUsersDetails(agentIDs, token)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var stories = []payload.SponsoredStory{}
+	var events []model.CampaignEvent
+
+	for _, campaign := range campaigns {
+		userDetails, found := details[campaign.AgentID]
+
+		if !found || !canViewAuthor(userDetails, viewerID) {
+			continue
+		}
+
+		stories = append(stories, payload.SponsoredStory{
+			CampaignID: campaign.ID,
+			AgentID:    campaign.AgentID,
+			StoryID:    campaign.ContentID,
+			Content:    campaign.Content,
+		})
+		events = append(events, model.CampaignEvent{
+			CampaignID: campaign.ID,
+			ViewerID:   viewerID,
+			Type:       model.CAMPAIGN_IMPRESSION,
+			Placement:  model.STORY_TRAY,
+		})
+	}
+
+	return stories, service.repository.RecordEvents(events)
+}
+
+// FindPromotedStories tells story-service which of the stories are still used by
+// campaigns that haven't ended, because their media must not be deleted yet.
+func (service *CampaignService) FindPromotedStories(storyIDs []uuid.UUID) ([]uuid.UUID, error) {
+	promoted, err := service.repository.FindPromotedContentIDs(model.CAMPAIGN_STORY, storyIDs, time.Now())
+
+	if err != nil {
+		return nil, err
+	}
+
+	if promoted == nil {
+		promoted = []uuid.UUID{}
+	}
+
+	return promoted, nil
+}
+
+func (service *CampaignService) findEligible(contentType model.CampaignContentType, viewerID uuid.UUID, limit int) ([]model.Campaign, error) {
+	audience, err := fetchAudience(viewerID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return service.repository.FindEligible(contentType, &repository.CampaignAudience{
+		ViewerID: viewerID,
+		Age:      audience.Age,
+		Gender:   audience.Gender,
+		Country:  strings.ToLower(strings.TrimSpace(audience.Country)),
+		City:     strings.ToLower(strings.TrimSpace(audience.City)),
+	}, time.Now(), limit)
+}
+
+// findContent checks that the agent owns the promoted content. The media of a
+// story are copied into the campaign because the story itself expires.
+func (service *CampaignService) findContent(agentID uuid.UUID, contentType model.CampaignContentType, contentID uuid.UUID) ([]string, error) {
+	switch contentType {
+	case model.CAMPAIGN_POST:
+		post, err := service.postRepository.FindById(contentID.String())
+
+		if err != nil || post.UserID != agentID || post.Status != model.PUBLISHED || post.ArchivedAt != nil {
+			return nil, ErrInvalidCampaignContent
+		}
+
+		return post.Content, nil
+	case model.CAMPAIGN_STORY:
+		story, err := fetchStory(contentID)
+
+		if err != nil || story.UserID != agentID || story.CloseFriendsOnly {
+			return nil, ErrInvalidCampaignContent
+		}
+
+		return story.Content, nil
+	}
+
+	return nil, ErrInvalidCampaignContent
+}
+
+func (service *CampaignService) findOwnCampaign(campaignID uuid.UUID, agentID uuid.UUID) (*model.Campaign, error) {
+	campaign, err := service.repository.FindByID(campaignID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if campaign.AgentID != agentID {
+		return nil, ErrContentUnavailable
+	}
+
+	return campaign, nil
+}
+
+// checkCampaignSchedule fills in what a one-time campaign implies: it runs for a
+// day from its start and is shown once to each viewer.
+func checkCampaignSchedule(campaign *model.Campaign) error {
+	if campaign.StartsAt.IsZero() {
+		return ErrInvalidCampaignSchedule
+	}
+
+	switch campaign.Schedule {
+	case model.ONE_TIME:
+		campaign.EndsAt = campaign.StartsAt.Add(24 * time.Hour)
+		campaign.TimesPerDay = 1
+	case model.RECURRING:
+		if !campaign.EndsAt.After(campaign.StartsAt) || campaign.TimesPerDay < 1 || campaign.TimesPerDay > maxCampaignTimesPerDay {
+			return ErrInvalidCampaignSchedule
+		}
+	default:
+		return ErrInvalidCampaignSchedule
+	}
+
+	if !campaign.EndsAt.After(time.Now()) {
+		return ErrInvalidCampaignSchedule
+	}
+
+	return nil
+}
+
+func checkCampaignTargeting(campaign *model.Campaign) error {
+	if campaign.MinAge < 0 || campaign.MaxAge < 0 || campaign.MinAge > maxCampaignAge || campaign.MaxAge > maxCampaignAge {
+		return ErrInvalidCampaignTargeting
+	}
+
+	if campaign.MaxAge != 0 && campaign.MinAge > campaign.MaxAge {
+		return ErrInvalidCampaignTargeting
+	}
+
+	for _, gender := range campaign.Genders {
+		if gender != 0 && gender != 1 {
+			return ErrInvalidCampaignTargeting
+		}
+	}
+
+	return nil
+}
+
+func normaliseTargets(targets []string) []string {
+	var normalised []string
+
+	for _, target := range targets {
+		target = strings.ToLower(strings.TrimSpace(target))
+
+		if target != "" {
+			normalised = append(normalised, target)
+		}
+	}
+
+	return normalised
+}
+
+func campaignFeedInterval() int {
+	interval, err := strconv.Atoi(os.Getenv("CAMPAIGN_FEED_INTERVAL"))
+
+	if err != nil || interval <= 0 {
+		return defaultCampaignFeedInterval
+	}
+
+	return interval
+}
+
+func toCampaignView(campaign *model.Campaign) payload.CampaignView {
+	return payload.CampaignView{
+		ID:          campaign.ID,
+		Name:        campaign.Name,
+		ContentType: campaign.ContentType,
+		ContentID:   campaign.ContentID,
+		Content:     campaign.Content,
+		Schedule:    campaign.Schedule,
+		StartsAt:    campaign.StartsAt,
+		EndsAt:      campaign.EndsAt,
+		TimesPerDay: campaign.TimesPerDay,
+		MinAge:      campaign.MinAge,
+		MaxAge:      campaign.MaxAge,
+		Genders:     campaign.Genders,
+		Countries:   campaign.Countries,
+		Cities:      campaign.Cities,
+		CreatedAt:   campaign.CreatedAt,
+	}
+}
+
+func fetchAudience(userID uuid.UUID) (*payload.Audience, error) {
+	requestURL := fmt.Sprintf("http://%s:%s/internal/audience/%s", os.Getenv("USER_SERVICE_DOMAIN"), os.Getenv("USER_SERVICE_PORT"), userID.String())
+	response, err := http.Get(requestURL)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("user-service responded with %s", response.Status)
+	}
+
+	var audience = &payload.Audience{}
+	err = helpers.FromJSON(audience, response.Body)
+
+	return audience, err
+}
+
+func fetchStory(storyID uuid.UUID) (*payload.StoryDetails, error) {
+	requestURL := fmt.Sprintf("http://%s:%s/internal/stories/%s", os.Getenv("STORY_SERVICE_DOMAIN"), os.Getenv("STORY_SERVICE_PORT"), storyID.String())
+	response, err := http.Get(requestURL)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("story-service responded with %s", response.Status)
+	}
+
+	var story = &payload.StoryDetails{}
+	err = helpers.FromJSON(story, response.Body)
+
+	return story, err
+}
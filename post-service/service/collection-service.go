@@ -0,0 +1,297 @@
+package service
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/KristijanPill/Nishtagram/post-service/model"
+	"github.com/KristijanPill/Nishtagram/post-service/payload"
+	"github.com/KristijanPill/Nishtagram/post-service/repository"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidCollectionName = errors.New("collection name must not be empty")
+	ErrCollectionNameTaken   = errors.New("a collection with this name already exists")
+	ErrCoverNotInCollection  = errors.New("the cover post must be in the collection")
+)
+
+type CollectionService struct {
+	collectionRepository *repository.CollectionRepository
+	savedPostRepository  *repository.SavedPostRepository
+	postRepository       *repository.PostRepository
+	reviewRepository     *repository.ReviewRepository
+	commentRepository    *repository.CommentRepository
+}
+
+func NewCollectionService(collectionRepository *repository.CollectionRepository, savedPostRepository *repository.SavedPostRepository,
+	postRepository *repository.PostRepository, reviewRepository *repository.ReviewRepository, commentRepository *repository.CommentRepository) *CollectionService {
+	return &CollectionService{
+		collectionRepository: collectionRepository,
+		savedPostRepository:  savedPostRepository,
+		postRepository:       postRepository,
+		reviewRepository:     reviewRepository,
+		commentRepository:    commentRepository,
+	}
+}
+
+func (service *CollectionService) Create(dto *payload.CollectionCreate, loggedInUserID uuid.UUID) (*payload.CollectionView, error) {
+	name, err := service.checkName(dto.Name, loggedInUserID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	position, err := service.collectionRepository.FindNextPosition(loggedInUserID.String())
+
+	if err != nil {
+		return nil, err
+	}
+
+	collection, err := service.collectionRepository.Create(&model.Collection{
+		UserID:   loggedInUserID,
+		Name:     name,
+		Position: position,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &payload.CollectionView{ID: collection.ID, Name: collection.Name, Position: collection.Position}, nil
+}
+
+func (service *CollectionService) FindAllByUser(loggedInUserID uuid.UUID) ([]payload.CollectionView, error) {
+	collections, err := service.collectionRepository.FindAllByUserID(loggedInUserID.String())
+
+	if err != nil {
+		return nil, err
+	}
+
+	return service.toCollectionViews(collections)
+}
+
+// Update renames the collection and sets its cover. Without a cover post the
+// most recently added post is used as the cover.
+func (service *CollectionService) Update(collectionID uuid.UUID, dto *payload.CollectionUpdate, loggedInUserID uuid.UUID) (*payload.CollectionView, error) {
+	collection, err := service.collectionRepository.FindByIDAndUserID(collectionID.String(), loggedInUserID.String())
+
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSpace(dto.Name) != collection.Name {
+		collection.Name, err = service.checkName(dto.Name, loggedInUserID)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if dto.CoverPostID != nil {
+		contains, err := service.collectionRepository.ContainsPost(collection.ID, *dto.CoverPostID)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if !contains {
+			return nil, ErrCoverNotInCollection
+		}
+	}
+
+	collection.CoverPostID = dto.CoverPostID
+
+	collection, err = service.collectionRepository.Update(collection)
+
+	if err != nil {
+		return nil, err
+	}
+
+	collectionsView, err := service.toCollectionViews([]model.Collection{*collection})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &collectionsView[0], nil
+}
+
+func (service *CollectionService) Reorder(dto *payload.CollectionOrder, loggedInUserID uuid.UUID) error {
+	return service.collectionRepository.UpdatePositions(loggedInUserID.String(), dto.IDs)
+}
+
+// Delete removes the collection only; its posts stay saved.
+func (service *CollectionService) Delete(collectionID uuid.UUID, loggedInUserID uuid.UUID) error {
+	collection, err := service.collectionRepository.FindByIDAndUserID(collectionID.String(), loggedInUserID.String())
+
+	if err != nil {
+		return err
+	}
+
+	return service.collectionRepository.Delete(collection)
+}
+
+// AddPost adds the post to the collection, saving it first if needed.
+func (service *CollectionService) AddPost(collectionID uuid.UUID, postID uuid.UUID, loggedInUserID uuid.UUID, token string) error {
+	collection, err := service.collectionRepository.FindByIDAndUserID(collectionID.String(), loggedInUserID.String())
+
+	if err != nil {
+		return err
+	}
+
+	if _, err := checkPostAccess(service.postRepository, postID, loggedInUserID, token); err != nil {
+		return err
+	}
+
+	_, err = service.savedPostRepository.Create(&model.SavedPost{
+		UserID: loggedInUserID,
+		PostID: postID,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return service.collectionRepository.AddPost(collection.ID, postID)
+}
+
+// RemovePost takes the post out of the collection; it stays saved.
+func (service *CollectionService) RemovePost(collectionID uuid.UUID, postID uuid.UUID, loggedInUserID uuid.UUID) error {
+	collection, err := service.collectionRepository.FindByIDAndUserID(collectionID.String(), loggedInUserID.String())
+
+	if err != nil {
+		return err
+	}
+
+	return service.collectionRepository.RemovePost(collection, postID)
+}
+
+func (service *CollectionService) FindPosts(collectionID uuid.UUID, loggedInUserID uuid.UUID, page int, size int, token string) (*payload.CollectionPage, error) {
+	collection, err := service.collectionRepository.FindByIDAndUserID(collectionID.String(), loggedInUserID.String())
+
+	if err != nil {
+		return nil, err
+	}
+
+	collectionsView, err := service.toCollectionViews([]model.Collection{*collection})
+
+	if err != nil {
+		return nil, err
+	}
+
+	posts, err := service.collectionRepository.FindPostsByCollectionID(collection.ID, page, size)
+
+	if err != nil {
+		return nil, err
+	}
+
+	postsView, err := visiblePostViews(service.reviewRepository, service.commentRepository, posts, loggedInUserID, token)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &payload.CollectionPage{
+		Collection: collectionsView[0],
+		Page:       page,
+		Size:       size,
+		Posts:      postsView,
+	}, nil
+}
+
+func (service *CollectionService) checkName(name string, loggedInUserID uuid.UUID) (string, error) {
+	name = strings.TrimSpace(name)
+
+	if name == "" {
+		return "", ErrInvalidCollectionName
+	}
+
+	exists, err := service.collectionRepository.ExistsByNameAndUserID(name, loggedInUserID.String())
+
+	if err != nil {
+		return "", err
+	}
+
+	if exists {
+		return "", ErrCollectionNameTaken
+	}
+
+	return name, nil
+}
+
+// toCollectionViews counts the posts of all collections and loads their cover
+// posts with one query each.
+func (service *CollectionService) toCollectionViews(collections []model.Collection) ([]payload.CollectionView, error) {
+	var collectionsView = []payload.CollectionView{}
+
+	if len(collections) == 0 {
+		return collectionsView, nil
+	}
+
+	var collectionIDs []uuid.UUID
+
+	for _, collection := range collections {
+		collectionIDs = append(collectionIDs, collection.ID)
+	}
+
+	counts, err := service.collectionRepository.FindPostCounts(collectionIDs)
+
+	if err != nil {
+		return nil, err
+	}
+
+	countsByCollectionID := make(map[uuid.UUID]repository.CollectionPostCount)
+
+	for _, count := range counts {
+		countsByCollectionID[count.CollectionID] = count
+	}
+
+	coverPostIDs := make(map[uuid.UUID]uuid.UUID)
+	var postIDs []uuid.UUID
+
+	for _, collection := range collections {
+		count, found := countsByCollectionID[collection.ID]
+
+		if !found {
+			continue
+		}
+
+		coverPostID := count.LastPostID
+
+		if collection.CoverPostID != nil {
+			coverPostID = *collection.CoverPostID
+		}
+
+		coverPostIDs[collection.ID] = coverPostID
+		postIDs = append(postIDs, coverPostID)
+	}
+
+	covers := make(map[uuid.UUID]string)
+
+	if len(postIDs) != 0 {
+		posts, err := service.postRepository.FindAllByIDs(postIDs)
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, post := range posts {
+			if len(post.Content) != 0 {
+				covers[post.ID] = post.Content[0]
+			}
+		}
+	}
+
+	for _, collection := range collections {
+		collectionsView = append(collectionsView, payload.CollectionView{
+			ID:            collection.ID,
+			Name:          collection.Name,
+			CoverImage:    covers[coverPostIDs[collection.ID]],
+			NumberOfPosts: countsByCollectionID[collection.ID].Posts,
+			Position:      collection.Position,
+		})
+	}
+
+	return collectionsView, nil
+}
@@ -1,78 +1,225 @@
 package service
 
 import (
-	"bytes"
-	"encoding/json"
-	"fmt"
-	"net/http"
-	"os"
+	"errors"
+	"strings"
 
-	"github.com/KristijanPill/Nishtagram/post-service/helpers"
 	"github.com/KristijanPill/Nishtagram/post-service/model"
 	"github.com/KristijanPill/Nishtagram/post-service/payload"
 	"github.com/KristijanPill/Nishtagram/post-service/repository"
 	"github.com/google/uuid"
 )
 
+var (
+	ErrCommentsDisabled         = errors.New("commenting is turned off for this post")
+	ErrInvalidHeldCommentAction = errors.New("action must be APPROVE or REMOVE")
+)
+
+const maxKeywordLength = 100
+
 type CommentService struct {
-	repository *repository.CommentRepository
+	repository        *repository.CommentRepository
+	postRepository    *repository.PostRepository
+	keywordRepository *repository.KeywordRepository
 }
 
-func NewCommentService(repository *repository.CommentRepository) *CommentService {
-	return &CommentService{repository: repository}
+func NewCommentService(repository *repository.CommentRepository, postRepository *repository.PostRepository, keywordRepository *repository.KeywordRepository) *CommentService {
+	return &CommentService{repository: repository, postRepository: postRepository, keywordRepository: keywordRepository}
 }
 
-func (service *CommentService) Create(dto *payload.CommentCreate) (*model.Comment, error) {
+// Create adds the comment, holding it back when it contains one of the keywords
+// filtered by the post owner. Held comments are only shown to their author
+// until the owner approves them.
+func (service *CommentService) Create(dto *payload.CommentCreate, token string) (*model.Comment, error) {
+	post, err := checkPostAccess(service.postRepository, dto.PostID, dto.UserID, token)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if post.CommentsDisabled {
+		return nil, ErrCommentsDisabled
+	}
+
 	comment := &model.Comment{
 		PostID:             dto.PostID,
 		UserID:             dto.UserID,
 		Content:            dto.Content,
 		RepliedToCommentID: dto.RepliedToCommentID,
+		Status:             model.VISIBLE,
+	}
+
+	if dto.UserID != post.UserID {
+		keywords, err := service.keywordRepository.FindByUserID(post.UserID.String())
+
+		if err != nil {
+			return nil, err
+		}
+
+		if containsKeyword(dto.Content, keywords) {
+			comment.Status = model.HELD
+		}
 	}
 
 	return service.repository.Create(comment)
 }
 
-func (service *CommentService) FindAllByPostID(id uuid.UUID) ([]payload.CommentView, error) {
-	comments, err := service.repository.FindAllByPostID(id.String())
+func (service *CommentService) FindAllByPostID(id uuid.UUID, loggedInUserID uuid.UUID, token string) ([]payload.CommentView, error) {
+	post, err := checkPostAccess(service.postRepository, id, loggedInUserID, token)
 
 	if err != nil {
 		return nil, err
 	}
 
-	var userIDs = &payload.UserIDs{}
+	if post.CommentsDisabled {
+		return []payload.CommentView{}, nil
+	}
 
-	for _, comment := range comments {
-		userIDs.IDs = append(userIDs.IDs, payload.UserID{ID: comment.UserID})
+	comments, err := service.repository.FindAllByPostID(id.String(), loggedInUserID.String())
+
+	if err != nil {
+		return nil, err
+	}
+
+	return toCommentViews(comments, token)
+}
+
+func (service *CommentService) FindHeld(loggedInUserID uuid.UUID, postID uuid.UUID, page int, size int, token string) ([]payload.HeldCommentView, error) {
+	var postIDString string
+
+	if postID != uuid.Nil {
+		postIDString = postID.String()
+	}
+
+	comments, err := service.repository.FindHeldByPostOwner(loggedInUserID.String(), postIDString, page, size)
+
+	if err != nil {
+		return nil, err
+	}
+
+	commentsView, err := toCommentViews(comments, token)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var heldCommentsView = []payload.HeldCommentView{}
+
+	for i, comment := range comments {
+		heldCommentsView = append(heldCommentsView, payload.HeldCommentView{
+			CommentView: commentsView[i],
+			PostID:      comment.PostID,
+			CreatedAt:   comment.CreatedAt,
+		})
 	}
 
-	requestJSON, err := json.Marshal(userIDs)
+	return heldCommentsView, nil
+}
+
+// ReviewHeld approves or removes held comments in bulk. Only comments held on
+// posts of the logged in user are affected.
+func (service *CommentService) ReviewHeld(loggedInUserID uuid.UUID, dto *payload.HeldCommentReview) (*payload.HeldCommentReviewResult, error) {
+	var reviewed int64
+	var err error
+
+	if len(dto.CommentIDs) == 0 {
+		return &payload.HeldCommentReviewResult{}, nil
+	}
+
+	switch dto.Action {
+	case model.APPROVE:
+		reviewed, err = service.repository.ApproveHeld(loggedInUserID.String(), dto.CommentIDs)
+	case model.REMOVE:
+		reviewed, err = service.repository.DeleteHeld(loggedInUserID.String(), dto.CommentIDs)
+	default:
+		return nil, ErrInvalidHeldCommentAction
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &payload.HeldCommentReviewResult{Reviewed: reviewed}, nil
+}
+
+func (service *CommentService) GetKeywords(loggedInUserID uuid.UUID) (*payload.FilteredKeywords, error) {
+	keywords, err := service.keywordRepository.FindByUserID(loggedInUserID.String())
 
 	if err != nil {
 		return nil, err
 	}
 
-	requestURL := fmt.Sprintf("http://%s:%s/users-details", os.Getenv("USER_SERVICE_DOMAIN"), os.Getenv("USER_SERVICE_PORT"))
-	response, err := http.Post(requestURL, "application/json", bytes.NewBuffer(requestJSON))
+	return &payload.FilteredKeywords{Keywords: append([]string{}, keywords...)}, nil
+}
+
+func (service *CommentService) SetKeywords(loggedInUserID uuid.UUID, dto *payload.FilteredKeywords) (*payload.FilteredKeywords, error) {
+	var keywords []model.FilteredKeyword
+	var names = []string{}
+	seen := make(map[string]bool)
+
+	for _, keyword := range dto.Keywords {
+		keyword = strings.ToLower(strings.TrimSpace(keyword))
+
+		if runes := []rune(keyword); len(runes) > maxKeywordLength {
+			keyword = string(runes[:maxKeywordLength])
+		}
+
+		if keyword == "" || seen[keyword] {
+			continue
+		}
+
+		seen[keyword] = true
+		names = append(names, keyword)
+		keywords = append(keywords, model.FilteredKeyword{UserID: loggedInUserID, Keyword: keyword})
+	}
+
+	err := service.keywordRepository.Replace(loggedInUserID.String(), keywords)
 
 	if err != nil {
 		return nil, err
 	}
 
-	var details = &payload.UsersDetails{}
+	return &payload.FilteredKeywords{Keywords: names}, nil
+}
+
+// containsKeyword matches case-insensitively anywhere in the comment, so the
+// filter also catches the keyword inside longer words.
+func containsKeyword(content string, keywords []string) bool {
+	content = strings.ToLower(content)
+
+	for _, keyword := range keywords {
+		if strings.Contains(content, keyword) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func toCommentViews(comments []model.Comment, token string) ([]payload.CommentView, error) {
+	var userIDs []uuid.UUID
+
+	for _, comment := range comments {
+		userIDs = append(userIDs, comment.UserID)
+	}
+
+	details, err := fetchUsersDetails(userIDs, token)
 
-	helpers.FromJSON(details, response.Body)
+	if err != nil {
+		return nil, err
+	}
 
 	var commentsView = []payload.CommentView{}
 
-	for i, comment := range comments {
+	for _, comment := range comments {
 		commentView := payload.CommentView{
 			ID:                 comment.ID,
 			UserID:             comment.UserID,
-			Username:           details.UsersDetails[i].Username,
-			ProfilePicture:     details.UsersDetails[i].ProfilePicture,
+			Username:           details[comment.UserID].Username,
+			ProfilePicture:     details[comment.UserID].ProfilePicture,
 			Content:            comment.Content,
 			RepliedToCommentID: comment.RepliedToCommentID,
+			Held:               comment.Status == model.HELD,
 		}
 
 		commentsView = append(commentsView, commentView)
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/KristijanPill/Nishtagram/post-service/model"
+	"github.com/KristijanPill/Nishtagram/post-service/payload"
+	"github.com/KristijanPill/Nishtagram/post-service/repository"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidSchedule  = errors.New("posts can only be scheduled for a future time")
+	ErrNotPublished     = errors.New("the post is not published yet")
+	ErrAlreadyPublished = errors.New("the post is already published")
+)
+
+const dueBatchSize = 100
+
+type DraftService struct {
+	postRepository *repository.PostRepository
+}
+
+func NewDraftService(postRepository *repository.PostRepository) *DraftService {
+	return &DraftService{postRepository: postRepository}
+}
+
+// CheckSchedule makes sure a scheduled post has a publish time in the future.
+func CheckSchedule(status model.PostStatus, scheduledAt *time.Time) error {
+	if status == model.SCHEDULED && (scheduledAt == nil || !scheduledAt.After(time.Now())) {
+		return ErrInvalidSchedule
+	}
+
+	return nil
+}
+
+// FindAll lists the drafts and scheduled posts of the user, the ones due first.
+func (service *DraftService) FindAll(loggedInUserID uuid.UUID, status model.PostStatus, page int, size int) ([]payload.DraftView, error) {
+	statuses := []model.PostStatus{model.DRAFT, model.SCHEDULED}
+
+	if status != "" {
+		statuses = []model.PostStatus{status}
+	}
+
+	posts, err := service.postRepository.FindUnpublishedByUserID(loggedInUserID.String(), statuses, page, size)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var draftsView = []payload.DraftView{}
+
+	for i := range posts {
+		draftsView = append(draftsView, toDraftView(&posts[i]))
+	}
+
+	return draftsView, nil
+}
+
+// Update replaces the editable fields of a draft or scheduled post. Setting a
+// publication time schedules the post, leaving it out turns it back into a draft.
+func (service *DraftService) Update(postID uuid.UUID, dto *payload.DraftUpdate, loggedInUserID uuid.UUID) (*payload.DraftView, error) {
+	post, err := service.findUnpublished(postID, loggedInUserID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	post.Description = dto.Description
+	post.Tags = collectHashtags(dto.Description, dto.Tags)
+	post.TaggedUsers = nil
+
+	for _, taggedUser := range dto.TaggedUsers {
+		post.TaggedUsers = append(post.TaggedUsers, taggedUser.String())
+	}
+
+	post.Status = model.DRAFT
+	post.ScheduledAt = dto.ScheduledAt
+
+	if dto.ScheduledAt != nil {
+		post.Status = model.SCHEDULED
+	}
+
+	if err := CheckSchedule(post.Status, post.ScheduledAt); err != nil {
+		return nil, err
+	}
+
+	post, err = service.postRepository.UpdateDraft(post)
+
+	if err != nil {
+		return nil, err
+	}
+
+	draftView := toDraftView(post)
+
+	return &draftView, nil
+}
+
+func (service *DraftService) Publish(postID uuid.UUID, loggedInUserID uuid.UUID) error {
+	post, err := service.findUnpublished(postID, loggedInUserID)
+
+	if err != nil {
+		return err
+	}
+
+	published, err := service.postRepository.Publish(post, post.Status)
+
+	if err != nil {
+		return err
+	}
+
+	if !published {
+		return ErrAlreadyPublished
+	}
+
+	return nil
+}
+
+// Delete discards a draft or cancels a scheduled post.
+func (service *DraftService) Delete(postID uuid.UUID, loggedInUserID uuid.UUID) error {
+	post, err := service.findUnpublished(postID, loggedInUserID)
+
+	if err != nil {
+		return err
+	}
+
+	return service.postRepository.Delete(post)
+}
+
+// PublishDue publishes every scheduled post whose time has come. Schedules live
+// in the database, so posts that fell due while the service was down are
+// published on the first run after it starts again.
+func (service *DraftService) PublishDue() {
+	for {
+		posts, err := service.postRepository.FindDue(time.Now(), dueBatchSize)
+
+		if err != nil {
+			log.Println("finding due posts:", err)
+
+			return
+		}
+
+		for i := range posts {
+			if _, err := service.postRepository.Publish(&posts[i], model.SCHEDULED); err != nil {
+				log.Println("publishing post", posts[i].ID, ":", err)
+			}
+		}
+
+		if len(posts) < dueBatchSize {
+			return
+		}
+	}
+}
+
+func (service *DraftService) findUnpublished(postID uuid.UUID, loggedInUserID uuid.UUID) (*model.Post, error) {
+	post, err := service.postRepository.FindById(postID.String())
+
+	if err != nil {
+		return nil, err
+	}
+
+	if post.UserID != loggedInUserID {
+		return nil, ErrNotPostOwner
+	}
+
+	if post.Status == model.PUBLISHED {
+		return nil, ErrAlreadyPublished
+	}
+
+	return post, nil
+}
+
+func toDraftView(post *model.Post) payload.DraftView {
+	return payload.DraftView{
+		ID:          post.ID,
+		Content:     post.Content,
+		Media:       toMediaViews(post.Media),
+		Description: post.Description,
+		Tags:        post.Tags,
+		TaggedUsers: post.TaggedUsers,
+		Location:    post.Location,
+		Status:      post.Status,
+		ScheduledAt: post.ScheduledAt,
+		CreatedAt:   post.CreatedAt,
+	}
+}
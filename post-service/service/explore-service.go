@@ -0,0 +1,148 @@
+package service
+
+import (
+	"time"
+
+	"github.com/KristijanPill/Nishtagram/post-service/helpers"
+	"github.com/KristijanPill/Nishtagram/post-service/model"
+	"github.com/KristijanPill/Nishtagram/post-service/payload"
+	"github.com/KristijanPill/Nishtagram/post-service/repository"
+	"github.com/google/uuid"
+)
+
+const (
+	exploreWindow        = 7 * 24 * time.Hour
+	explorePostsByAuthor = 3
+	exploreMaxBatches    = 5
+)
+
+type ExploreService struct {
+	repository        *repository.ExploreRepository
+	postRepository    *repository.PostRepository
+	reviewRepository  *repository.ReviewRepository
+	commentRepository *repository.CommentRepository
+	campaignService   *CampaignService
+}
+
+type exploreCursor struct {
+	At    time.Time             `json:"at"`
+	After repository.ExploreHit `json:"after"`
+}
+
+func NewExploreService(repository *repository.ExploreRepository, postRepository *repository.PostRepository,
+	reviewRepository *repository.ReviewRepository, commentRepository *repository.CommentRepository, campaignService *CampaignService) *ExploreService {
+	return &ExploreService{repository: repository, postRepository: postRepository, reviewRepository: reviewRepository, commentRepository: commentRepository, campaignService: campaignService}
+}
+
+// Explore recommends recent public posts of accounts the viewer does not
+// follow. Mutes only exist on follows, so leaving out followed accounts also
+// leaves out muted ones. The ranking time is carried in the cursor, and ranked
+// posts are fetched in batches until the page is full because some of them are
+// filtered out only after asking user-service about their authors.
+func (service *ExploreService) Explore(loggedInUserID uuid.UUID, cursor string, limit int, token string) (*payload.ExplorePage, error) {
+	filter := &repository.ExploreFilter{
+		ViewerID:      loggedInUserID,
+		At:            time.Now(),
+		PostsByAuthor: explorePostsByAuthor,
+		Limit:         limit * 2,
+	}
+
+	if cursor != "" {
+		decoded := &exploreCursor{}
+
+		if err := helpers.DecodeCursor(cursor, decoded); err != nil {
+			return nil, ErrInvalidCursor
+		}
+
+		filter.At = decoded.At
+		filter.After = &decoded.After
+	}
+
+	filter.Since = filter.At.Add(-exploreWindow)
+
+	var picked []repository.ExploreHit
+	detailsByID := make(map[uuid.UUID]payload.UserDetails)
+	exhausted := false
+
+	for batch := 0; batch < exploreMaxBatches && len(picked) < limit && !exhausted; batch++ {
+		hits, err := service.repository.FindRanked(filter)
+
+		if err != nil {
+			return nil, err
+		}
+
+		exhausted = len(hits) < filter.Limit
+
+		var authorIDs []uuid.UUID
+
+		for _, hit := range hits {
+			authorIDs = append(authorIDs, hit.UserID)
+		}
+
+		details, err := fetchUsersDetails(authorIDs, token)
+
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range hits {
+			filter.After = &hits[i]
+			userDetails, found := details[hits[i].UserID]
+
+			if !found || userDetails.Followed || userDetails.Blocked || userDetails.Private {
+				continue
+			}
+
+			detailsByID[userDetails.ID] = userDetails
+			picked = append(picked, hits[i])
+
+			if len(picked) == limit {
+				exhausted = exhausted && i == len(hits)-1
+				break
+			}
+		}
+	}
+
+	var ids []uuid.UUID
+
+	for _, hit := range picked {
+		ids = append(ids, hit.ID)
+	}
+
+	posts, err := service.postRepository.FindAllByIDs(ids)
+
+	if err != nil {
+		return nil, err
+	}
+
+	postsByID := make(map[uuid.UUID]model.Post)
+
+	for _, post := range posts {
+		postsByID[post.ID] = post
+	}
+
+	page := &payload.ExplorePage{Posts: []payload.PostView{}}
+
+	for _, hit := range picked {
+		post, found := postsByID[hit.ID]
+
+		if !found {
+			continue
+		}
+
+		userDetails := detailsByID[hit.UserID]
+		page.Posts = append(page.Posts, newPostView(service.reviewRepository, service.commentRepository, &post, &userDetails, loggedInUserID))
+	}
+
+	page.Posts = service.campaignService.InjectPosts(page.Posts, loggedInUserID, token)
+
+	if !exhausted && filter.After != nil {
+		page.NextCursor, err = helpers.EncodeCursor(&exploreCursor{At: filter.At, After: *filter.After})
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return page, nil
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/KristijanPill/Nishtagram/post-service/payload"
+	"github.com/KristijanPill/Nishtagram/post-service/repository"
+)
+
+var hashtagPattern = regexp.MustCompile(`#([\p{L}\p{N}_]+)`)
+
+type HashtagService struct {
+	repository *repository.HashtagRepository
+}
+
+func NewHashtagService(repository *repository.HashtagRepository) *HashtagService {
+	return &HashtagService{repository: repository}
+}
+
+// GetTrending scores hashtags by how much faster they are used now than over the
+// past week: the average hourly rate of the last hour and the last day minus the
+// weekly hourly rate, so steadily popular tags rank below ones that are taking off.
+func (service *HashtagService) GetTrending(limit int) ([]payload.TrendingHashtag, error) {
+	usages, err := service.repository.FindRecentUsage()
+
+	if err != nil {
+		return nil, err
+	}
+
+	var trending = []payload.TrendingHashtag{}
+
+	for _, usage := range usages {
+		if usage.LastDay == 0 {
+			continue
+		}
+
+		hourRate := float64(usage.LastHour)
+		dayRate := float64(usage.LastDay) / 24
+		weekRate := float64(usage.LastWeek) / (7 * 24)
+
+		trending = append(trending, payload.TrendingHashtag{
+			Name:     usage.Name,
+			LastHour: usage.LastHour,
+			LastDay:  usage.LastDay,
+			LastWeek: usage.LastWeek,
+			Score:    (hourRate+dayRate)/2 - weekRate,
+		})
+	}
+
+	sort.SliceStable(trending, func(i, j int) bool {
+		if trending[i].Score == trending[j].Score {
+			return trending[i].LastDay > trending[j].LastDay
+		}
+
+		return trending[i].Score > trending[j].Score
+	})
+
+	if len(trending) > limit {
+		trending = trending[:limit]
+	}
+
+	return trending, nil
+}
+
+func normaliseHashtag(tag string) string {
+	return strings.ToLower(strings.TrimLeft(strings.TrimSpace(tag), "#"))
+}
+
+// collectHashtags merges the hashtags written in the description with the ones
+// sent explicitly, normalised and without duplicates.
+func collectHashtags(description string, tags []string) []string {
+	var hashtags []string
+	seen := make(map[string]bool)
+
+	for _, match := range hashtagPattern.FindAllStringSubmatch(description, -1) {
+		tags = append(tags, match[1])
+	}
+
+	for _, tag := range tags {
+		name := normaliseHashtag(tag)
+
+		if name == "" || seen[name] {
+			continue
+		}
+
+		seen[name] = true
+		hashtags = append(hashtags, name)
+	}
+
+	return hashtags
+}
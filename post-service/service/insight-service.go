@@ -0,0 +1,188 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/KristijanPill/Nishtagram/post-service/model"
+	"github.com/KristijanPill/Nishtagram/post-service/payload"
+	"github.com/KristijanPill/Nishtagram/post-service/repository"
+	"github.com/google/uuid"
+)
+
+var ErrInvalidPostEvent = errors.New("invalid post event")
+
+var ErrTooManyPostEvents = errors.New("too many events in one batch")
+
+const maxPostEventBatch = 100
+
+const dayLayout = "2006-01-02"
+
+type InsightService struct {
+	repository       *repository.InsightRepository
+	postRepository   *repository.PostRepository
+	reviewRepository *repository.ReviewRepository
+}
+
+func NewInsightService(repository *repository.InsightRepository, postRepository *repository.PostRepository, reviewRepository *repository.ReviewRepository) *InsightService {
+	return &InsightService{repository: repository, postRepository: postRepository, reviewRepository: reviewRepository}
+}
+
+// Record stores a batch of events sent by the client. Owners viewing their own
+// posts, posts that no longer exist and posts the viewer can't see are skipped.
+// The owners are looked up once per batch, which also tells whether the viewer
+// follows them so reach can be split by it.
+func (service *InsightService) Record(viewerID uuid.UUID, dto *payload.PostEvents, token string) error {
+	if len(dto.Events) > maxPostEventBatch {
+		return ErrTooManyPostEvents
+	}
+
+	var postIDs []uuid.UUID
+
+	for _, event := range dto.Events {
+		if event.PostID == uuid.Nil || !event.Type.IsValid() {
+			return ErrInvalidPostEvent
+		}
+
+		postIDs = append(postIDs, event.PostID)
+	}
+
+	posts, err := service.postRepository.FindAllByIDs(postIDs)
+
+	if err != nil {
+		return err
+	}
+
+	ownerByPostID := make(map[uuid.UUID]uuid.UUID)
+	var ownerIDs []uuid.UUID
+
+	for _, post := range posts {
+		if post.UserID == viewerID || checkHidden(&post, viewerID) != nil {
+			continue
+		}
+
+		ownerByPostID[post.ID] = post.UserID
+		ownerIDs = append(ownerIDs, post.UserID)
+	}
+
+	details, err := fetchUsersDetails(ownerIDs, token)
+
+	if err != nil {
+		return err
+	}
+
+	day, _ := time.Parse(dayLayout, time.Now().UTC().Format(dayLayout))
+	seen := make(map[model.PostEvent]bool)
+	var events []model.PostEvent
+
+	for _, event := range dto.Events {
+		ownerID, found := ownerByPostID[event.PostID]
+		ownerDetails, detailsFound := details[ownerID]
+
+		if !found || !detailsFound || !canViewAuthor(ownerDetails, viewerID) {
+			continue
+		}
+
+		postEvent := model.PostEvent{
+			PostID:   event.PostID,
+			ViewerID: viewerID,
+			Type:     event.Type,
+			Day:      day,
+			Follower: ownerDetails.Followed,
+		}
+
+		if seen[postEvent] {
+			continue
+		}
+
+		seen[postEvent] = true
+		events = append(events, postEvent)
+	}
+
+	return service.repository.RecordEvents(events)
+}
+
+// GetInsights aggregates the recorded events together with the post's reviews
+// and saves. The timeline covers the last given number of days, including days
+// without any activity.
+func (service *InsightService) GetInsights(postID uuid.UUID, loggedInUserID uuid.UUID, days int) (*payload.PostInsights, error) {
+	post, err := service.postRepository.FindById(postID.String())
+
+	if err != nil {
+		return nil, err
+	}
+
+	if post.UserID != loggedInUserID {
+		return nil, ErrNotPostOwner
+	}
+
+	totals, err := service.repository.FindTotals(postID.String())
+
+	if err != nil {
+		return nil, err
+	}
+
+	saves, err := service.repository.FindSaveCount(postID.String())
+
+	if err != nil {
+		return nil, err
+	}
+
+	insights := &payload.PostInsights{
+		PostID:           postID,
+		Impressions:      totals.Impressions,
+		Reach:            totals.Reach,
+		FollowerReach:    totals.FollowerReach,
+		NonFollowerReach: totals.NonFollowerReach,
+		ProfileVisits:    totals.ProfileVisits,
+		Saves:            saves,
+		Shares:           totals.Shares,
+		Likes:            service.reviewRepository.FindCountByPostIDAndStatus(postID.String(), model.LIKE),
+		Dislikes:         service.reviewRepository.FindCountByPostIDAndStatus(postID.String(), model.DISLIKE),
+	}
+
+	if insights.Likes+insights.Dislikes > 0 {
+		insights.LikeRatio = float64(insights.Likes) / float64(insights.Likes+insights.Dislikes)
+	}
+
+	today, _ := time.Parse(dayLayout, time.Now().UTC().Format(dayLayout))
+	since := today.AddDate(0, 0, 1-days)
+
+	dailyEvents, err := service.repository.FindDailyEvents(postID.String(), since)
+
+	if err != nil {
+		return nil, err
+	}
+
+	dailyReviews, err := service.repository.FindDailyReviews(postID.String(), since)
+
+	if err != nil {
+		return nil, err
+	}
+
+	timeline := make([]payload.InsightDay, days)
+	indexByDay := make(map[string]int)
+
+	for i := range timeline {
+		timeline[i].Day = since.AddDate(0, 0, i).Format(dayLayout)
+		indexByDay[timeline[i].Day] = i
+	}
+
+	for _, daily := range dailyEvents {
+		if i, found := indexByDay[daily.Day.Format(dayLayout)]; found {
+			timeline[i].Impressions = daily.Impressions
+			timeline[i].Reach = daily.Reach
+		}
+	}
+
+	for _, daily := range dailyReviews {
+		if i, found := indexByDay[daily.Day.Format(dayLayout)]; found {
+			timeline[i].Likes = daily.Likes
+			timeline[i].Dislikes = daily.Dislikes
+		}
+	}
+
+	insights.Timeline = timeline
+
+	return insights, nil
+}
@@ -1,18 +1,145 @@
 package service
 
 import (
+	"errors"
+	"math"
+	"strings"
+
 	"github.com/KristijanPill/Nishtagram/post-service/model"
+	"github.com/KristijanPill/Nishtagram/post-service/payload"
 	"github.com/KristijanPill/Nishtagram/post-service/repository"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidLocation    = errors.New("a location needs a name and valid coordinates")
+	ErrInvalidCoordinates = errors.New("latitude must be between -90 and 90 and longitude between -180 and 180")
+	ErrInvalidRadius      = errors.New("radius must be a positive number of meters")
+)
+
+const (
+	duplicateLocationRadius = 50.0
+	defaultNearbyRadius     = 1000.0
+	maxNearbyRadius         = 50000.0
 )
 
 type LocationService struct {
-	repository *repository.LocationRepository
+	repository        *repository.LocationRepository
+	postRepository    *repository.PostRepository
+	reviewRepository  *repository.ReviewRepository
+	commentRepository *repository.CommentRepository
 }
 
-func NewLocationService(repository *repository.LocationRepository) *LocationService {
-	return &LocationService{repository: repository}
+func NewLocationService(repository *repository.LocationRepository, postRepository *repository.PostRepository,
+	reviewRepository *repository.ReviewRepository, commentRepository *repository.CommentRepository) *LocationService {
+	return &LocationService{
+		repository:        repository,
+		postRepository:    postRepository,
+		reviewRepository:  reviewRepository,
+		commentRepository: commentRepository,
+	}
 }
 
 func (service *LocationService) GetByQuery(query string) ([]model.Location, error) {
 	return service.repository.GetByQuery(query)
 }
+
+// Create adds the location unless a location with the same name already exists
+// close by, in which case that one is returned and created is false.
+func (service *LocationService) Create(dto *payload.LocationCreate) (location *model.Location, created bool, err error) {
+	name := strings.TrimSpace(dto.Name)
+
+	if name == "" || dto.Latitude == nil || dto.Longitude == nil {
+		return nil, false, ErrInvalidLocation
+	}
+
+	if !validCoordinates(*dto.Latitude, *dto.Longitude) {
+		return nil, false, ErrInvalidCoordinates
+	}
+
+	location, err = service.repository.FindDuplicate(name, *dto.Latitude, *dto.Longitude, duplicateLocationRadius)
+
+	if err == nil {
+		return location, false, nil
+	}
+
+	location, err = service.repository.Create(&model.Location{
+		Name:      name,
+		Address:   strings.TrimSpace(dto.Address),
+		Country:   strings.TrimSpace(dto.Country),
+		City:      strings.TrimSpace(dto.City),
+		Latitude:  dto.Latitude,
+		Longitude: dto.Longitude,
+	})
+
+	return location, err == nil, err
+}
+
+// FindNearby lists the locations within radius meters of the point, closest
+// first. The radius defaults to a kilometer and is capped at 50 kilometers.
+func (service *LocationService) FindNearby(latitude float64, longitude float64, radius float64, limit int) ([]payload.NearbyLocation, error) {
+	if !validCoordinates(latitude, longitude) {
+		return nil, ErrInvalidCoordinates
+	}
+
+	if radius == 0 {
+		radius = defaultNearbyRadius
+	}
+
+	if radius < 0 || math.IsNaN(radius) || math.IsInf(radius, 0) {
+		return nil, ErrInvalidRadius
+	}
+
+	if radius > maxNearbyRadius {
+		radius = maxNearbyRadius
+	}
+
+	locations, err := service.repository.FindNearby(latitude, longitude, radius, limit)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var nearby = []payload.NearbyLocation{}
+
+	for _, location := range locations {
+		nearby = append(nearby, payload.NearbyLocation{Location: location.Location, Distance: location.Distance})
+	}
+
+	return nearby, nil
+}
+
+func (service *LocationService) GetPage(locationID uuid.UUID, loggedInUserID uuid.UUID, page int, size int, token string) (*payload.LocationPage, error) {
+	location, err := service.repository.FindByID(locationID.String())
+
+	if err != nil {
+		return nil, err
+	}
+
+	posts, err := service.postRepository.FindByLocationID(location.ID.String(), page, size)
+
+	if err != nil {
+		return nil, err
+	}
+
+	postsView, err := visiblePostViews(service.reviewRepository, service.commentRepository, posts, loggedInUserID, token)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &payload.LocationPage{
+		Location: *location,
+		Page:     page,
+		Size:     size,
+		Posts:    postsView,
+	}, nil
+}
+
+func validCoordinates(latitude float64, longitude float64) bool {
+	if math.IsNaN(latitude) || math.IsNaN(longitude) || math.IsInf(latitude, 0) || math.IsInf(longitude, 0) {
+		return false
+	}
+
+	return latitude >= -90 && latitude <= 90 && longitude >= -180 && longitude <= 180
+}
@@ -0,0 +1,381 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+
+	"github.com/KristijanPill/Nishtagram/post-service/model"
+	"github.com/KristijanPill/Nishtagram/post-service/payload"
+	"github.com/KristijanPill/Nishtagram/post-service/repository"
+	"github.com/google/uuid"
+)
+
+var ErrInvalidModerationAction = errors.New("invalid moderation action")
+
+var ErrNoPendingReports = errors.New("there are no pending reports for this content")
+
+var ErrInvalidReportTarget = errors.New("only posts and stories can be reported")
+
+const moderationNotificationType = "MODERATION"
+
+// Reports can be made on posts, kept here, and on stories, kept by
+// story-service. Both end up in the one queue of this service.
+const (
+	postTarget  = "post"
+	storyTarget = "story"
+)
+
+var targetPlurals = map[string]string{
+	postTarget:  "posts",
+	storyTarget: "stories",
+}
+
+type ModerationService struct {
+	repository     *repository.ModerationRepository
+	postRepository *repository.PostRepository
+}
+
+func NewModerationService(repository *repository.ModerationRepository, postRepository *repository.PostRepository) *ModerationService {
+	return &ModerationService{repository: repository, postRepository: postRepository}
+}
+
+// FindQueue groups reports by post or story, most reported first, so every post
+// and story shows up once in the queue no matter how many users reported it.
+// Both services sort their groups the same way, so the page is cut from the
+// merge of the groups each of them has up to the end of the page.
+func (service *ModerationService) FindQueue(status model.ReportStatus, page int, size int) ([]payload.ReportGroup, error) {
+	limit := (page + 1) * size
+
+	queue, err := service.findPostReportGroups(status, limit)
+
+	if err != nil {
+		return nil, err
+	}
+
+	storyGroups, err := fetchStoryReportGroups(status, limit)
+
+	if err != nil {
+		return nil, err
+	}
+
+	queue = append(queue, storyGroups...)
+
+	sort.SliceStable(queue, func(i, j int) bool {
+		if queue[i].Reports != queue[j].Reports {
+			return queue[i].Reports > queue[j].Reports
+		}
+
+		return queue[i].LastReportedAt.After(queue[j].LastReportedAt)
+	})
+
+	if page*size >= len(queue) {
+		return []payload.ReportGroup{}, nil
+	}
+
+	end := page*size + size
+
+	if end > len(queue) {
+		end = len(queue)
+	}
+
+	return queue[page*size : end], nil
+}
+
+func (service *ModerationService) findPostReportGroups(status model.ReportStatus, limit int) ([]payload.ReportGroup, error) {
+	groups, err := service.repository.FindReportGroups(status, limit)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var queue = []payload.ReportGroup{}
+
+	if len(groups) == 0 {
+		return queue, nil
+	}
+
+	var postIDs []uuid.UUID
+
+	for _, group := range groups {
+		postIDs = append(postIDs, group.PostID)
+	}
+
+	reasonCounts, err := service.repository.FindReasonCounts(postIDs, status)
+
+	if err != nil {
+		return nil, err
+	}
+
+	posts, err := service.postRepository.FindAllByIDsUnscoped(postIDs)
+
+	if err != nil {
+		return nil, err
+	}
+
+	postsByID := make(map[uuid.UUID]model.Post)
+
+	for _, post := range posts {
+		postsByID[post.ID] = post
+	}
+
+	reasonsByPostID := make(map[uuid.UUID]map[model.ReportReason]int64)
+
+	for _, reasonCount := range reasonCounts {
+		if reasonsByPostID[reasonCount.PostID] == nil {
+			reasonsByPostID[reasonCount.PostID] = make(map[model.ReportReason]int64)
+		}
+
+		reasonsByPostID[reasonCount.PostID][reasonCount.Reason] = reasonCount.Reports
+	}
+
+	for _, group := range groups {
+		reportGroup := payload.ReportGroup{
+			TargetID:        group.PostID,
+			TargetType:      postTarget,
+			Reports:         group.Reports,
+			Reasons:         reasonsByPostID[group.PostID],
+			FirstReportedAt: group.FirstReportedAt,
+			LastReportedAt:  group.LastReportedAt,
+		}
+
+		if post, found := postsByID[group.PostID]; found {
+			reportGroup.OwnerID = post.UserID
+			reportGroup.Preview = payload.ContentPreview{
+				Description: post.Description,
+				Content:     post.Content,
+				Removed:     post.DeletedAt.Valid,
+			}
+		}
+
+		queue = append(queue, reportGroup)
+	}
+
+	return queue, nil
+}
+
+func (service *ModerationService) FindReports(targetType string, targetID uuid.UUID) ([]payload.ReportView, error) {
+	switch targetType {
+	case postTarget:
+		return service.findPostReports(targetID)
+	case storyTarget:
+		return fetchStoryReports(targetID)
+	}
+
+	return nil, ErrInvalidReportTarget
+}
+
+func (service *ModerationService) findPostReports(postID uuid.UUID) ([]payload.ReportView, error) {
+	reports, err := service.repository.FindReportsByPostID(postID.String())
+
+	if err != nil {
+		return nil, err
+	}
+
+	var reportsView = []payload.ReportView{}
+
+	for _, report := range reports {
+		reportsView = append(reportsView, payload.ReportView{
+			ID:          report.ID,
+			UserID:      report.UserID,
+			Reason:      report.Reason,
+			Description: report.Description,
+			Status:      report.Status,
+			CreatedAt:   report.CreatedAt,
+			ResolvedAt:  report.ResolvedAt,
+		})
+	}
+
+	return reportsView, nil
+}
+
+// TakeAction resolves every pending report on the post or story with a single
+// decision, records it and lets the reporters and, unless the reports were
+// dismissed, the owner know the outcome. The decision is recorded and the
+// reports resolved before the owner is warned or suspended, so a failure can't
+// leave a sanction behind without a record of it. The admin token is forwarded
+// to user-service.
+func (service *ModerationService) TakeAction(targetType string, targetID uuid.UUID, adminID uuid.UUID, dto *payload.ModerationActionCreate, token string) (*model.ModerationAction, error) {
+	if !dto.Action.IsValid() {
+		return nil, ErrInvalidModerationAction
+	}
+
+	pending, err := service.findPendingReports(targetType, targetID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(pending.ReporterIDs) == 0 {
+		return nil, ErrNoPendingReports
+	}
+
+	action := &model.ModerationAction{
+		AdminID: adminID,
+		OwnerID: pending.OwnerID,
+		Action:  dto.Action,
+		Note:    dto.Note,
+	}
+
+	if targetType == storyTarget {
+		action.StoryID = &targetID
+	} else {
+		action.PostID = &targetID
+	}
+
+	action, err = service.repository.CreateAction(action)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if targetType == storyTarget {
+		err = resolveStoryReports(targetID, dto.Action)
+	} else {
+		err = service.resolvePostReports(targetID, dto.Action)
+	}
+
+	if err != nil {
+		_ = service.repository.DeleteAction(action)
+
+		return nil, err
+	}
+
+	switch dto.Action {
+	case model.WARN_USER:
+		err = callUserServiceAdmin(http.MethodPut, "/admin/warn/"+pending.OwnerID.String(), nil, token)
+	case model.SUSPEND_ACCOUNT:
+		err = callUserServiceAdmin(http.MethodPut, "/admin/suspend/"+pending.OwnerID.String(), nil, token)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("the reports were resolved, but the owner couldn't be sanctioned: %w", err)
+	}
+
+	notify(pending.ReporterIDs, reporterOutcomeMessage(dto.Action, targetType), token)
+
+	if dto.Action != model.DISMISS {
+		notify([]uuid.UUID{pending.OwnerID}, ownerOutcomeMessage(dto.Action, targetType), token)
+	}
+
+	return action, nil
+}
+
+func (service *ModerationService) findPendingReports(targetType string, targetID uuid.UUID) (*payload.PendingReports, error) {
+	switch targetType {
+	case postTarget:
+		post, err := service.postRepository.FindByIdUnscoped(targetID.String())
+
+		if err != nil {
+			return nil, err
+		}
+
+		reporterIDs, err := service.repository.FindPendingReporterIDs(targetID.String())
+
+		if err != nil {
+			return nil, err
+		}
+
+		return &payload.PendingReports{OwnerID: post.UserID, ReporterIDs: reporterIDs}, nil
+	case storyTarget:
+		return fetchStoryPendingReports(targetID)
+	}
+
+	return nil, ErrInvalidReportTarget
+}
+
+func (service *ModerationService) resolvePostReports(postID uuid.UUID, action model.ModerationActionType) error {
+	if action == model.REMOVE_CONTENT {
+		post, err := service.postRepository.FindByIdUnscoped(postID.String())
+
+		if err != nil {
+			return err
+		}
+
+		err = service.postRepository.Delete(post)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	status := model.ACTIONED
+
+	if action == model.DISMISS {
+		status = model.DISMISSED
+	}
+
+	return service.repository.ResolvePendingReports(postID.String(), status)
+}
+
+func reporterOutcomeMessage(action model.ModerationActionType, targetType string) string {
+	if action == model.DISMISS {
+		return fmt.Sprintf("We reviewed the %s you reported and found that it doesn't go against our community guidelines.", targetType)
+	}
+
+	return fmt.Sprintf("Thanks for your report. We reviewed the %s and took action against it.", targetType)
+}
+
+func ownerOutcomeMessage(action model.ModerationActionType, targetType string) string {
+	switch action {
+	case model.REMOVE_CONTENT:
+		return fmt.Sprintf("Your %s was removed because it goes against our community guidelines.", targetType)
+	case model.WARN_USER:
+		return fmt.Sprintf("You received a warning because one of your %s goes against our community guidelines.", targetPlurals[targetType])
+	}
+
+	return fmt.Sprintf("Your account was suspended because one of your %s goes against our community guidelines.", targetPlurals[targetType])
+}
+
+// notify is best effort: the moderation decision is already stored, so a failed
+// notification must not undo or fail it.
+func notify(userIDs []uuid.UUID, message string, token string) {
+	request := &payload.NotificationCreate{
+		UserIDs: userIDs,
+		Type:    moderationNotificationType,
+		Message: message,
+	}
+
+	_ = callUserServiceAdmin(http.MethodPost, "/admin/notifications", request, token)
+}
+
+func callUserServiceAdmin(method string, path string, body interface{}, token string) error {
+	var requestBody = &bytes.Buffer{}
+
+	if body != nil {
+		err := json.NewEncoder(requestBody).Encode(body)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	requestURL := fmt.Sprintf("http://%s:%s%s", os.Getenv("USER_SERVICE_DOMAIN"), os.Getenv("USER_SERVICE_PORT"), path)
+	req, err := http.NewRequest(method, requestURL, requestBody)
+
+	if err != nil {
+		return err
+	}
+
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Authorization", "Bearer "+token)
+
+	client := &http.Client{}
+	response, err := client.Do(req)
+
+	if err != nil {
+		return err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("user-service responded with %s", response.Status)
+	}
+
+	return nil
+}
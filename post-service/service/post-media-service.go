@@ -0,0 +1,162 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/KristijanPill/Nishtagram/post-service/model"
+	"github.com/KristijanPill/Nishtagram/post-service/payload"
+	"github.com/KristijanPill/Nishtagram/post-service/repository"
+	"github.com/google/uuid"
+)
+
+var ErrInvalidMediaOrder = errors.New("the order must list every media of the post exactly once")
+
+var ErrLastMedia = errors.New("a post must keep at least one media")
+
+type PostMediaService struct {
+	repository     *repository.PostMediaRepository
+	postRepository *repository.PostRepository
+}
+
+func NewPostMediaService(repository *repository.PostMediaRepository, postRepository *repository.PostRepository) *PostMediaService {
+	return &PostMediaService{repository: repository, postRepository: postRepository}
+}
+
+func (service *PostMediaService) Reorder(postID uuid.UUID, dto *payload.MediaOrder, loggedInUserID uuid.UUID) ([]payload.MediaView, error) {
+	post, err := service.findOwnPost(postID, loggedInUserID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(dto.MediaIDs) != len(post.Media) {
+		return nil, ErrInvalidMediaOrder
+	}
+
+	mediaByID := make(map[uuid.UUID]model.PostMedia)
+
+	for _, media := range post.Media {
+		mediaByID[media.ID] = media
+	}
+
+	var ordered []model.PostMedia
+
+	for _, mediaID := range dto.MediaIDs {
+		media, found := mediaByID[mediaID]
+
+		if !found {
+			return nil, ErrInvalidMediaOrder
+		}
+
+		delete(mediaByID, mediaID)
+		ordered = append(ordered, media)
+	}
+
+	return service.reorder(post, ordered)
+}
+
+func (service *PostMediaService) Remove(postID uuid.UUID, mediaID uuid.UUID, loggedInUserID uuid.UUID) ([]payload.MediaView, error) {
+	post, err := service.findOwnPost(postID, loggedInUserID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var remaining []model.PostMedia
+
+	for _, media := range post.Media {
+		if media.ID != mediaID {
+			remaining = append(remaining, media)
+		}
+	}
+
+	if len(remaining) == len(post.Media) {
+		return nil, ErrContentUnavailable
+	}
+
+	if len(remaining) == 0 {
+		return nil, ErrLastMedia
+	}
+
+	return service.reorder(post, remaining)
+}
+
+func (service *PostMediaService) Update(postID uuid.UUID, mediaID uuid.UUID, dto *payload.MediaUpdate, loggedInUserID uuid.UUID) (*payload.MediaView, error) {
+	if _, err := service.findOwnPost(postID, loggedInUserID); err != nil {
+		return nil, err
+	}
+
+	media, err := service.repository.FindByIDAndPostID(mediaID, postID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	media.AltText = dto.AltText
+	media.TaggedUsers = nil
+
+	for _, taggedUser := range dto.TaggedUsers {
+		media.TaggedUsers = append(media.TaggedUsers, taggedUser.String())
+	}
+
+	media, err = service.repository.Update(media)
+
+	if err != nil {
+		return nil, err
+	}
+
+	mediaView := toMediaViews([]model.PostMedia{*media})[0]
+
+	return &mediaView, nil
+}
+
+func (service *PostMediaService) reorder(post *model.Post, media []model.PostMedia) ([]payload.MediaView, error) {
+	err := service.repository.Reorder(post.ID, media)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for position := range media {
+		media[position].Position = position
+	}
+
+	return toMediaViews(media), nil
+}
+
+func (service *PostMediaService) findOwnPost(postID uuid.UUID, loggedInUserID uuid.UUID) (*model.Post, error) {
+	post, err := service.postRepository.FindById(postID.String())
+
+	if err != nil {
+		return nil, err
+	}
+
+	if post.UserID != loggedInUserID {
+		return nil, ErrNotPostOwner
+	}
+
+	return post, nil
+}
+
+func toMediaViews(media []model.PostMedia) []payload.MediaView {
+	var mediaViews = []payload.MediaView{}
+
+	for _, item := range media {
+		mediaViews = append(mediaViews, payload.MediaView{
+			ID:            item.ID,
+			Path:          item.Path,
+			ThumbnailPath: item.ThumbnailPath,
+			FeedPath:      item.FeedPath,
+			Blurhash:      item.Blurhash,
+			Type:          item.Type,
+			Width:         item.Width,
+			Height:        item.Height,
+			Duration:      item.Duration,
+			AltText:       item.AltText,
+			TaggedUsers:   item.TaggedUsers,
+			Position:      item.Position,
+		})
+	}
+
+	return mediaViews
+}
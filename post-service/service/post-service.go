@@ -1,11 +1,10 @@
 package service
 
 import (
-	"bytes"
-	"encoding/json"
-	"fmt"
-	"net/http"
+	"errors"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/KristijanPill/Nishtagram/post-service/helpers"
 	"github.com/KristijanPill/Nishtagram/post-service/model"
@@ -14,211 +13,542 @@ import (
 	"github.com/google/uuid"
 )
 
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+var ErrInvalidReportReason = errors.New("invalid report reason")
+
+const topCommentsCount = 3
+
+const searchMaxBatches = 5
+
 type PostService struct {
-	postRepository    *repository.PostRepository
-	reviewRepository  *repository.ReviewRepository
-	commentRepository *repository.CommentRepository
+	postRepository      *repository.PostRepository
+	reviewRepository    *repository.ReviewRepository
+	commentRepository   *repository.CommentRepository
+	hashtagRepository   *repository.HashtagRepository
+	savedPostRepository *repository.SavedPostRepository
 }
 
-func NewPostService(postRepository *repository.PostRepository, reviewRepository *repository.ReviewRepository, commentRepository *repository.CommentRepository) *PostService {
-	return &PostService{postRepository: postRepository, reviewRepository: reviewRepository, commentRepository: commentRepository}
+func NewPostService(postRepository *repository.PostRepository, reviewRepository *repository.ReviewRepository, commentRepository *repository.CommentRepository,
+	hashtagRepository *repository.HashtagRepository, savedPostRepository *repository.SavedPostRepository) *PostService {
+	return &PostService{
+		postRepository:      postRepository,
+		reviewRepository:    reviewRepository,
+		commentRepository:   commentRepository,
+		hashtagRepository:   hashtagRepository,
+		savedPostRepository: savedPostRepository,
+	}
 }
 
+// Create publishes the post right away unless it is a draft or scheduled, in
+// which case its hashtags are linked once it gets published.
 func (service *PostService) Create(post *model.Post) (*model.Post, error) {
+	post.Tags = collectHashtags(post.Description, post.Tags)
+
+	if post.Status == "" {
+		post.Status = model.PUBLISHED
+	}
+
+	if err := CheckSchedule(post.Status, post.ScheduledAt); err != nil {
+		return nil, err
+	}
+
 	return service.postRepository.Create(post)
 }
 
-func (service *PostService) FindByOtherUser(userID uuid.UUID, loggedInUserID uuid.UUID) ([]payload.PostView, error) {
+func (service *PostService) FindByID(postID uuid.UUID, loggedInUserID uuid.UUID, token string) (*payload.PostDetailView, error) {
+	post, err := checkPostAccess(service.postRepository, postID, loggedInUserID, token)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return service.toPostDetailView(post, loggedInUserID, token)
+}
+
+func (service *PostService) FindByShareSlug(slug string, loggedInUserID uuid.UUID, token string) (*payload.PostDetailView, error) {
+	post, err := service.postRepository.FindByShareSlug(slug)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkHidden(post, loggedInUserID); err != nil {
+		return nil, err
+	}
+
+	if err := checkAuthorAccess(post.UserID, loggedInUserID, token); err != nil {
+		return nil, err
+	}
+
+	return service.toPostDetailView(post, loggedInUserID, token)
+}
+
+// GetShareLink returns the stable share link of a post, generating the slug for
+// posts created before share links existed.
+func (service *PostService) GetShareLink(postID uuid.UUID, loggedInUserID uuid.UUID, token string) (*payload.ShareLink, error) {
+	post, err := checkPostAccess(service.postRepository, postID, loggedInUserID, token)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if post.ShareSlug == nil {
+		slug, err := model.NewShareSlug()
+
+		if err != nil {
+			return nil, err
+		}
+
+		post.ShareSlug = &slug
+
+		if _, err := service.postRepository.UpdateShareSlug(post); err != nil {
+			return nil, err
+		}
+	}
+
+	return &payload.ShareLink{Slug: *post.ShareSlug, Link: shareLink(*post.ShareSlug)}, nil
+}
+
+func (service *PostService) toPostDetailView(post *model.Post, loggedInUserID uuid.UUID, token string) (*payload.PostDetailView, error) {
+	var comments []model.Comment
+	var err error
+
+	if !post.CommentsDisabled {
+		comments, err = service.commentRepository.FindTopByPostID(post.ID.String(), topCommentsCount)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	userIDs := []uuid.UUID{post.UserID}
+	var taggedUserIDs []uuid.UUID
+
+	for _, taggedUser := range post.TaggedUsers {
+		if taggedUserID, err := uuid.Parse(taggedUser); err == nil {
+			taggedUserIDs = append(taggedUserIDs, taggedUserID)
+		}
+	}
+
+	userIDs = append(userIDs, taggedUserIDs...)
+
+	for _, comment := range comments {
+		userIDs = append(userIDs, comment.UserID)
+	}
+
+	details, err := fetchUsersDetails(userIDs, token)
+
+	if err != nil {
+		return nil, err
+	}
+
+	author := details[post.UserID]
+
+	detailView := &payload.PostDetailView{
+		PostView:    service.toPostView(post, &author, loggedInUserID),
+		CreatedAt:   post.CreatedAt,
+		Tags:        post.Tags,
+		TaggedUsers: []payload.UserView{},
+		TopComments: []payload.CommentView{},
+	}
+
+	for _, taggedUserID := range taggedUserIDs {
+		if taggedUser, found := details[taggedUserID]; found {
+			detailView.TaggedUsers = append(detailView.TaggedUsers, payload.UserView{ID: taggedUser.ID, Username: taggedUser.Username})
+		}
+	}
+
+	for _, comment := range comments {
+		detailView.TopComments = append(detailView.TopComments, payload.CommentView{
+			ID:                 comment.ID,
+			UserID:             comment.UserID,
+			Username:           details[comment.UserID].Username,
+			ProfilePicture:     details[comment.UserID].ProfilePicture,
+			Content:            comment.Content,
+			RepliedToCommentID: comment.RepliedToCommentID,
+		})
+	}
+
+	if loggedInUserID != uuid.Nil {
+		detailView.Saved, err = service.savedPostRepository.ExistsByPostIDAndUserID(post.ID.String(), loggedInUserID.String())
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if post.ShareSlug != nil {
+		detailView.ShareLink = shareLink(*post.ShareSlug)
+	}
+
+	return detailView, nil
+}
+
+func shareLink(slug string) string {
+	return os.Getenv("SHARE_BASE_URL") + "/p/" + slug
+}
+
+func (service *PostService) FindByHashtag(name string, loggedInUserID uuid.UUID, token string, page int, size int) (*payload.HashtagPage, error) {
+	hashtag, err := service.hashtagRepository.FindByName(normaliseHashtag(name))
+
+	if err != nil {
+		return nil, err
+	}
+
+	posts, err := service.hashtagRepository.FindPostsByHashtagID(hashtag.ID, page, size)
+
+	if err != nil {
+		return nil, err
+	}
+
+	postsView, err := service.toVisiblePostViews(posts, loggedInUserID, token)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &payload.HashtagPage{
+		Name:       hashtag.Name,
+		UsageCount: hashtag.UsageCount,
+		Page:       page,
+		Size:       size,
+		Posts:      postsView,
+	}, nil
+}
+
+func (service *PostService) toPostView(post *model.Post, userDetails *payload.UserDetails, loggedInUserID uuid.UUID) payload.PostView {
+	return newPostView(service.reviewRepository, service.commentRepository, post, userDetails, loggedInUserID)
+}
+
+func newPostView(reviewRepository *repository.ReviewRepository, commentRepository *repository.CommentRepository,
+	post *model.Post, userDetails *payload.UserDetails, loggedInUserID uuid.UUID) payload.PostView {
+	postView := payload.PostView{
+		ID:               post.ID,
+		UserID:           post.UserID,
+		Username:         userDetails.Username,
+		ProfilePicture:   userDetails.ProfilePicture,
+		Content:          post.Content,
+		Media:            toMediaViews(post.Media),
+		NumberOfComments: commentRepository.FindCountByPostID(post.ID.String()),
+		Status:           reviewRepository.FindStatusByPostIDAndUserID(post.ID.String(), loggedInUserID.String()),
+		Location:         post.Location,
+		Description:      post.Description,
+		ArchivedAt:       post.ArchivedAt,
+		CommentsDisabled: post.CommentsDisabled,
+	}
+
+	if post.HideLikeCounts && post.UserID != loggedInUserID {
+		postView.LikeCountsHidden = true
+
+		return postView
+	}
+
+	postView.NumberOfLikes = reviewRepository.FindCountByPostIDAndStatus(post.ID.String(), model.LIKE)
+	postView.NumberOfDislikes = reviewRepository.FindCountByPostIDAndStatus(post.ID.String(), model.DISLIKE)
+
+	return postView
+}
+
+func (service *PostService) FindByOtherUser(userID uuid.UUID, loggedInUserID uuid.UUID, token string) ([]payload.PostView, error) {
+	if err := checkAuthorAccess(userID, loggedInUserID, token); err != nil {
+		return nil, err
+	}
+
 	posts, err := service.postRepository.FindByUserID(userID.String())
 
 	if err != nil {
 		return nil, err
 	}
 
+	details, err := fetchUsersDetails([]uuid.UUID{userID}, token)
+
+	if err != nil {
+		return nil, err
+	}
+
+	userDetails := details[userID]
+
 	var postsView []payload.PostView
 
-	for _, post := range posts {
-		postView := &payload.PostView{
-			ID:               post.ID,
-			UserID:           post.UserID,
-			Username:         service.getUserDetails(post.UserID).Username,
-			Content:          post.Content,
-			NumberOfLikes:    service.reviewRepository.FindCountByPostIDAndStatus(post.ID.String(), model.LIKE),
-			NumberOfDislikes: service.reviewRepository.FindCountByPostIDAndStatus(post.ID.String(), model.DISLIKE),
-			NumberOfComments: service.commentRepository.FindCountByPostID(post.ID.String()),
-			Status:           service.reviewRepository.FindStatusByPostIDAndUserID(post.ID.String(), loggedInUserID.String()),
-			Location:         post.Location,
-			Description:      post.Description,
-		}
-		postsView = append(postsView, *postView)
+	for i := range posts {
+		postsView = append(postsView, service.toPostView(&posts[i], &userDetails, loggedInUserID))
 	}
 
 	return postsView, nil
 }
 
-func (service *PostService) getUserDetails(userID uuid.UUID) *payload.UserDetails {
-	var userIDs = &payload.UserIDs{}
-	userIDs.IDs = append(userIDs.IDs, payload.UserID{ID: userID})
+func (service *PostService) UpdateSettings(postID uuid.UUID, dto *payload.PostSettings, loggedInUserID uuid.UUID) error {
+	post, err := service.postRepository.FindById(postID.String())
 
-	requestJSON, _ := json.Marshal(userIDs)
+	if err != nil {
+		return err
+	}
+
+	if post.UserID != loggedInUserID {
+		return ErrNotPostOwner
+	}
 
-	requestURL := fmt.Sprintf("http://%s:%s/users-details", os.Getenv("USER_SERVICE_DOMAIN"), os.Getenv("USER_SERVICE_PORT"))
-	response, err := http.Post(requestURL, "application/json", bytes.NewBuffer(requestJSON))
+	post.CommentsDisabled = dto.CommentsDisabled
+	post.HideLikeCounts = dto.HideLikeCounts
+
+	_, err = service.postRepository.UpdateSettings(post)
+
+	return err
+}
+
+// Archive takes the post off the owner's profile, keeping its likes and
+// comments, until it is unarchived.
+func (service *PostService) Archive(postID uuid.UUID, loggedInUserID uuid.UUID) error {
+	now := time.Now()
+
+	return service.setArchivedAt(postID, loggedInUserID, &now)
+}
+
+func (service *PostService) Unarchive(postID uuid.UUID, loggedInUserID uuid.UUID) error {
+	return service.setArchivedAt(postID, loggedInUserID, nil)
+}
+
+func (service *PostService) setArchivedAt(postID uuid.UUID, loggedInUserID uuid.UUID, archivedAt *time.Time) error {
+	post, err := service.postRepository.FindById(postID.String())
 
 	if err != nil {
-		return nil
+		return err
+	}
+
+	if post.UserID != loggedInUserID {
+		return ErrNotPostOwner
+	}
+
+	if post.Status != model.PUBLISHED {
+		return ErrNotPublished
 	}
 
-	var details = &payload.UsersDetails{}
+	post.ArchivedAt = archivedAt
 
-	helpers.FromJSON(details, response.Body)
+	_, err = service.postRepository.UpdateArchivedAt(post)
 
-	return &details.UsersDetails[0]
+	return err
 }
 
-func (service *PostService) GetReviewsByUserIDAndStatus(userID uuid.UUID, status int) ([]payload.PostView, error) {
+func (service *PostService) FindArchived(loggedInUserID uuid.UUID, page int, size int, token string) ([]payload.PostView, error) {
+	posts, err := service.postRepository.FindArchivedByUserID(loggedInUserID.String(), page, size)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return service.toVisiblePostViews(posts, loggedInUserID, token)
+}
+
+// GetReviewsByUserIDAndStatus lists the posts the user liked or disliked,
+// leaving out the ones the user can no longer see.
+func (service *PostService) GetReviewsByUserIDAndStatus(userID uuid.UUID, status int, token string) ([]payload.PostView, error) {
 	reviews, err := service.reviewRepository.GetReviewsByUserIDAndStatus(userID, status)
 
 	if err != nil {
 		return nil, err
 	}
 
-	var postsView []payload.PostView
+	var posts []model.Post
 
 	for _, review := range reviews {
-		postView := &payload.PostView{
-			ID:               review.Post.ID,
-			UserID:           review.Post.UserID,
-			Username:         service.getUserDetails(review.Post.UserID).Username,
-			Content:          review.Post.Content,
-			NumberOfLikes:    service.reviewRepository.FindCountByPostIDAndStatus(review.Post.ID.String(), model.LIKE),
-			NumberOfDislikes: service.reviewRepository.FindCountByPostIDAndStatus(review.Post.ID.String(), model.DISLIKE),
-			NumberOfComments: service.commentRepository.FindCountByPostID(review.Post.ID.String()),
-			Status:           service.reviewRepository.FindStatusByPostIDAndUserID(review.Post.ID.String(), userID.String()),
-			Location:         review.Post.Location,
-			Description:      review.Post.Description,
-		}
-		postsView = append(postsView, *postView)
+		posts = append(posts, review.Post)
 	}
 
-	return postsView, nil
-
+	return service.toVisiblePostViews(posts, userID, token)
 }
 
 func (service *PostService) CreateReport(dto *payload.ReportCreate) (*model.Report, error) {
+	if !dto.Reason.IsValid() {
+		return nil, ErrInvalidReportReason
+	}
 
 	report := &model.Report{
 		PostID:      dto.PostID,
 		UserID:      dto.UserID,
+		Reason:      dto.Reason,
 		Description: dto.Description,
 	}
 
 	return service.postRepository.CreateReport(report)
 }
 
-func (service *PostService) SearchPostsByLocation(query string, loggedInUserID uuid.UUID) ([]payload.PostView, error) {
+func (service *PostService) SearchPostsByLocation(query string, loggedInUserID uuid.UUID, token string) ([]payload.PostView, error) {
 	posts, err := service.postRepository.FindLikeLocation(query)
 
 	if err != nil {
 		return nil, err
 	}
 
-	var userIDs = &payload.UserIDs{}
-
-	for _, post := range posts {
-		userIDs.IDs = append(userIDs.IDs, payload.UserID{ID: post.UserID})
-	}
+	return service.toVisiblePostViews(posts, loggedInUserID, token)
+}
 
-	requestJSON, err := json.Marshal(userIDs)
+func (service *PostService) SearchPostsByTags(query string, loggedInUserID uuid.UUID, token string) ([]payload.PostView, error) {
+	posts, err := service.postRepository.FindLikeTags(strings.Join(collectHashtags("", strings.Fields(query)), " "))
 
 	if err != nil {
 		return nil, err
 	}
 
-	requestURL := fmt.Sprintf("http://%s:%s/users-details", os.Getenv("USER_SERVICE_DOMAIN"), os.Getenv("USER_SERVICE_PORT"))
-	response, err := http.Post(requestURL, "application/json", bytes.NewBuffer(requestJSON))
+	return service.toVisiblePostViews(posts, loggedInUserID, token)
+}
 
-	if err != nil {
-		return nil, err
+// SearchPosts ranks the published posts matching the query. Posts the viewer
+// can't see are filtered out only after asking user-service about their
+// authors, so ranked posts are fetched in batches until the page is full.
+func (service *PostService) SearchPosts(query *payload.PostSearchQuery, loggedInUserID uuid.UUID, token string) (*payload.PostSearchResult, error) {
+	filter := &repository.PostSearchFilter{
+		Query:      strings.TrimSpace(query.Query),
+		From:       query.From,
+		To:         query.To,
+		MediaType:  query.MediaType,
+		LocationID: query.LocationID,
+		Limit:      query.Limit * 2,
 	}
 
-	var details = &payload.UsersDetails{}
+	if query.Cursor != "" {
+		filter.After = &repository.PostSearchHit{}
 
-	helpers.FromJSON(details, response.Body)
+		if err := helpers.DecodeCursor(query.Cursor, filter.After); err != nil {
+			return nil, ErrInvalidCursor
+		}
+	}
 
-	var postsView []payload.PostView
+	if filter.Query != "" {
+		authorIDs, err := searchUserIDs(filter.Query)
+
+		if err != nil {
+			return nil, err
+		}
+
+		filter.AuthorIDs = authorIDs
+	}
+
+	var picked []model.Post
+	detailsByID := make(map[uuid.UUID]payload.UserDetails)
+	exhausted := false
+
+	for batch := 0; batch < searchMaxBatches && len(picked) < query.Limit && !exhausted; batch++ {
+		hits, err := service.postRepository.Search(filter)
+
+		if err != nil {
+			return nil, err
+		}
+
+		exhausted = len(hits) < filter.Limit
+
+		var ids []uuid.UUID
+
+		for _, hit := range hits {
+			ids = append(ids, hit.ID)
+		}
+
+		found, err := service.postRepository.FindAllByIDs(ids)
+
+		if err != nil {
+			return nil, err
+		}
+
+		var authorIDs []uuid.UUID
+		postsByID := make(map[uuid.UUID]model.Post)
+
+		for _, post := range found {
+			authorIDs = append(authorIDs, post.UserID)
+			postsByID[post.ID] = post
+		}
+
+		details, err := fetchUsersDetails(authorIDs, token)
+
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range hits {
+			filter.After = &hits[i]
+			post, found := postsByID[hits[i].ID]
 
-	for i, post := range posts {
-		if !details.UsersDetails[i].Private {
-			postView := &payload.PostView{
-				ID:               post.ID,
-				UserID:           post.UserID,
-				Username:         service.getUserDetails(post.UserID).Username,
-				ProfilePicture:   service.getUserDetails(post.UserID).ProfilePicture,
-				Content:          post.Content,
-				NumberOfLikes:    service.reviewRepository.FindCountByPostIDAndStatus(post.ID.String(), model.LIKE),
-				NumberOfDislikes: service.reviewRepository.FindCountByPostIDAndStatus(post.ID.String(), model.DISLIKE),
-				NumberOfComments: service.commentRepository.FindCountByPostID(post.ID.String()),
-				Status:           service.reviewRepository.FindStatusByPostIDAndUserID(post.ID.String(), loggedInUserID.String()),
-				Location:         post.Location,
-				Description:      post.Description,
+			if !found {
+				continue
+			}
+
+			userDetails, found := details[post.UserID]
+
+			if !found || !canViewAuthor(userDetails, loggedInUserID) || checkHidden(&post, loggedInUserID) != nil {
+				continue
+			}
+
+			detailsByID[userDetails.ID] = userDetails
+			picked = append(picked, post)
+
+			if len(picked) == query.Limit {
+				exhausted = exhausted && i == len(hits)-1
+				break
 			}
-			postsView = append(postsView, *postView)
 		}
 	}
 
-	return postsView, nil
+	result := &payload.PostSearchResult{Posts: []payload.PostView{}}
+
+	for i := range picked {
+		userDetails := detailsByID[picked[i].UserID]
+		result.Posts = append(result.Posts, newPostView(service.reviewRepository, service.commentRepository, &picked[i], &userDetails, loggedInUserID))
+	}
+
+	if !exhausted && filter.After != nil {
+		var err error
+		result.NextCursor, err = helpers.EncodeCursor(filter.After)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
 }
 
-func (service *PostService) SearchPostsByTags(query string, loggedInUserID uuid.UUID) ([]payload.PostView, error) {
-	posts, err := service.postRepository.FindLikeTags(query)
+// FindShared returns the posts shared into stories that the viewer may see.
+// Deleted posts and posts the viewer can't see are left out.
+func (service *PostService) FindShared(dto *payload.SharedPostsRequest, loggedInUserID uuid.UUID, token string) ([]payload.PostView, error) {
+	posts, err := service.postRepository.FindAllByIDs(dto.IDs)
 
 	if err != nil {
 		return nil, err
 	}
 
-	var userIDs = &payload.UserIDs{}
+	return service.toVisiblePostViews(posts, loggedInUserID, token)
+}
 
-	for _, post := range posts {
-		userIDs.IDs = append(userIDs.IDs, payload.UserID{ID: post.UserID})
-	}
+func (service *PostService) toVisiblePostViews(posts []model.Post, loggedInUserID uuid.UUID, token string) ([]payload.PostView, error) {
+	return visiblePostViews(service.reviewRepository, service.commentRepository, posts, loggedInUserID, token)
+}
 
-	requestJSON, err := json.Marshal(userIDs)
+// visiblePostViews resolves the authors of the posts in bulk and keeps only
+// the posts the logged in user is allowed to see.
+func visiblePostViews(reviewRepository *repository.ReviewRepository, commentRepository *repository.CommentRepository,
+	posts []model.Post, loggedInUserID uuid.UUID, token string) ([]payload.PostView, error) {
+	var userIDs []uuid.UUID
 
-	if err != nil {
-		return nil, err
+	for _, post := range posts {
+		userIDs = append(userIDs, post.UserID)
 	}
 
-	requestURL := fmt.Sprintf("http://%s:%s/users-details", os.Getenv("USER_SERVICE_DOMAIN"), os.Getenv("USER_SERVICE_PORT"))
-	response, err := http.Post(requestURL, "application/json", bytes.NewBuffer(requestJSON))
+	details, err := fetchUsersDetails(userIDs, token)
 
 	if err != nil {
 		return nil, err
 	}
 
-	var details = &payload.UsersDetails{}
-
-	helpers.FromJSON(details, response.Body)
+	var postsView = []payload.PostView{}
 
-	var postsView []payload.PostView
+	for i := range posts {
+		userDetails, found := details[posts[i].UserID]
 
-	for i, post := range posts {
-		if !details.UsersDetails[i].Private {
-			postView := &payload.PostView{
-				ID:               post.ID,
-				UserID:           post.UserID,
-				Username:         service.getUserDetails(post.UserID).Username,
-				ProfilePicture:   service.getUserDetails(post.UserID).ProfilePicture,
-				Content:          post.Content,
-				NumberOfLikes:    service.reviewRepository.FindCountByPostIDAndStatus(post.ID.String(), model.LIKE),
-				NumberOfDislikes: service.reviewRepository.FindCountByPostIDAndStatus(post.ID.String(), model.DISLIKE),
-				NumberOfComments: service.commentRepository.FindCountByPostID(post.ID.String()),
-				Status:           service.reviewRepository.FindStatusByPostIDAndUserID(post.ID.String(), loggedInUserID.String()),
-				Location:         post.Location,
-				Description:      post.Description,
-			}
-			postsView = append(postsView, *postView)
+		if !found || !canViewAuthor(userDetails, loggedInUserID) || checkHidden(&posts[i], loggedInUserID) != nil {
+			continue
 		}
+
+		postsView = append(postsView, newPostView(reviewRepository, commentRepository, &posts[i], &userDetails, loggedInUserID))
 	}
 
 	return postsView, nil
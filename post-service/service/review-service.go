@@ -4,17 +4,23 @@ import (
 	"github.com/KristijanPill/Nishtagram/post-service/model"
 	"github.com/KristijanPill/Nishtagram/post-service/payload"
 	"github.com/KristijanPill/Nishtagram/post-service/repository"
+	"github.com/google/uuid"
 )
 
 type ReviewService struct {
-	repository *repository.ReviewRepository
+	repository     *repository.ReviewRepository
+	postRepository *repository.PostRepository
 }
 
-func NewReviewService(repository *repository.ReviewRepository) *ReviewService {
-	return &ReviewService{repository: repository}
+func NewReviewService(repository *repository.ReviewRepository, postRepository *repository.PostRepository) *ReviewService {
+	return &ReviewService{repository: repository, postRepository: postRepository}
 }
 
-func (service *ReviewService) ReviewPost(dto *payload.ReviewCreate) (*model.Review, error) {
+func (service *ReviewService) ReviewPost(dto *payload.ReviewCreate, token string) (*model.Review, error) {
+	if _, err := checkPostAccess(service.postRepository, dto.PostID, dto.UserID, token); err != nil {
+		return nil, err
+	}
+
 	exists, err := service.repository.ExistsByPostIDAndUserID(dto.PostID.String(), dto.UserID.String())
 
 	if err != nil {
@@ -38,3 +44,59 @@ func (service *ReviewService) ReviewPost(dto *payload.ReviewCreate) (*model.Revi
 		return service.repository.Create(review)
 	}
 }
+
+// FindReviewers lists who liked or disliked a post. Dislikes are only visible to
+// the owner of the post, and users blocked in either direction are left out.
+// When the owner hides the like counts, only the owner gets the total.
+func (service *ReviewService) FindReviewers(postID uuid.UUID, status model.ReviewStatus, loggedInUserID uuid.UUID, token string, page int, size int) (*payload.ReviewersPage, error) {
+	post, err := checkPostAccess(service.postRepository, postID, loggedInUserID, token)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if status == model.DISLIKE && post.UserID != loggedInUserID {
+		return nil, ErrNotPostOwner
+	}
+
+	userIDs, err := service.repository.FindUserIDsByPostIDAndStatus(postID.String(), status, page, size)
+
+	if err != nil {
+		return nil, err
+	}
+
+	details, err := fetchUsersDetails(userIDs, token)
+
+	if err != nil {
+		return nil, err
+	}
+
+	reviewersPage := &payload.ReviewersPage{
+		Page:  page,
+		Size:  size,
+		Users: []payload.ReviewerView{},
+	}
+
+	if post.HideLikeCounts && post.UserID != loggedInUserID {
+		reviewersPage.TotalHidden = true
+	} else {
+		reviewersPage.Total = service.repository.FindCountByPostIDAndStatus(postID.String(), status)
+	}
+
+	for _, userID := range userIDs {
+		userDetails, found := details[userID]
+
+		if !found || userDetails.Blocked {
+			continue
+		}
+
+		reviewersPage.Users = append(reviewersPage.Users, payload.ReviewerView{
+			ID:             userDetails.ID,
+			Username:       userDetails.Username,
+			ProfilePicture: userDetails.ProfilePicture,
+			Followed:       userDetails.Followed,
+		})
+	}
+
+	return reviewersPage, nil
+}
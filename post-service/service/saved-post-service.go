@@ -1,13 +1,6 @@
 package service
 
 import (
-	"bytes"
-	"encoding/json"
-	"fmt"
-	"net/http"
-	"os"
-
-	"github.com/KristijanPill/Nishtagram/post-service/helpers"
 	"github.com/KristijanPill/Nishtagram/post-service/model"
 	"github.com/KristijanPill/Nishtagram/post-service/payload"
 	"github.com/KristijanPill/Nishtagram/post-service/repository"
@@ -15,96 +8,123 @@ import (
 )
 
 type SavedPostService struct {
-	savedPostRepository *repository.SavedPostRepository
-	reviewRepository    *repository.ReviewRepository
-	commentRepository   *repository.CommentRepository
+	savedPostRepository  *repository.SavedPostRepository
+	collectionRepository *repository.CollectionRepository
+	postRepository       *repository.PostRepository
+	reviewRepository     *repository.ReviewRepository
+	commentRepository    *repository.CommentRepository
 }
 
-func NewSavedPostService(savedPostRepository *repository.SavedPostRepository, reviewRepository *repository.ReviewRepository, commentRepository *repository.CommentRepository) *SavedPostService {
+func NewSavedPostService(savedPostRepository *repository.SavedPostRepository, collectionRepository *repository.CollectionRepository,
+	postRepository *repository.PostRepository, reviewRepository *repository.ReviewRepository, commentRepository *repository.CommentRepository) *SavedPostService {
 	return &SavedPostService{
-		savedPostRepository: savedPostRepository,
-		reviewRepository:    reviewRepository,
-		commentRepository:   commentRepository,
+		savedPostRepository:  savedPostRepository,
+		collectionRepository: collectionRepository,
+		postRepository:       postRepository,
+		reviewRepository:     reviewRepository,
+		commentRepository:    commentRepository,
 	}
 }
 
-func (service *SavedPostService) SavePost(dto *payload.SavedPostCreate) (*model.SavedPost, error) {
-	exists, err := service.savedPostRepository.ExistsByPostIDAndUserID(dto.PostID.String(), dto.UserID.String())
+// SavePost saves the post and adds it to the given collections. Saving a post
+// that is already saved only adds it to the collections it is not in yet.
+func (service *SavedPostService) SavePost(dto *payload.SavedPostCreate, token string) (*model.SavedPost, error) {
+	if _, err := checkPostAccess(service.postRepository, dto.PostID, dto.UserID, token); err != nil {
+		return nil, err
+	}
+
+	var collections []*model.Collection
+
+	for _, collectionID := range dto.CollectionIDs {
+		collection, err := service.collectionRepository.FindByIDAndUserID(collectionID.String(), dto.UserID.String())
+
+		if err != nil {
+			return nil, err
+		}
+
+		collections = append(collections, collection)
+	}
+
+	savedPost, err := service.savedPostRepository.Create(&model.SavedPost{
+		UserID: dto.UserID,
+		PostID: dto.PostID,
+	})
 
 	if err != nil {
 		return nil, err
 	}
 
-	savedPost := &model.SavedPost{
-		UserID:         dto.UserID,
-		PostID:         dto.PostID,
-		CollectionName: dto.CollectionName,
+	for _, collection := range collections {
+		if err := service.collectionRepository.AddPost(collection.ID, dto.PostID); err != nil {
+			return nil, err
+		}
 	}
 
-	if exists {
-		return service.savedPostRepository.Update(savedPost)
-	} else {
-		return service.savedPostRepository.Create(savedPost)
-	}
+	return savedPost, nil
 }
 
-func (service *SavedPostService) RemoveSavedPost(savedPost *model.SavedPost) error {
-	return service.savedPostRepository.Delete(savedPost)
+func (service *SavedPostService) RemoveSavedPost(postID uuid.UUID, loggedInUserID uuid.UUID) error {
+	return service.savedPostRepository.Delete(&model.SavedPost{
+		UserID: loggedInUserID,
+		PostID: postID,
+	})
 }
 
 func (service *SavedPostService) GetAllCollectionNames(loggedInUserID uuid.UUID) ([]string, error) {
-	return service.savedPostRepository.FindAllCollectionNames(loggedInUserID.String())
-}
-
-func (service *SavedPostService) GetAllByLoggedInUser(loggedInUserID uuid.UUID) ([]payload.SavedPostView, error) {
-	savedPosts, err := service.savedPostRepository.FindAllByUserID(loggedInUserID.String())
+	collections, err := service.collectionRepository.FindAllByUserID(loggedInUserID.String())
 
 	if err != nil {
 		return nil, err
 	}
 
-	var savedPostsView []payload.SavedPostView
-
-	for _, post := range savedPosts {
-		postView := &payload.PostView{
-			ID:               post.PostID,
-			UserID:           post.UserID,
-			Username:         service.getUserDetails(post.UserID).Username,
-			Content:          post.Post.Content,
-			NumberOfLikes:    service.reviewRepository.FindCountByPostIDAndStatus(post.PostID.String(), model.LIKE),
-			NumberOfDislikes: service.reviewRepository.FindCountByPostIDAndStatus(post.PostID.String(), model.DISLIKE),
-			NumberOfComments: service.commentRepository.FindCountByPostID(post.PostID.String()),
-			Status:           service.reviewRepository.FindStatusByPostIDAndUserID(post.PostID.String(), post.UserID.String()),
-			Location:         post.Post.Location,
-			Description:      post.Post.Description,
-		}
-		savedPostView := &payload.SavedPostView{
-			PostView:       *postView,
-			CollectionName: post.CollectionName,
-		}
+	var names = []string{}
 
-		savedPostsView = append(savedPostsView, *savedPostView)
+	for _, collection := range collections {
+		names = append(names, collection.Name)
 	}
 
-	return savedPostsView, nil
+	return names, nil
 }
 
-func (service *SavedPostService) getUserDetails(userID uuid.UUID) *payload.UserDetails {
-	var userIDs = &payload.UserIDs{}
-	userIDs.IDs = append(userIDs.IDs, payload.UserID{ID: userID})
+func (service *SavedPostService) GetAllByLoggedInUser(loggedInUserID uuid.UUID, page int, size int, token string) ([]payload.SavedPostView, error) {
+	savedPosts, err := service.savedPostRepository.FindAllByUserID(loggedInUserID.String(), page, size)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var posts []model.Post
 
-	requestJSON, _ := json.Marshal(userIDs)
+	for _, savedPost := range savedPosts {
+		posts = append(posts, savedPost.Post)
+	}
 
-	requestURL := fmt.Sprintf("http://%s:%s/users-details", os.Getenv("USER_SERVICE_DOMAIN"), os.Getenv("USER_SERVICE_PORT"))
-	response, err := http.Post(requestURL, "application/json", bytes.NewBuffer(requestJSON))
+	postsView, err := visiblePostViews(service.reviewRepository, service.commentRepository, posts, loggedInUserID, token)
 
 	if err != nil {
-		return nil
+		return nil, err
 	}
 
-	var details = &payload.UsersDetails{}
+	postViewsByID := make(map[uuid.UUID]payload.PostView)
 
-	helpers.FromJSON(details, response.Body)
+	for _, postView := range postsView {
+		postViewsByID[postView.ID] = postView
+	}
+
+	var savedPostsView = []payload.SavedPostView{}
+
+	for _, savedPost := range savedPosts {
+		postView, found := postViewsByID[savedPost.PostID]
+
+		if !found {
+			continue
+		}
+
+		savedPostsView = append(savedPostsView, payload.SavedPostView{
+			PostView: postView,
+			SavedAt:  savedPost.CreatedAt,
+		})
+	}
 
-	return &details.UsersDetails[0]
+	return savedPostsView, nil
 }
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/KristijanPill/Nishtagram/post-service/helpers"
+	"github.com/KristijanPill/Nishtagram/post-service/model"
+	"github.com/KristijanPill/Nishtagram/post-service/payload"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// The reports on stories are kept by story-service, which hands them over
+// through its internal endpoints.
+
+func fetchStoryReportGroups(status model.ReportStatus, limit int) ([]payload.ReportGroup, error) {
+	requestURL := fmt.Sprintf("http://%s:%s/internal/reports?status=%s&limit=%d", os.Getenv("STORY_SERVICE_DOMAIN"), os.Getenv("STORY_SERVICE_PORT"), url.QueryEscape(string(status)), limit)
+	response, err := http.Get(requestURL)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("story-service responded with %s", response.Status)
+	}
+
+	var groups []payload.ReportGroup
+	err = helpers.FromJSON(&groups, response.Body)
+
+	return groups, err
+}
+
+func fetchStoryReports(storyID uuid.UUID) ([]payload.ReportView, error) {
+	requestURL := fmt.Sprintf("http://%s:%s/internal/reports/%s", os.Getenv("STORY_SERVICE_DOMAIN"), os.Getenv("STORY_SERVICE_PORT"), storyID.String())
+	response, err := http.Get(requestURL)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("story-service responded with %s", response.Status)
+	}
+
+	var reports = []payload.ReportView{}
+	err = helpers.FromJSON(&reports, response.Body)
+
+	return reports, err
+}
+
+func fetchStoryPendingReports(storyID uuid.UUID) (*payload.PendingReports, error) {
+	requestURL := fmt.Sprintf("http://%s:%s/internal/reports/%s/pending", os.Getenv("STORY_SERVICE_DOMAIN"), os.Getenv("STORY_SERVICE_PORT"), storyID.String())
+	response, err := http.Get(requestURL)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNotFound {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("story-service responded with %s", response.Status)
+	}
+
+	var pending = &payload.PendingReports{}
+	err = helpers.FromJSON(pending, response.Body)
+
+	return pending, err
+}
+
+// resolveStoryReports has story-service close the pending reports on the story
+// and remove it if that was the decision.
+func resolveStoryReports(storyID uuid.UUID, action model.ModerationActionType) error {
+	requestJSON, err := json.Marshal(&payload.ReportResolve{Action: action})
+
+	if err != nil {
+		return err
+	}
+
+	requestURL := fmt.Sprintf("http://%s:%s/internal/reports/%s/resolve", os.Getenv("STORY_SERVICE_DOMAIN"), os.Getenv("STORY_SERVICE_PORT"), storyID.String())
+	response, err := http.Post(requestURL, "application/json", bytes.NewBuffer(requestJSON))
+
+	if err != nil {
+		return err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("story-service responded with %s", response.Status)
+	}
+
+	return nil
+}
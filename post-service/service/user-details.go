@@ -0,0 +1,132 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/KristijanPill/Nishtagram/post-service/helpers"
+	"github.com/KristijanPill/Nishtagram/post-service/payload"
+	"github.com/google/uuid"
+)
+
+// fetchUsersDetails resolves all given users with a single call to user-service
+// and keys the result by user ID, so callers never depend on response ordering.
+// The viewer's token is forwarded so follow and block status are filled in.
+func fetchUsersDetails(userIDs []uuid.UUID, token string) (map[uuid.UUID]payload.UserDetails, error) {
+	detailsByID := make(map[uuid.UUID]payload.UserDetails)
+
+	if len(userIDs) == 0 {
+		return detailsByID, nil
+	}
+
+	var request = &payload.UserIDs{}
+	seen := make(map[uuid.UUID]bool)
+
+	for _, userID := range userIDs {
+		if seen[userID] {
+			continue
+		}
+
+		seen[userID] = true
+		request.IDs = append(request.IDs, payload.UserID{ID: userID})
+	}
+
+	requestJSON, err := json.Marshal(request)
+
+	if err != nil {
+		return nil, err
+	}
+
+	requestURL := fmt.Sprintf("http://%s:%s/users-details", os.Getenv("USER_SERVICE_DOMAIN"), os.Getenv("USER_SERVICE_PORT"))
+	client := &http.Client{}
+	req, err := http.NewRequest(http.MethodPost, requestURL, bytes.NewBuffer(requestJSON))
+
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Content-Type", "application/json")
+
+	if token != "" {
+		req.Header.Add("Authorization", "Bearer "+token)
+	}
+
+	response, err := client.Do(req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+
+	var details = &payload.UsersDetails{}
+
+	err = helpers.FromJSON(details, response.Body)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, userDetails := range details.UsersDetails {
+		if userDetails.ID != uuid.Nil {
+			detailsByID[userDetails.ID] = userDetails
+		}
+	}
+
+	return detailsByID, nil
+}
+
+// canViewAuthor applies the profile visibility rules: blocked users see nothing,
+// and private accounts are only visible to their followers and themselves.
+func canViewAuthor(details payload.UserDetails, loggedInUserID uuid.UUID) bool {
+	if details.ID == loggedInUserID {
+		return true
+	}
+
+	if details.Blocked {
+		return false
+	}
+
+	return !details.Private || details.Followed
+}
+
+// maxSearchAuthors caps how many matching accounts a post search looks at, so
+// a broad query doesn't turn into a huge IN list.
+const maxSearchAuthors = 50
+
+// searchUserIDs finds the accounts whose username or name matches the query,
+// keeping the first ones user-service returns.
+func searchUserIDs(query string) ([]uuid.UUID, error) {
+	requestURL := fmt.Sprintf("http://%s:%s/search?query=%s", os.Getenv("USER_SERVICE_DOMAIN"), os.Getenv("USER_SERVICE_PORT"), url.QueryEscape(query))
+	response, err := http.Get(requestURL)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+
+	var users []payload.UserView
+
+	err = helpers.FromJSON(&users, response.Body)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var userIDs []uuid.UUID
+
+	for _, user := range users {
+		if len(userIDs) == maxSearchAuthors {
+			break
+		}
+
+		userIDs = append(userIDs, user.ID)
+	}
+
+	return userIDs, nil
+}
@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/KristijanPill/Nishtagram/story-service/helpers"
+	"github.com/KristijanPill/Nishtagram/story-service/service"
+)
+
+const maxSponsoredStories = 10
+
+type CampaignHandler struct {
+	service *service.CampaignService
+}
+
+func NewCampaignHandler(service *service.CampaignService) *CampaignHandler {
+	return &CampaignHandler{service: service}
+}
+
+func (handler *CampaignHandler) FindSponsored(w http.ResponseWriter, r *http.Request) {
+	count, err := strconv.Atoi(r.URL.Query().Get("count"))
+
+	if err != nil || count < 1 || count > maxSponsoredStories {
+		count = 1
+	}
+
+	stories, err := handler.service.FindSponsored(count, helpers.ExtractTokenFromHeader(r.Header["Authorization"][0]))
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	helpers.ToJSON(&stories, w)
+}
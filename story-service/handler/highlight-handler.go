@@ -0,0 +1,419 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/KristijanPill/Nishtagram/story-service/helpers"
+	"github.com/KristijanPill/Nishtagram/story-service/middleware"
+	"github.com/KristijanPill/Nishtagram/story-service/payload"
+	"github.com/KristijanPill/Nishtagram/story-service/service"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+type HighlightHandler struct {
+	service *service.HighlightService
+}
+
+func NewHighlightHandler(service *service.HighlightService) *HighlightHandler {
+	return &HighlightHandler{service: service}
+}
+
+func (handler *HighlightHandler) Create(w http.ResponseWriter, r *http.Request) {
+	dto := &payload.HighlightCreate{}
+	err := helpers.FromJSON(&dto, r.Body)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	claims := r.Context().Value(middleware.TokenKey{}).(jwt.MapClaims)
+
+	userIDString := helpers.ExtractClaim("sub", claims)
+	userID, err := uuid.Parse(userIDString)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	highlight, err := handler.service.Create(dto, userID)
+
+	if writeHighlightError(w, err) {
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	helpers.ToJSON(highlight, w)
+}
+
+func (handler *HighlightHandler) FindAllByLoggedInUser(w http.ResponseWriter, r *http.Request) {
+	claims := r.Context().Value(middleware.TokenKey{}).(jwt.MapClaims)
+
+	userIDString := helpers.ExtractClaim("sub", claims)
+	userID, err := uuid.Parse(userIDString)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	tokenString := helpers.ExtractTokenFromHeader(r.Header["Authorization"][0])
+
+	highlights, err := handler.service.FindAllByLoggedInUser(userID, tokenString)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	helpers.ToJSON(&highlights, w)
+}
+
+func (handler *HighlightHandler) FindByUser(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	userID, err := uuid.Parse(vars["id"])
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	var loggedInUserID uuid.UUID
+	if r.Context().Value(middleware.LoggedInUser{}) != nil {
+		loggedInUserID = r.Context().Value(middleware.LoggedInUser{}).(uuid.UUID)
+	}
+
+	var tokenString string
+	if loggedInUserID != uuid.Nil {
+		tokenString = helpers.ExtractTokenFromHeader(r.Header["Authorization"][0])
+	}
+
+	highlights, err := handler.service.FindByUser(userID, loggedInUserID, tokenString)
+
+	if writeHighlightError(w, err) {
+		return
+	}
+
+	helpers.ToJSON(&highlights, w)
+}
+
+func (handler *HighlightHandler) Update(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	highlightID, err := uuid.Parse(vars["id"])
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	dto := &payload.HighlightUpdate{}
+	err = helpers.FromJSON(&dto, r.Body)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	claims := r.Context().Value(middleware.TokenKey{}).(jwt.MapClaims)
+
+	userIDString := helpers.ExtractClaim("sub", claims)
+	userID, err := uuid.Parse(userIDString)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	highlight, err := handler.service.Update(highlightID, dto, userID)
+
+	if writeHighlightError(w, err) {
+		return
+	}
+
+	helpers.ToJSON(highlight, w)
+}
+
+func (handler *HighlightHandler) Reorder(w http.ResponseWriter, r *http.Request) {
+	dto := &payload.HighlightOrder{}
+	err := helpers.FromJSON(&dto, r.Body)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	claims := r.Context().Value(middleware.TokenKey{}).(jwt.MapClaims)
+
+	userIDString := helpers.ExtractClaim("sub", claims)
+	userID, err := uuid.Parse(userIDString)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	err = handler.service.Reorder(dto, userID)
+
+	if writeHighlightError(w, err) {
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (handler *HighlightHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	highlightID, err := uuid.Parse(vars["id"])
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	claims := r.Context().Value(middleware.TokenKey{}).(jwt.MapClaims)
+
+	userIDString := helpers.ExtractClaim("sub", claims)
+	userID, err := uuid.Parse(userIDString)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	err = handler.service.Delete(highlightID, userID)
+
+	if writeHighlightError(w, err) {
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (handler *HighlightHandler) AddStory(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	highlightID, err := uuid.Parse(vars["id"])
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	dto := &payload.HighlightStoryCreate{}
+	err = helpers.FromJSON(&dto, r.Body)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	claims := r.Context().Value(middleware.TokenKey{}).(jwt.MapClaims)
+
+	userIDString := helpers.ExtractClaim("sub", claims)
+	userID, err := uuid.Parse(userIDString)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	highlight, err := handler.service.AddStory(highlightID, dto.StoryID, userID)
+
+	if writeHighlightError(w, err) {
+		return
+	}
+
+	helpers.ToJSON(highlight, w)
+}
+
+func (handler *HighlightHandler) RemoveStory(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	highlightID, err := uuid.Parse(vars["id"])
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	storyID, err := uuid.Parse(vars["storyId"])
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	claims := r.Context().Value(middleware.TokenKey{}).(jwt.MapClaims)
+
+	userIDString := helpers.ExtractClaim("sub", claims)
+	userID, err := uuid.Parse(userIDString)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	err = handler.service.RemoveStory(highlightID, storyID, userID)
+
+	if writeHighlightError(w, err) {
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (handler *HighlightHandler) ReorderStories(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	highlightID, err := uuid.Parse(vars["id"])
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	dto := &payload.HighlightOrder{}
+	err = helpers.FromJSON(&dto, r.Body)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	claims := r.Context().Value(middleware.TokenKey{}).(jwt.MapClaims)
+
+	userIDString := helpers.ExtractClaim("sub", claims)
+	userID, err := uuid.Parse(userIDString)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	highlight, err := handler.service.ReorderStories(highlightID, dto, userID)
+
+	if writeHighlightError(w, err) {
+		return
+	}
+
+	helpers.ToJSON(highlight, w)
+}
+
+func (handler *HighlightHandler) HighlightStory(w http.ResponseWriter, r *http.Request) {
+	dto := &payload.StoryHighlightCreate{}
+	err := helpers.FromJSON(&dto, r.Body)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	claims := r.Context().Value(middleware.TokenKey{}).(jwt.MapClaims)
+
+	userIDString := helpers.ExtractClaim("sub", claims)
+	userID, err := uuid.Parse(userIDString)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	dto.UserID = userID
+
+	err = handler.service.HighlightStory(dto)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+}
+
+func (handler *HighlightHandler) GetAllHighlightNames(w http.ResponseWriter, r *http.Request) {
+	claims := r.Context().Value(middleware.TokenKey{}).(jwt.MapClaims)
+
+	userIDString := helpers.ExtractClaim("sub", claims)
+	userID, err := uuid.Parse(userIDString)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	highlightNames, err := handler.service.GetAllHighlightNames(userID)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	helpers.ToJSON(&highlightNames, w)
+}
+
+func (handler *HighlightHandler) GetAllByLoggedInUser(w http.ResponseWriter, r *http.Request) {
+	claims := r.Context().Value(middleware.TokenKey{}).(jwt.MapClaims)
+
+	userIDString := helpers.ExtractClaim("sub", claims)
+	userID, err := uuid.Parse(userIDString)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	tokenString := helpers.ExtractTokenFromHeader(r.Header["Authorization"][0])
+
+	highlights, err := handler.service.GetAllHighlightedStories(userID, tokenString)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	helpers.ToJSON(&highlights, w)
+}
+
+func writeHighlightError(w http.ResponseWriter, err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, service.ErrInvalidHighlightTitle), errors.Is(err, service.ErrEmptyHighlight), errors.Is(err, service.ErrCoverNotInHighlight):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return true
+	case errors.Is(err, service.ErrHighlightTitleTaken):
+		http.Error(w, err.Error(), http.StatusConflict)
+
+		return true
+	}
+
+	return writeStoryError(w, err)
+}
@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/KristijanPill/Nishtagram/story-service/helpers"
+	"github.com/KristijanPill/Nishtagram/story-service/model"
+	"github.com/KristijanPill/Nishtagram/story-service/payload"
+	"github.com/KristijanPill/Nishtagram/story-service/service"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+type ModerationHandler struct {
+	service *service.ModerationService
+}
+
+func NewModerationHandler(service *service.ModerationService) *ModerationHandler {
+	return &ModerationHandler{service: service}
+}
+
+func (handler *ModerationHandler) FindQueue(w http.ResponseWriter, r *http.Request) {
+	status := model.ReportStatus(r.URL.Query().Get("status"))
+
+	if status == "" {
+		status = model.PENDING
+	}
+
+	// post-service merges these groups with the reported posts, so it needs
+	// every group up to the end of the page it shows rather than one page.
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+
+	if err != nil || limit <= 0 {
+		http.Error(w, "invalid limit", http.StatusBadRequest)
+
+		return
+	}
+
+	queue, err := handler.service.FindQueue(status, limit)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	helpers.ToJSON(&queue, w)
+}
+
+func (handler *ModerationHandler) FindReports(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	storyID, err := uuid.Parse(vars["id"])
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	reports, err := handler.service.FindReports(storyID)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	helpers.ToJSON(&reports, w)
+}
+
+func (handler *ModerationHandler) FindPending(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	storyID, err := uuid.Parse(vars["id"])
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	pending, err := handler.service.FindPending(storyID)
+
+	if err == gorm.ErrRecordNotFound {
+		http.Error(w, err.Error(), http.StatusNotFound)
+
+		return
+	}
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	helpers.ToJSON(pending, w)
+}
+
+func (handler *ModerationHandler) Resolve(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	storyID, err := uuid.Parse(vars["id"])
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	dto := &payload.ReportResolve{}
+	err = helpers.FromJSON(&dto, r.Body)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	err = handler.service.Resolve(storyID, dto)
+
+	if err == gorm.ErrRecordNotFound {
+		http.Error(w, err.Error(), http.StatusNotFound)
+
+		return
+	}
+
+	if err == service.ErrInvalidModerationAction || err == service.ErrNoPendingReports {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,180 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/KristijanPill/Nishtagram/story-service/helpers"
+	"github.com/KristijanPill/Nishtagram/story-service/middleware"
+	"github.com/KristijanPill/Nishtagram/story-service/payload"
+	"github.com/KristijanPill/Nishtagram/story-service/service"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+type StickerHandler struct {
+	service *service.StickerService
+}
+
+func NewStickerHandler(service *service.StickerService) *StickerHandler {
+	return &StickerHandler{service: service}
+}
+
+func (handler *StickerHandler) Vote(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	stickerID, err := uuid.Parse(vars["id"])
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	dto := &payload.PollVoteCreate{}
+	err = helpers.FromJSON(&dto, r.Body)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	claims := r.Context().Value(middleware.TokenKey{}).(jwt.MapClaims)
+
+	userIDString := helpers.ExtractClaim("sub", claims)
+	userID, err := uuid.Parse(userIDString)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	tokenString := helpers.ExtractTokenFromHeader(r.Header["Authorization"][0])
+
+	err = handler.service.Vote(stickerID, userID, dto, tokenString)
+
+	if writeStickerError(w, err) {
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (handler *StickerHandler) Answer(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	stickerID, err := uuid.Parse(vars["id"])
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	dto := &payload.QuestionAnswerCreate{}
+	err = helpers.FromJSON(&dto, r.Body)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	claims := r.Context().Value(middleware.TokenKey{}).(jwt.MapClaims)
+
+	userIDString := helpers.ExtractClaim("sub", claims)
+	userID, err := uuid.Parse(userIDString)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	tokenString := helpers.ExtractTokenFromHeader(r.Header["Authorization"][0])
+
+	err = handler.service.Answer(stickerID, userID, dto, tokenString)
+
+	if writeStickerError(w, err) {
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (handler *StickerHandler) FindResults(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	storyID, err := uuid.Parse(vars["id"])
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	claims := r.Context().Value(middleware.TokenKey{}).(jwt.MapClaims)
+
+	userIDString := helpers.ExtractClaim("sub", claims)
+	userID, err := uuid.Parse(userIDString)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	results, err := handler.service.FindResults(storyID, userID)
+
+	if writeStickerError(w, err) {
+		return
+	}
+
+	helpers.ToJSON(&results, w)
+}
+
+func (handler *StickerHandler) FindAnswers(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	stickerID, err := uuid.Parse(vars["id"])
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	claims := r.Context().Value(middleware.TokenKey{}).(jwt.MapClaims)
+
+	userIDString := helpers.ExtractClaim("sub", claims)
+	userID, err := uuid.Parse(userIDString)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	page, size := helpers.ExtractPagination(r)
+
+	answers, err := handler.service.FindAnswers(stickerID, userID, page, size)
+
+	if writeStickerError(w, err) {
+		return
+	}
+
+	helpers.ToJSON(&answers, w)
+}
+
+func writeStickerError(w http.ResponseWriter, err error) bool {
+	switch err {
+	case service.ErrInvalidSticker, service.ErrTooManyStickers, service.ErrInvalidVote, service.ErrInvalidAnswer, service.ErrStickerSelf:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return true
+	case service.ErrAlreadyVoted:
+		http.Error(w, err.Error(), http.StatusConflict)
+
+		return true
+	}
+
+	return writeStoryError(w, err)
+}
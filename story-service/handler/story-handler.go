@@ -6,6 +6,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"os"
+	"strings"
 
 	"github.com/KristijanPill/Nishtagram/story-service/helpers"
 	"github.com/KristijanPill/Nishtagram/story-service/middleware"
@@ -15,6 +16,7 @@ import (
 	"github.com/dgrijalva/jwt-go"
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
+	"gorm.io/gorm"
 )
 
 type StoryHandler struct {
@@ -34,6 +36,44 @@ func (handler *StoryHandler) Create(w http.ResponseWriter, r *http.Request) {
 	}
 
 	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	var stickers []payload.StickerCreate
+
+	if stickersJSON := r.FormValue("stickers"); stickersJSON != "" {
+		err = helpers.FromJSON(&stickers, strings.NewReader(stickersJSON))
+
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+
+			return
+		}
+	}
+
+	if writeStickerError(w, handler.service.CheckStickers(stickers)) {
+		return
+	}
+
+	closeFriendsOnly := r.FormValue("close_friends") == "1"
+	var audienceListID *uuid.UUID
+
+	if audienceList := r.FormValue("audience_list"); audienceList != "" {
+		listID, err := uuid.Parse(audienceList)
+
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+
+			return
+		}
+
+		audienceListID = &listID
+	}
+
+	tokenString := helpers.ExtractTokenFromHeader(r.Header["Authorization"][0])
+
+	if writeStoryError(w, handler.service.CheckAudience(closeFriendsOnly, audienceListID, tokenString)) {
+		return
+	}
+
 	requestURL := fmt.Sprintf("http://%s:%s/upload/story", os.Getenv("MEDIA_SERVICE_DOMAIN"), os.Getenv("MEDIA_SERVICE_PORT"))
 	proxyReq, err := http.NewRequest(http.MethodPost, requestURL, bytes.NewReader(body))
 
@@ -58,6 +98,15 @@ func (handler *StoryHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		message, _ := ioutil.ReadAll(response.Body)
+		http.Error(w, strings.TrimSpace(string(message)), response.StatusCode)
+
+		return
+	}
+
 	storyPaths := &payload.StoryUploadResponse{}
 
 	err = helpers.FromJSON(&storyPaths, response.Body)
@@ -81,16 +130,79 @@ func (handler *StoryHandler) Create(w http.ResponseWriter, r *http.Request) {
 	story := &model.Story{
 		UserID:           userID,
 		Content:          storyPaths.StoryPaths,
-		CloseFriendsOnly: r.FormValue("close_friends") == "1",
+		Media:            newStoryMedia(storyPaths.Media),
+		CloseFriendsOnly: closeFriendsOnly,
+		AudienceListID:   audienceListID,
 	}
 
-	_, err = handler.service.Create(story)
+	_, err = handler.service.Create(story, stickers)
+
+	if writeStickerError(w, err) {
+		return
+	}
+}
+
+func (handler *StoryHandler) SharePost(w http.ResponseWriter, r *http.Request) {
+	dto := &payload.StoryShareCreate{}
+	err := helpers.FromJSON(&dto, r.Body)
 
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	claims := r.Context().Value(middleware.TokenKey{}).(jwt.MapClaims)
+
+	userIDString := helpers.ExtractClaim("sub", claims)
+	userID, err := uuid.Parse(userIDString)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	tokenString := helpers.ExtractTokenFromHeader(r.Header["Authorization"][0])
+
+	story, err := handler.service.SharePost(userID, dto, tokenString)
+
+	if writeStoryError(w, err) {
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	helpers.ToJSON(story, w)
+}
+
+func (handler *StoryHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	storyID, err := uuid.Parse(vars["id"])
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	claims := r.Context().Value(middleware.TokenKey{}).(jwt.MapClaims)
+
+	userIDString := helpers.ExtractClaim("sub", claims)
+	userID, err := uuid.Parse(userIDString)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 
 		return
 	}
+
+	err = handler.service.Delete(storyID, userID)
+
+	if writeStoryError(w, err) {
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
 }
 
 func (handler *StoryHandler) FindByUser(w http.ResponseWriter, r *http.Request) {
@@ -116,6 +228,29 @@ func (handler *StoryHandler) FindByUser(w http.ResponseWriter, r *http.Request)
 
 	stories, err := handler.service.FindByUser(userID, loggedInUserID, tokenString)
 
+	if writeStoryError(w, err) {
+		return
+	}
+
+	helpers.ToJSON(&stories, w)
+}
+
+func (handler *StoryHandler) FindByLoggedInUser(w http.ResponseWriter, r *http.Request) {
+	claims := r.Context().Value(middleware.TokenKey{}).(jwt.MapClaims)
+
+	userIDString := helpers.ExtractClaim("sub", claims)
+	userID, err := uuid.Parse(userIDString)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	tokenString := helpers.ExtractTokenFromHeader(r.Header["Authorization"][0])
+
+	stories, err := handler.service.FindByLoggedInUser(userID, tokenString)
+
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 
@@ -125,7 +260,7 @@ func (handler *StoryHandler) FindByUser(w http.ResponseWriter, r *http.Request)
 	helpers.ToJSON(&stories, w)
 }
 
-func (handler *StoryHandler) FindByLoggedInUser(w http.ResponseWriter, r *http.Request) {
+func (handler *StoryHandler) FindAllByLoggedInUser(w http.ResponseWriter, r *http.Request) {
 	claims := r.Context().Value(middleware.TokenKey{}).(jwt.MapClaims)
 
 	userIDString := helpers.ExtractClaim("sub", claims)
@@ -137,7 +272,9 @@ func (handler *StoryHandler) FindByLoggedInUser(w http.ResponseWriter, r *http.R
 		return
 	}
 
-	stories, err := handler.service.FindByLoggedInUser(userID)
+	tokenString := helpers.ExtractTokenFromHeader(r.Header["Authorization"][0])
+
+	stories, err := handler.service.FindAllByLoggedInUser(userID, tokenString)
 
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -148,7 +285,7 @@ func (handler *StoryHandler) FindByLoggedInUser(w http.ResponseWriter, r *http.R
 	helpers.ToJSON(&stories, w)
 }
 
-func (handler *StoryHandler) FindAllByLoggedInUser(w http.ResponseWriter, r *http.Request) {
+func (handler *StoryHandler) FindArchive(w http.ResponseWriter, r *http.Request) {
 	claims := r.Context().Value(middleware.TokenKey{}).(jwt.MapClaims)
 
 	userIDString := helpers.ExtractClaim("sub", claims)
@@ -160,7 +297,11 @@ func (handler *StoryHandler) FindAllByLoggedInUser(w http.ResponseWriter, r *htt
 		return
 	}
 
-	stories, err := handler.service.FindAllByLoggedInUser(userID)
+	page, size := helpers.ExtractPagination(r)
+
+	tokenString := helpers.ExtractTokenFromHeader(r.Header["Authorization"][0])
+
+	stories, err := handler.service.FindArchive(userID, page, size, tokenString)
 
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -171,6 +312,204 @@ func (handler *StoryHandler) FindAllByLoggedInUser(w http.ResponseWriter, r *htt
 	helpers.ToJSON(&stories, w)
 }
 
+func (handler *StoryHandler) FindTray(w http.ResponseWriter, r *http.Request) {
+	claims := r.Context().Value(middleware.TokenKey{}).(jwt.MapClaims)
+
+	userIDString := helpers.ExtractClaim("sub", claims)
+	userID, err := uuid.Parse(userIDString)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	tokenString := helpers.ExtractTokenFromHeader(r.Header["Authorization"][0])
+
+	tray, err := handler.service.FindTray(userID, tokenString)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	helpers.ToJSON(tray, w)
+}
+
+func (handler *StoryHandler) RecordView(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	storyID, err := uuid.Parse(vars["id"])
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	claims := r.Context().Value(middleware.TokenKey{}).(jwt.MapClaims)
+
+	userIDString := helpers.ExtractClaim("sub", claims)
+	userID, err := uuid.Parse(userIDString)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	tokenString := helpers.ExtractTokenFromHeader(r.Header["Authorization"][0])
+
+	err = handler.service.RecordView(storyID, userID, tokenString)
+
+	if writeStoryError(w, err) {
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (handler *StoryHandler) Reply(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	storyID, err := uuid.Parse(vars["id"])
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	dto := &payload.StoryReplyCreate{}
+	err = helpers.FromJSON(&dto, r.Body)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	claims := r.Context().Value(middleware.TokenKey{}).(jwt.MapClaims)
+
+	userIDString := helpers.ExtractClaim("sub", claims)
+	userID, err := uuid.Parse(userIDString)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	tokenString := helpers.ExtractTokenFromHeader(r.Header["Authorization"][0])
+
+	err = handler.service.Reply(storyID, userID, dto, tokenString)
+
+	if writeStoryError(w, err) {
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (handler *StoryHandler) React(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	storyID, err := uuid.Parse(vars["id"])
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	dto := &payload.StoryReactionCreate{}
+	err = helpers.FromJSON(&dto, r.Body)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	claims := r.Context().Value(middleware.TokenKey{}).(jwt.MapClaims)
+
+	userIDString := helpers.ExtractClaim("sub", claims)
+	userID, err := uuid.Parse(userIDString)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	tokenString := helpers.ExtractTokenFromHeader(r.Header["Authorization"][0])
+
+	err = handler.service.React(storyID, userID, dto, tokenString)
+
+	if writeStoryError(w, err) {
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (handler *StoryHandler) FindViewers(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	storyID, err := uuid.Parse(vars["id"])
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	claims := r.Context().Value(middleware.TokenKey{}).(jwt.MapClaims)
+
+	userIDString := helpers.ExtractClaim("sub", claims)
+	userID, err := uuid.Parse(userIDString)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	page, size := helpers.ExtractPagination(r)
+
+	viewers, err := handler.service.FindViewers(storyID, userID, page, size)
+
+	if writeStoryError(w, err) {
+		return
+	}
+
+	helpers.ToJSON(viewers, w)
+}
+
+func (handler *StoryHandler) GetDetails(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	storyID, err := uuid.Parse(vars["id"])
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	story, err := handler.service.GetDetails(storyID)
+
+	if err == gorm.ErrRecordNotFound {
+		http.Error(w, err.Error(), http.StatusNotFound)
+
+		return
+	}
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	helpers.ToJSON(story, w)
+}
+
 func (handler *StoryHandler) CreateReport(w http.ResponseWriter, r *http.Request) {
 
 	dto := &payload.ReportCreate{}
@@ -204,3 +543,55 @@ func (handler *StoryHandler) CreateReport(w http.ResponseWriter, r *http.Request
 	}
 
 }
+
+func writeStoryError(w http.ResponseWriter, err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case err == service.ErrPrivateAccount:
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		helpers.ToJSON(&payload.AccessDenied{Error: err.Error(), RequestToFollow: true}, w)
+	case err == service.ErrNotStoryOwner, err == service.ErrStoryResponseBlocked, err == service.ErrSharedPostPrivate:
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case err == service.ErrInvalidReaction, err == service.ErrInvalidStoryResponse, err == service.ErrStoryResponseSelf, err == service.ErrInvalidAudience:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case err == service.ErrStoryUnavailable, err == service.ErrSharedPostUnavailable, err == gorm.ErrRecordNotFound:
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+
+	return true
+}
+
+// newStoryMedia keeps what media-service told about the uploaded media, in the
+// upload order.
+func newStoryMedia(uploaded []payload.MediaMetadata) []model.StoryMedia {
+	var media []model.StoryMedia
+
+	for position, metadata := range uploaded {
+		storyMedia := model.StoryMedia{
+			Path:     metadata.Path,
+			Blurhash: metadata.Blurhash,
+			Type:     metadata.Type,
+			Width:    metadata.Width,
+			Height:   metadata.Height,
+			Duration: metadata.Duration,
+			Position: position,
+		}
+
+		for _, rendition := range metadata.Renditions {
+			switch rendition.Name {
+			case "thumbnail":
+				storyMedia.ThumbnailPath = rendition.Path
+			case "feed":
+				storyMedia.FeedPath = rendition.Path
+			}
+		}
+
+		media = append(media, storyMedia)
+	}
+
+	return media
+}
@@ -0,0 +1,31 @@
+package helpers
+
+import (
+	"net/http"
+	"strconv"
+)
+
+const (
+	defaultPageSize = 12
+	maxPageSize     = 50
+)
+
+func ExtractPagination(r *http.Request) (int, int) {
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+
+	if err != nil || page < 0 {
+		page = 0
+	}
+
+	size, err := strconv.Atoi(r.URL.Query().Get("size"))
+
+	if err != nil || size <= 0 {
+		size = defaultPageSize
+	}
+
+	if size > maxPageSize {
+		size = maxPageSize
+	}
+
+	return page, size
+}
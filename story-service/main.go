@@ -23,6 +23,8 @@ import (
 	"gorm.io/gorm"
 )
 
+const uuidPattern = "[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}"
+
 var publicKey *rsa.PublicKey
 
 func init() {
@@ -45,49 +47,109 @@ func initDatabase() *gorm.DB {
 	}
 
 	db.AutoMigrate(&model.Story{})
-	db.AutoMigrate(&model.StoryHighlight{})
+	db.AutoMigrate(&model.StoryMedia{})
+	db.AutoMigrate(&model.Highlight{})
+	db.AutoMigrate(&model.HighlightStory{})
+	db.AutoMigrate(&model.StoryViewer{})
+	db.AutoMigrate(&model.Sticker{})
+	db.AutoMigrate(&model.PollVote{})
+	db.AutoMigrate(&model.QuestionAnswer{})
 	db.AutoMigrate(&model.Report{})
 
+	if err := repository.NewHighlightRepository(db).MigrateStoryHighlights(); err != nil {
+		panic(err.Error())
+	}
+
+	if err := repository.NewModerationRepository(db).MigrateReports(); err != nil {
+		panic(err.Error())
+	}
+
 	return db
 }
 
-func handleFunc(storyHandler *handler.StoryHandler, storyHighlightHandler *handler.StoryHighlightHandler, securityMiddleware *middleware.SecurityMiddleware, sm *mux.Router) {
+func handleFunc(storyHandler *handler.StoryHandler, highlightHandler *handler.HighlightHandler, moderationHandler *handler.ModerationHandler, campaignHandler *handler.CampaignHandler, stickerHandler *handler.StickerHandler, securityMiddleware *middleware.SecurityMiddleware, sm *mux.Router) {
 	postRouter := sm.Methods(http.MethodPost).Subrouter()
 	postRouter.HandleFunc("/", storyHandler.Create)
-	postRouter.HandleFunc("/highlight", storyHighlightHandler.HighlightStory)
+	postRouter.HandleFunc("/share", storyHandler.SharePost)
+	postRouter.HandleFunc("/highlight", highlightHandler.HighlightStory)
+	postRouter.HandleFunc("/highlights", highlightHandler.Create)
+	postRouter.HandleFunc("/highlights/{id:"+uuidPattern+"}/stories", highlightHandler.AddStory)
 	postRouter.HandleFunc("/report", storyHandler.CreateReport)
+	postRouter.HandleFunc("/{id:"+uuidPattern+"}/view", storyHandler.RecordView)
+	postRouter.HandleFunc("/{id:"+uuidPattern+"}/reply", storyHandler.Reply)
+	postRouter.HandleFunc("/{id:"+uuidPattern+"}/react", storyHandler.React)
+	postRouter.HandleFunc("/stickers/{id:"+uuidPattern+"}/vote", stickerHandler.Vote)
+	postRouter.HandleFunc("/stickers/{id:"+uuidPattern+"}/answer", stickerHandler.Answer)
 
 	postRouter.Use(securityMiddleware.Authenticate)
 
 	getRouterPublic := sm.Methods(http.MethodGet).Subrouter()
 	getRouterPublic.HandleFunc("/user/{id}", storyHandler.FindByUser)
+	getRouterPublic.HandleFunc("/highlights/user/{id}", highlightHandler.FindByUser)
 	getRouterPublic.Use(securityMiddleware.UserContext)
 
 	getRouterRestricted := sm.Methods(http.MethodGet).Subrouter()
 	getRouterRestricted.HandleFunc("/", storyHandler.FindByLoggedInUser)
 	getRouterRestricted.HandleFunc("/all", storyHandler.FindAllByLoggedInUser)
-	getRouterRestricted.HandleFunc("/highlight/names", storyHighlightHandler.GetAllHighlightNames)
-	getRouterRestricted.HandleFunc("/highlight", storyHighlightHandler.GetAllByLoggedInUser)
+	getRouterRestricted.HandleFunc("/archive", storyHandler.FindArchive)
+	getRouterRestricted.HandleFunc("/tray", storyHandler.FindTray)
+	getRouterRestricted.HandleFunc("/sponsored", campaignHandler.FindSponsored)
+	getRouterRestricted.HandleFunc("/{id:"+uuidPattern+"}/viewers", storyHandler.FindViewers)
+	getRouterRestricted.HandleFunc("/{id:"+uuidPattern+"}/stickers/results", stickerHandler.FindResults)
+	getRouterRestricted.HandleFunc("/stickers/{id:"+uuidPattern+"}/answers", stickerHandler.FindAnswers)
+	getRouterRestricted.HandleFunc("/highlight/names", highlightHandler.GetAllHighlightNames)
+	getRouterRestricted.HandleFunc("/highlight", highlightHandler.GetAllByLoggedInUser)
+	getRouterRestricted.HandleFunc("/highlights", highlightHandler.FindAllByLoggedInUser)
 	getRouterRestricted.Use(securityMiddleware.Authenticate)
 
+	putRouter := sm.Methods(http.MethodPut).Subrouter()
+	putRouter.HandleFunc("/highlights/order", highlightHandler.Reorder)
+	putRouter.HandleFunc("/highlights/{id:"+uuidPattern+"}", highlightHandler.Update)
+	putRouter.HandleFunc("/highlights/{id:"+uuidPattern+"}/stories/order", highlightHandler.ReorderStories)
+	putRouter.Use(securityMiddleware.Authenticate)
+
+	deleteRouter := sm.Methods(http.MethodDelete).Subrouter()
+	deleteRouter.HandleFunc("/{id:"+uuidPattern+"}", storyHandler.Delete)
+	deleteRouter.HandleFunc("/highlights/{id:"+uuidPattern+"}", highlightHandler.Delete)
+	deleteRouter.HandleFunc("/highlights/{id:"+uuidPattern+"}/stories/{storyId:"+uuidPattern+"}", highlightHandler.RemoveStory)
+	deleteRouter.Use(securityMiddleware.Authenticate)
+
+	getRouterInternal := sm.Methods(http.MethodGet).Subrouter()
+	getRouterInternal.HandleFunc("/internal/stories/{id}", storyHandler.GetDetails)
+	getRouterInternal.HandleFunc("/internal/reports", moderationHandler.FindQueue)
+	getRouterInternal.HandleFunc("/internal/reports/{id:"+uuidPattern+"}", moderationHandler.FindReports)
+	getRouterInternal.HandleFunc("/internal/reports/{id:"+uuidPattern+"}/pending", moderationHandler.FindPending)
+
+	postRouterInternal := sm.Methods(http.MethodPost).Subrouter()
+	postRouterInternal.HandleFunc("/internal/reports/{id:"+uuidPattern+"}/resolve", moderationHandler.Resolve)
 }
 
 func main() {
 	database := initDatabase()
 
 	storyRepository := repository.NewStoryRepository(database)
-	storyHighlightRepository := repository.NewStoryHighlightRepository(database)
-
-	storyService := service.NewStoryService(storyRepository)
-	storyHighlightService := service.NewStoryHighlightService(storyHighlightRepository, storyRepository)
+	highlightRepository := repository.NewHighlightRepository(database)
+	storyViewerRepository := repository.NewStoryViewerRepository(database)
+	moderationRepository := repository.NewModerationRepository(database)
+	stickerRepository := repository.NewStickerRepository(database)
+
+	campaignService := service.NewCampaignService()
+	storyService := service.NewStoryService(storyRepository, storyViewerRepository, campaignService)
+	storyArchiveService := service.NewStoryArchiveService(storyRepository)
+	highlightService := service.NewHighlightService(highlightRepository, storyRepository)
+	moderationService := service.NewModerationService(moderationRepository, storyRepository)
+	stickerService := service.NewStickerService(stickerRepository, storyRepository)
 
 	securityMiddleware := middleware.NewSecurityMiddleware(publicKey)
 	storyHandler := handler.NewStoryHandler(storyService)
-	storyHighlightHandler := handler.NewStoryHighlightHandler(storyHighlightService)
+	highlightHandler := handler.NewHighlightHandler(highlightService)
+	moderationHandler := handler.NewModerationHandler(moderationService)
+	campaignHandler := handler.NewCampaignHandler(campaignService)
+	stickerHandler := handler.NewStickerHandler(stickerService)
 
 	sm := mux.NewRouter()
 
-	handleFunc(storyHandler, storyHighlightHandler, securityMiddleware, sm)
+	handleFunc(storyHandler, highlightHandler, moderationHandler, campaignHandler, stickerHandler, securityMiddleware, sm)
 
 	bindAddress := fmt.Sprintf(":%s", os.Getenv("STORY_SERVICE_PORT"))
 
@@ -113,6 +175,15 @@ func main() {
 		}
 	}()
 
+	// archive expired stories and delete media past retention every 5 minutes
+	go func() {
+		for {
+			storyArchiveService.ArchiveExpired()
+			storyArchiveService.DeleteExpiredMedia()
+			time.Sleep(5 * time.Minute)
+		}
+	}()
+
 	// start the server
 	go func() {
 
@@ -8,27 +8,183 @@ import (
 	"gorm.io/gorm"
 )
 
+// Story is visible for a day after it's created. Expired stories are moved to
+// the archive of their owner, and their media are deleted from media-service
+// once the retention period is over, unless the story is highlighted. A story
+// that shares a post has no media of its own; the post is fetched from
+// post-service whenever the story is shown. A story is shown to everyone, to
+// close friends only or to the members of one of its owner's audience lists.
 type Story struct {
 	ID               uuid.UUID `gorm:"primary_key; unique; type:uuid;"`
 	UserID           uuid.UUID
 	CreatedAt        time.Time
 	Content          pq.StringArray `gorm:"type:varchar(1000)[]"`
 	CloseFriendsOnly bool
+	AudienceListID   *uuid.UUID `gorm:"type:uuid"`
+	SharedPostID     *uuid.UUID `gorm:"type:uuid; index"`
+	Media            []StoryMedia
+	Stickers         []Sticker
+	ArchivedAt       *time.Time     `gorm:"index"`
+	MediaDeletedAt   *time.Time     `gorm:"index"`
+	DeletedAt        gorm.DeletedAt `gorm:"index"`
 }
 
+// StoryMedia describes one of the story's media as media-service stored it.
+// Story.Content keeps the paths in the same order. Images also have smaller
+// renditions, and a blurhash to show while they load.
+type StoryMedia struct {
+	ID            uuid.UUID `gorm:"primaryKey; unique; type:uuid"`
+	StoryID       uuid.UUID `gorm:"type:uuid; index"`
+	Path          string    `gorm:"type:varchar(1000)"`
+	ThumbnailPath string    `gorm:"type:varchar(1000)"`
+	FeedPath      string    `gorm:"type:varchar(1000)"`
+	Blurhash      string
+	Type          string
+	Width         int
+	Height        int
+	Duration      float64
+	Position      int
+}
+
+type StickerType string
+
+const (
+	POLL     StickerType = "POLL"
+	QUESTION StickerType = "QUESTION"
+	MENTION  StickerType = "MENTION"
+	LOCATION StickerType = "LOCATION"
+	HASHTAG  StickerType = "HASHTAG"
+)
+
+func (stickerType StickerType) IsValid() bool {
+	switch stickerType {
+	case POLL, QUESTION, MENTION, LOCATION, HASHTAG:
+		return true
+	}
+
+	return false
+}
+
+// Sticker is placed on a story at X and Y, given as fractions of the story's
+// width and height. Text holds the poll or question prompt, the mentioned
+// username, the location name or the hashtag.
+type Sticker struct {
+	ID              uuid.UUID `gorm:"primaryKey; unique; type:uuid"`
+	StoryID         uuid.UUID `gorm:"type:uuid; index"`
+	Type            StickerType
+	Text            string
+	Options         pq.StringArray `gorm:"type:varchar(100)[]"`
+	MentionedUserID *uuid.UUID     `gorm:"type:uuid"`
+	Latitude        *float64
+	Longitude       *float64
+	X               float64
+	Y               float64
+	CreatedAt       time.Time
+}
+
+// PollVote is the option a user picked on a poll sticker. A user votes once.
+type PollVote struct {
+	StickerID uuid.UUID `gorm:"primaryKey; type:uuid"`
+	UserID    uuid.UUID `gorm:"primaryKey; type:uuid"`
+	Option    int
+	CreatedAt time.Time
+}
+
+type QuestionAnswer struct {
+	ID        uuid.UUID `gorm:"primaryKey; unique; type:uuid"`
+	StickerID uuid.UUID `gorm:"type:uuid; index"`
+	UserID    uuid.UUID `gorm:"type:uuid"`
+	Text      string
+	CreatedAt time.Time
+}
+
+// StoryViewer records that a user has seen a story. Seeing a story again keeps
+// the time it was first seen.
+type StoryViewer struct {
+	StoryID   uuid.UUID `gorm:"primaryKey; type:uuid"`
+	ViewerID  uuid.UUID `gorm:"primaryKey; type:uuid; index"`
+	CreatedAt time.Time
+}
+
+type ReportReason string
+
+const (
+	SPAM                  ReportReason = "SPAM"
+	NUDITY                ReportReason = "NUDITY"
+	HATE_SPEECH           ReportReason = "HATE_SPEECH"
+	VIOLENCE              ReportReason = "VIOLENCE"
+	HARASSMENT            ReportReason = "HARASSMENT"
+	FALSE_INFORMATION     ReportReason = "FALSE_INFORMATION"
+	INTELLECTUAL_PROPERTY ReportReason = "INTELLECTUAL_PROPERTY"
+	SELF_HARM             ReportReason = "SELF_HARM"
+	OTHER                 ReportReason = "OTHER"
+)
+
+func (reason ReportReason) IsValid() bool {
+	switch reason {
+	case SPAM, NUDITY, HATE_SPEECH, VIOLENCE, HARASSMENT, FALSE_INFORMATION, INTELLECTUAL_PROPERTY, SELF_HARM, OTHER:
+		return true
+	}
+
+	return false
+}
+
+type ReportStatus string
+
+const (
+	PENDING   ReportStatus = "PENDING"
+	DISMISSED ReportStatus = "DISMISSED"
+	ACTIONED  ReportStatus = "ACTIONED"
+)
+
 type Report struct {
 	ID          uuid.UUID `gorm:"primary_key;  unique; type:uuid;"`
 	UserID      uuid.UUID `gorm:"type:uuid"`
 	StoryID     uuid.UUID `gorm:"foreign_key; not_unique; type:uuid;"`
+	Reason      ReportReason
 	Description string
+	Status      ReportStatus `gorm:"index; default:PENDING"`
+	CreatedAt   time.Time
+	ResolvedAt  *time.Time
+}
+
+type ModerationActionType string
+
+const (
+	DISMISS         ModerationActionType = "DISMISS"
+	REMOVE_CONTENT  ModerationActionType = "REMOVE_CONTENT"
+	WARN_USER       ModerationActionType = "WARN_USER"
+	SUSPEND_ACCOUNT ModerationActionType = "SUSPEND_ACCOUNT"
+)
+
+func (action ModerationActionType) IsValid() bool {
+	switch action {
+	case DISMISS, REMOVE_CONTENT, WARN_USER, SUSPEND_ACCOUNT:
+		return true
+	}
+
+	return false
+}
+
+// Highlight is an album of stories kept on the owner's profile after they
+// expire. Without a cover story the first story of the album is the cover.
+type Highlight struct {
+	ID           uuid.UUID  `gorm:"primaryKey; unique; type:uuid"`
+	UserID       uuid.UUID  `gorm:"type:uuid; uniqueIndex:idx_highlight_user_title"`
+	Title        string     `gorm:"uniqueIndex:idx_highlight_user_title"`
+	CoverStoryID *uuid.UUID `gorm:"type:uuid"`
+	Position     int
+	Stories      []HighlightStory
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
 }
 
-type StoryHighlight struct {
-	UserID        uuid.UUID `gorm:"primaryKey; type:uuid"`
-	StoryID       uuid.UUID `gorm:"primaryKey; type:uuid"`
-	Story         Story
-	CreatedAt     time.Time
-	HighlightName string
+type HighlightStory struct {
+	HighlightID uuid.UUID `gorm:"primaryKey; type:uuid"`
+	StoryID     uuid.UUID `gorm:"primaryKey; type:uuid; index"`
+	Story       Story
+	Position    int
+	CreatedAt   time.Time
 }
 
 func (s *Story) BeforeCreate(tx *gorm.DB) (err error) {
@@ -36,6 +192,26 @@ func (s *Story) BeforeCreate(tx *gorm.DB) (err error) {
 	return nil
 }
 
+func (m *StoryMedia) BeforeCreate(tx *gorm.DB) (err error) {
+	m.ID = uuid.New()
+	return nil
+}
+
+func (s *Sticker) BeforeCreate(tx *gorm.DB) (err error) {
+	s.ID = uuid.New()
+	return nil
+}
+
+func (a *QuestionAnswer) BeforeCreate(tx *gorm.DB) (err error) {
+	a.ID = uuid.New()
+	return nil
+}
+
+func (h *Highlight) BeforeCreate(tx *gorm.DB) (err error) {
+	h.ID = uuid.New()
+	return nil
+}
+
 func (r *Report) BeforeCreate(tx *gorm.DB) (err error) {
 	r.ID = uuid.New()
 	return nil
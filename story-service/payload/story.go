@@ -3,11 +3,41 @@ package payload
 import (
 	"time"
 
+	"github.com/KristijanPill/Nishtagram/story-service/model"
 	"github.com/google/uuid"
 )
 
 type StoryUploadResponse struct {
-	StoryPaths []string `json:"mediaPaths"`
+	StoryPaths []string        `json:"mediaPaths"`
+	Media      []MediaMetadata `json:"media"`
+}
+
+type MediaMetadata struct {
+	Path       string           `json:"path"`
+	Type       string           `json:"type"`
+	Width      int              `json:"width"`
+	Height     int              `json:"height"`
+	Duration   float64          `json:"duration"`
+	Blurhash   string           `json:"blurhash"`
+	Renditions []MediaRendition `json:"renditions"`
+}
+
+type MediaRendition struct {
+	Name   string `json:"name"`
+	Path   string `json:"path"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+type MediaView struct {
+	Path          string  `json:"path"`
+	ThumbnailPath string  `json:"thumbnail_path,omitempty"`
+	FeedPath      string  `json:"feed_path,omitempty"`
+	Blurhash      string  `json:"blurhash,omitempty"`
+	Type          string  `json:"type"`
+	Width         int     `json:"width,omitempty"`
+	Height        int     `json:"height,omitempty"`
+	Duration      float64 `json:"duration,omitempty"`
 }
 
 type StoryHighlightCreate struct {
@@ -17,18 +47,72 @@ type StoryHighlightCreate struct {
 }
 
 type ReportCreate struct {
-	UserID      uuid.UUID `json:"user_id"`
-	StoryID     uuid.UUID `json:"story_id"`
-	Description string    `json:"description"`
+	UserID      uuid.UUID          `json:"user_id"`
+	StoryID     uuid.UUID          `json:"story_id"`
+	Reason      model.ReportReason `json:"reason"`
+	Description string             `json:"description"`
 }
 
 type StoryView struct {
+	ID               uuid.UUID       `json:"id"`
+	CreatedAt        time.Time       `json:"created_at"`
+	Content          []string        `json:"content"`
+	Media            []MediaView     `json:"media"`
+	CloseFriendsOnly bool            `json:"close_friends_only"`
+	AudienceListID   *uuid.UUID      `json:"audience_list_id,omitempty"`
+	Seen             bool            `json:"seen"`
+	Stickers         []StickerView   `json:"stickers"`
+	SharedPost       *SharedPostView `json:"shared_post,omitempty"`
+}
+
+type OutgoingFollow struct {
+	UserID      uuid.UUID `json:"user_id"`
+	CloseFriend bool      `json:"close_friend"`
+}
+
+type StoryTrayItem struct {
+	UserID        uuid.UUID   `json:"user_id"`
+	HasUnseen     bool        `json:"has_unseen"`
+	LatestStoryAt time.Time   `json:"latest_story_at"`
+	Stories       []StoryView `json:"stories"`
+}
+
+type StoryTray struct {
+	Authors   []StoryTrayItem      `json:"authors"`
+	Sponsored []SponsoredStoryView `json:"sponsored"`
+}
+
+type StoryViewerView struct {
+	UserID   uuid.UUID `json:"user_id"`
+	ViewedAt time.Time `json:"viewed_at"`
+}
+
+type StoryViewers struct {
+	Total   int64             `json:"total"`
+	Viewers []StoryViewerView `json:"viewers"`
+}
+
+type StoryDetails struct {
 	ID               uuid.UUID `json:"id"`
-	CreatedAt        time.Time `json:"created_at"`
+	UserID           uuid.UUID `json:"user_id"`
 	Content          []string  `json:"content"`
 	CloseFriendsOnly bool      `json:"close_friends_only"`
 }
 
+type SponsoredStory struct {
+	CampaignID uuid.UUID `json:"campaign_id"`
+	AgentID    uuid.UUID `json:"agent_id"`
+	StoryID    uuid.UUID `json:"story_id"`
+	Content    []string  `json:"content"`
+}
+
+type SponsoredStoryView struct {
+	StoryView  StoryView `json:"story"`
+	CampaignID uuid.UUID `json:"campaign_id"`
+	AgentID    uuid.UUID `json:"agent_id"`
+	Sponsored  bool      `json:"sponsored"`
+}
+
 type FollowStatus struct {
 	IsFollower  bool `json:"is_follower"`
 	CloseFriend bool `json:"close_friend"`
@@ -38,3 +122,222 @@ type StoryHighlightView struct {
 	StoryView     StoryView `json:"story"`
 	HighlightName string    `json:"highlight_name"`
 }
+
+type HighlightCreate struct {
+	Title        string      `json:"title"`
+	StoryIDs     []uuid.UUID `json:"story_ids"`
+	CoverStoryID *uuid.UUID  `json:"cover_story_id"`
+}
+
+type HighlightUpdate struct {
+	Title        string     `json:"title"`
+	CoverStoryID *uuid.UUID `json:"cover_story_id"`
+}
+
+type HighlightStoryCreate struct {
+	StoryID uuid.UUID `json:"story_id"`
+}
+
+type HighlightOrder struct {
+	IDs []uuid.UUID `json:"ids"`
+}
+
+type HighlightView struct {
+	ID              uuid.UUID   `json:"id"`
+	Title           string      `json:"title"`
+	CoverStoryID    *uuid.UUID  `json:"cover_story_id"`
+	CoverImage      string      `json:"cover_image"`
+	NumberOfStories int         `json:"number_of_stories"`
+	Position        int         `json:"position"`
+	Stories         []StoryView `json:"stories"`
+}
+
+type AccessDenied struct {
+	Error           string `json:"error"`
+	RequestToFollow bool   `json:"request_to_follow"`
+}
+
+type UserID struct {
+	ID uuid.UUID `json:"id"`
+}
+
+type UserIDs struct {
+	IDs []UserID `json:"ids"`
+}
+
+type UserDetails struct {
+	ID       uuid.UUID `json:"id"`
+	Username string    `json:"username"`
+	Private  bool      `json:"private"`
+	Followed bool      `json:"followed"`
+	Blocked  bool      `json:"blocked"`
+}
+
+type UsersDetails struct {
+	UsersDetails []UserDetails `json:"users_details"`
+}
+
+type ContentPreview struct {
+	Content          []string `json:"content"`
+	CloseFriendsOnly bool     `json:"close_friends_only"`
+	Removed          bool     `json:"removed"`
+}
+
+type ReportGroup struct {
+	TargetID        uuid.UUID                    `json:"target_id"`
+	TargetType      string                       `json:"target_type"`
+	OwnerID         uuid.UUID                    `json:"owner_id"`
+	Reports         int64                        `json:"reports"`
+	Reasons         map[model.ReportReason]int64 `json:"reasons"`
+	FirstReportedAt time.Time                    `json:"first_reported_at"`
+	LastReportedAt  time.Time                    `json:"last_reported_at"`
+	Preview         ContentPreview               `json:"preview"`
+}
+
+type ReportView struct {
+	ID          uuid.UUID          `json:"id"`
+	UserID      uuid.UUID          `json:"user_id"`
+	Reason      model.ReportReason `json:"reason"`
+	Description string             `json:"description"`
+	Status      model.ReportStatus `json:"status"`
+	CreatedAt   time.Time          `json:"created_at"`
+	ResolvedAt  *time.Time         `json:"resolved_at,omitempty"`
+}
+
+type PendingReports struct {
+	OwnerID     uuid.UUID   `json:"owner_id"`
+	ReporterIDs []uuid.UUID `json:"reporter_ids"`
+}
+
+type ReportResolve struct {
+	Action model.ModerationActionType `json:"action"`
+}
+
+type StoryReplyCreate struct {
+	Text string `json:"text"`
+}
+
+type StoryReactionCreate struct {
+	Emoji string `json:"emoji"`
+}
+
+type StoryResponseCreate struct {
+	SenderID         uuid.UUID `json:"sender_id"`
+	RecipientID      uuid.UUID `json:"recipient_id"`
+	Type             string    `json:"type"`
+	Text             string    `json:"text"`
+	StoryID          uuid.UUID `json:"story_id"`
+	StoryPreview     string    `json:"story_preview"`
+	CloseFriendsOnly bool      `json:"close_friends_only"`
+}
+
+type StickerCreate struct {
+	Type      model.StickerType `json:"type"`
+	Text      string            `json:"text"`
+	Options   []string          `json:"options"`
+	Latitude  *float64          `json:"latitude"`
+	Longitude *float64          `json:"longitude"`
+	X         float64           `json:"x"`
+	Y         float64           `json:"y"`
+}
+
+type StickerView struct {
+	ID              uuid.UUID         `json:"id"`
+	Type            model.StickerType `json:"type"`
+	Text            string            `json:"text"`
+	Options         []string          `json:"options,omitempty"`
+	MentionedUserID *uuid.UUID        `json:"mentioned_user_id,omitempty"`
+	Latitude        *float64          `json:"latitude,omitempty"`
+	Longitude       *float64          `json:"longitude,omitempty"`
+	X               float64           `json:"x"`
+	Y               float64           `json:"y"`
+}
+
+type PollVoteCreate struct {
+	Option int `json:"option"`
+}
+
+type QuestionAnswerCreate struct {
+	Text string `json:"text"`
+}
+
+type StickerResults struct {
+	Sticker    StickerView `json:"sticker"`
+	Votes      []int64     `json:"votes,omitempty"`
+	TotalVotes int64       `json:"total_votes"`
+	Answers    int64       `json:"answers"`
+}
+
+type QuestionAnswerView struct {
+	ID        uuid.UUID `json:"id"`
+	UserID    uuid.UUID `json:"user_id"`
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type MentionCreate struct {
+	AuthorID  uuid.UUID `json:"author_id"`
+	Usernames []string  `json:"usernames"`
+}
+
+type Mention struct {
+	UserID   uuid.UUID `json:"user_id"`
+	Username string    `json:"username"`
+}
+
+type MentionNotify struct {
+	AuthorID uuid.UUID   `json:"author_id"`
+	UserIDs  []uuid.UUID `json:"user_ids"`
+}
+
+type StoryShareCreate struct {
+	PostID         uuid.UUID  `json:"post_id"`
+	CloseFriends   bool       `json:"close_friends"`
+	AudienceListID *uuid.UUID `json:"audience_list_id"`
+}
+
+// SharedPostView embeds the shared post in a story. Available is false when the
+// post was deleted or the viewer isn't allowed to see it.
+type SharedPostView struct {
+	ID             uuid.UUID `json:"id"`
+	Available      bool      `json:"available"`
+	UserID         uuid.UUID `json:"user_id,omitempty"`
+	Username       string    `json:"username,omitempty"`
+	ProfilePicture string    `json:"profile_picture,omitempty"`
+	Content        []string  `json:"content,omitempty"`
+	Description    string    `json:"description,omitempty"`
+}
+
+type SharedPostsRequest struct {
+	IDs []uuid.UUID `json:"ids"`
+}
+
+type PostView struct {
+	ID             uuid.UUID `json:"id"`
+	UserID         uuid.UUID `json:"user_id"`
+	Username       string    `json:"username"`
+	ProfilePicture string    `json:"profile_picture"`
+	Content        []string  `json:"content"`
+	Description    string    `json:"description"`
+}
+
+type AudienceListRef struct {
+	ID     uuid.UUID `json:"id"`
+	UserID uuid.UUID `json:"user_id"`
+}
+
+type StoryAudienceRequest struct {
+	ViewerID  uuid.UUID         `json:"viewer_id"`
+	AuthorIDs []uuid.UUID       `json:"author_ids"`
+	Lists     []AudienceListRef `json:"lists"`
+}
+
+type StoryAudience struct {
+	HiddenBy []uuid.UUID `json:"hidden_by"`
+	MemberOf []uuid.UUID `json:"member_of"`
+}
+
+type AudienceListView struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+}
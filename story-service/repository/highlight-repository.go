@@ -0,0 +1,316 @@
+package repository
+
+import (
+	"sort"
+	"time"
+
+	"github.com/KristijanPill/Nishtagram/story-service/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type HighlightRepository struct {
+	database *gorm.DB
+}
+
+func NewHighlightRepository(database *gorm.DB) *HighlightRepository {
+	return &HighlightRepository{database: database}
+}
+
+// storiesByPosition preloads the stories of a highlight in album order, leaving
+// out deleted stories.
+func storiesByPosition(db *gorm.DB) *gorm.DB {
+	return db.Select("highlight_stories.*").
+		Joins("JOIN stories ON stories.id = highlight_stories.story_id AND stories.deleted_at IS NULL").
+		Order("highlight_stories.position, highlight_stories.created_at")
+}
+
+func (repository *HighlightRepository) Create(highlight *model.Highlight) (*model.Highlight, error) {
+	result := repository.database.Omit("Stories").Create(highlight)
+
+	return highlight, result.Error
+}
+
+func (repository *HighlightRepository) FindByIDAndUserID(id string, userID string) (*model.Highlight, error) {
+	var highlight model.Highlight
+	result := repository.database.Preload("Stories", storiesByPosition).Preload("Stories.Story").Preload("Stories.Story.Media", mediaByPosition).Preload("Stories.Story.Stickers", stickersByCreation).
+		First(&highlight, "id = ? AND user_id = ?", id, userID)
+
+	return &highlight, result.Error
+}
+
+func (repository *HighlightRepository) FindByTitleAndUserID(title string, userID string) (*model.Highlight, error) {
+	var highlight model.Highlight
+	result := repository.database.First(&highlight, "title = ? AND user_id = ?", title, userID)
+
+	return &highlight, result.Error
+}
+
+func (repository *HighlightRepository) ExistsByTitleAndUserID(title string, userID string) (bool, error) {
+	var highlight model.Highlight
+	result := repository.database.Where("title = ? AND user_id = ?", title, userID).Find(&highlight)
+
+	return result.RowsAffected != 0, result.Error
+}
+
+func (repository *HighlightRepository) FindAllByUserID(userID string) ([]model.Highlight, error) {
+	var highlights []model.Highlight
+	result := repository.database.Preload("Stories", storiesByPosition).Preload("Stories.Story").Preload("Stories.Story.Media", mediaByPosition).Preload("Stories.Story.Stickers", stickersByCreation).
+		Where("user_id = ?", userID).
+		Order("position, created_at").
+		Find(&highlights)
+
+	return highlights, result.Error
+}
+
+func (repository *HighlightRepository) FindAllTitles(userID string) ([]string, error) {
+	var titles []string
+	result := repository.database.Model(&model.Highlight{}).Where("user_id = ?", userID).Order("position, created_at").Pluck("title", &titles)
+
+	return titles, result.Error
+}
+
+func (repository *HighlightRepository) FindNextPosition(userID string) (int, error) {
+	var position int
+	result := repository.database.Model(&model.Highlight{}).Select("COALESCE(MAX(position) + 1, 0)").Where("user_id = ?", userID).Scan(&position)
+
+	return position, result.Error
+}
+
+func (repository *HighlightRepository) Update(highlight *model.Highlight) (*model.Highlight, error) {
+	result := repository.database.Omit("Stories").Save(highlight)
+
+	return highlight, result.Error
+}
+
+// UpdatePositions orders the highlights of the user as given; highlights
+// missing from the list keep their relative order after the listed ones. Only
+// the highlights that moved are updated.
+func (repository *HighlightRepository) UpdatePositions(userID string, highlightIDs []uuid.UUID) error {
+	return repository.database.Transaction(func(tx *gorm.DB) error {
+		var current []model.Highlight
+
+		if err := tx.Select("id", "position").Where("user_id = ?", userID).Order("position, created_at").Find(&current).Error; err != nil {
+			return err
+		}
+
+		for highlightID, position := range movedHighlights(current, highlightIDs) {
+			if err := tx.Model(&model.Highlight{}).Where("id = ?", highlightID).Update("position", position).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// movedHighlights gives the new position of every highlight that has to move
+// so the listed ones come first, in the order given, and the rest follow in
+// their current order. Ids that aren't the user's highlights are skipped.
+func movedHighlights(current []model.Highlight, listed []uuid.UUID) map[uuid.UUID]int {
+	positions := make(map[uuid.UUID]int)
+
+	for _, highlight := range current {
+		positions[highlight.ID] = highlight.Position
+	}
+
+	var ordered []uuid.UUID
+	placed := make(map[uuid.UUID]bool)
+
+	for _, id := range listed {
+		if _, found := positions[id]; found && !placed[id] {
+			placed[id] = true
+			ordered = append(ordered, id)
+		}
+	}
+
+	for _, highlight := range current {
+		if !placed[highlight.ID] {
+			ordered = append(ordered, highlight.ID)
+		}
+	}
+
+	moved := make(map[uuid.UUID]int)
+
+	for position, id := range ordered {
+		if positions[id] != position {
+			moved[id] = position
+		}
+	}
+
+	return moved
+}
+
+func (repository *HighlightRepository) Delete(highlight *model.Highlight) error {
+	return repository.database.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("highlight_id = ?", highlight.ID).Delete(&model.HighlightStory{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Omit("Stories").Delete(highlight).Error
+	})
+}
+
+// AddStories appends the stories to the end of the highlight, skipping the
+// ones it already holds.
+func (repository *HighlightRepository) AddStories(highlightID uuid.UUID, storyIDs []uuid.UUID) error {
+	return repository.database.Transaction(func(tx *gorm.DB) error {
+		var position int
+		err := tx.Model(&model.HighlightStory{}).Select("COALESCE(MAX(position) + 1, 0)").Where("highlight_id = ?", highlightID).Scan(&position).Error
+
+		if err != nil {
+			return err
+		}
+
+		for i, storyID := range storyIDs {
+			highlightStory := &model.HighlightStory{HighlightID: highlightID, StoryID: storyID, Position: position + i}
+
+			if err := tx.Omit("Story").Clauses(clause.OnConflict{DoNothing: true}).Create(highlightStory).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (repository *HighlightRepository) RemoveStory(highlight *model.Highlight, storyID uuid.UUID) error {
+	return repository.database.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("highlight_id = ? AND story_id = ?", highlight.ID, storyID).Delete(&model.HighlightStory{})
+
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		if highlight.CoverStoryID != nil && *highlight.CoverStoryID == storyID {
+			return tx.Model(&model.Highlight{}).Where("id = ?", highlight.ID).Update("cover_story_id", nil).Error
+		}
+
+		return nil
+	})
+}
+
+// UpdateStoryPositions orders the stories of the highlight as given; stories
+// missing from the list keep their relative order after the listed ones.
+func (repository *HighlightRepository) UpdateStoryPositions(highlightID uuid.UUID, storyIDs []uuid.UUID) error {
+	return repository.database.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.HighlightStory{}).Where("highlight_id = ?", highlightID).Update("position", gorm.Expr("position + ?", len(storyIDs))).Error; err != nil {
+			return err
+		}
+
+		for position, storyID := range storyIDs {
+			if err := tx.Model(&model.HighlightStory{}).Where("highlight_id = ? AND story_id = ?", highlightID, storyID).Update("position", position).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// legacyStoryHighlight is a row of the story_highlights table, which kept the
+// highlight name with every highlighted story.
+type legacyStoryHighlight struct {
+	UserID        uuid.UUID
+	StoryID       uuid.UUID
+	HighlightName string
+	CreatedAt     time.Time
+}
+
+const legacyHighlightTitle = "Highlights"
+
+// MigrateStoryHighlights turns the highlight names that used to be stored with
+// every highlighted story into highlight albums and drops the old table.
+func (repository *HighlightRepository) MigrateStoryHighlights() error {
+	migrator := repository.database.Migrator()
+
+	if !migrator.HasTable("story_highlights") {
+		return nil
+	}
+
+	return repository.database.Transaction(func(tx *gorm.DB) error {
+		var rows []legacyStoryHighlight
+
+		if err := tx.Table("story_highlights").Find(&rows).Error; err != nil {
+			return err
+		}
+
+		for _, highlight := range groupLegacyHighlights(rows) {
+			err := tx.Omit("Stories").Clauses(clause.OnConflict{DoNothing: true}).Create(&highlight).Error
+
+			if err != nil {
+				return err
+			}
+
+			var stored model.Highlight
+
+			if err := tx.Where("user_id = ? AND title = ?", highlight.UserID, highlight.Title).First(&stored).Error; err != nil {
+				return err
+			}
+
+			for i := range highlight.Stories {
+				highlight.Stories[i].HighlightID = stored.ID
+			}
+
+			if err := tx.Omit("Story").Clauses(clause.OnConflict{DoNothing: true}).Create(&highlight.Stories).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Migrator().DropTable("story_highlights")
+	})
+}
+
+// groupLegacyHighlights makes an album of every highlight name of a user, with
+// the stories in the order they were highlighted. Stories highlighted without
+// a name go to an album called Highlights.
+func groupLegacyHighlights(rows []legacyStoryHighlight) []model.Highlight {
+	sorted := append([]legacyStoryHighlight(nil), rows...)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].CreatedAt.Before(sorted[j].CreatedAt)
+	})
+
+	type album struct {
+		userID uuid.UUID
+		title  string
+	}
+
+	var highlights []model.Highlight
+	indexes := make(map[album]int)
+
+	for _, row := range sorted {
+		title := row.HighlightName
+
+		if title == "" {
+			title = legacyHighlightTitle
+		}
+
+		index, found := indexes[album{row.UserID, title}]
+
+		if !found {
+			index = len(highlights)
+			indexes[album{row.UserID, title}] = index
+			highlights = append(highlights, model.Highlight{
+				UserID:    row.UserID,
+				Title:     title,
+				CreatedAt: row.CreatedAt,
+				UpdatedAt: row.CreatedAt,
+			})
+		}
+
+		highlight := &highlights[index]
+		highlight.Stories = append(highlight.Stories, model.HighlightStory{
+			StoryID:   row.StoryID,
+			Position:  len(highlight.Stories),
+			CreatedAt: row.CreatedAt,
+		})
+	}
+
+	return highlights
+}
@@ -0,0 +1,189 @@
+package repository
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/KristijanPill/Nishtagram/story-service/model"
+	"github.com/google/uuid"
+)
+
+func TestMovedHighlights(t *testing.T) {
+	trips := uuid.MustParse("00000000-0000-0000-0000-00000000000a")
+	food := uuid.MustParse("00000000-0000-0000-0000-00000000000b")
+	pets := uuid.MustParse("00000000-0000-0000-0000-00000000000c")
+	foreign := uuid.MustParse("00000000-0000-0000-0000-0000000000ff")
+
+	highlights := func(positions ...int) []model.Highlight {
+		ids := []uuid.UUID{trips, food, pets}
+		var current []model.Highlight
+
+		for i, position := range positions {
+			current = append(current, model.Highlight{ID: ids[i], Position: position})
+		}
+
+		return current
+	}
+
+	tests := []struct {
+		name    string
+		current []model.Highlight
+		listed  []uuid.UUID
+		want    map[uuid.UUID]int
+	}{
+		{
+			name:    "order unchanged",
+			current: highlights(0, 1, 2),
+			listed:  []uuid.UUID{trips, food, pets},
+			want:    map[uuid.UUID]int{},
+		},
+		{
+			name:    "last highlight moved to the front",
+			current: highlights(0, 1, 2),
+			listed:  []uuid.UUID{pets, trips, food},
+			want:    map[uuid.UUID]int{pets: 0, trips: 1, food: 2},
+		},
+		{
+			name:    "first two swapped leave the third in place",
+			current: highlights(0, 1, 2),
+			listed:  []uuid.UUID{food, trips},
+			want:    map[uuid.UUID]int{food: 0, trips: 1},
+		},
+		{
+			name:    "migrated albums all at position 0 get their own",
+			current: highlights(0, 0, 0),
+			listed:  nil,
+			want:    map[uuid.UUID]int{food: 1, pets: 2},
+		},
+		{
+			name:    "gaps left by deleted highlights are closed",
+			current: highlights(0, 3, 7),
+			listed:  []uuid.UUID{trips},
+			want:    map[uuid.UUID]int{food: 1, pets: 2},
+		},
+		{
+			name:    "someone else's highlight is ignored",
+			current: highlights(0, 1),
+			listed:  []uuid.UUID{foreign, food, food},
+			want:    map[uuid.UUID]int{food: 0, trips: 1},
+		},
+		{
+			name:    "user without highlights",
+			current: nil,
+			listed:  []uuid.UUID{trips},
+			want:    map[uuid.UUID]int{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := movedHighlights(test.current, test.listed); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("movedHighlights() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestGroupLegacyHighlights(t *testing.T) {
+	alice := uuid.MustParse("00000000-0000-0000-0000-0000000000a1")
+	bob := uuid.MustParse("00000000-0000-0000-0000-0000000000b0")
+	first := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	second := uuid.MustParse("00000000-0000-0000-0000-000000000002")
+	third := uuid.MustParse("00000000-0000-0000-0000-000000000003")
+
+	monday := time.Date(2021, 5, 3, 10, 0, 0, 0, time.UTC)
+	tuesday := monday.AddDate(0, 0, 1)
+	wednesday := monday.AddDate(0, 0, 2)
+
+	tests := []struct {
+		name string
+		rows []legacyStoryHighlight
+		want []model.Highlight
+	}{
+		{
+			name: "no rows",
+			rows: nil,
+			want: nil,
+		},
+		{
+			name: "stories are ordered by when they were highlighted",
+			rows: []legacyStoryHighlight{
+				{UserID: alice, StoryID: third, HighlightName: "Trips", CreatedAt: wednesday},
+				{UserID: alice, StoryID: first, HighlightName: "Trips", CreatedAt: monday},
+				{UserID: alice, StoryID: second, HighlightName: "Trips", CreatedAt: tuesday},
+			},
+			want: []model.Highlight{
+				{
+					UserID:    alice,
+					Title:     "Trips",
+					CreatedAt: monday,
+					UpdatedAt: monday,
+					Stories: []model.HighlightStory{
+						{StoryID: first, Position: 0, CreatedAt: monday},
+						{StoryID: second, Position: 1, CreatedAt: tuesday},
+						{StoryID: third, Position: 2, CreatedAt: wednesday},
+					},
+				},
+			},
+		},
+		{
+			name: "unnamed highlights go to the default album",
+			rows: []legacyStoryHighlight{
+				{UserID: alice, StoryID: first, HighlightName: "", CreatedAt: monday},
+				{UserID: alice, StoryID: second, HighlightName: "Highlights", CreatedAt: tuesday},
+			},
+			want: []model.Highlight{
+				{
+					UserID:    alice,
+					Title:     "Highlights",
+					CreatedAt: monday,
+					UpdatedAt: monday,
+					Stories: []model.HighlightStory{
+						{StoryID: first, Position: 0, CreatedAt: monday},
+						{StoryID: second, Position: 1, CreatedAt: tuesday},
+					},
+				},
+			},
+		},
+		{
+			name: "albums are kept per user and name",
+			rows: []legacyStoryHighlight{
+				{UserID: bob, StoryID: third, HighlightName: "Trips", CreatedAt: wednesday},
+				{UserID: alice, StoryID: second, HighlightName: "Food", CreatedAt: tuesday},
+				{UserID: alice, StoryID: first, HighlightName: "Trips", CreatedAt: monday},
+			},
+			want: []model.Highlight{
+				{
+					UserID:    alice,
+					Title:     "Trips",
+					CreatedAt: monday,
+					UpdatedAt: monday,
+					Stories:   []model.HighlightStory{{StoryID: first, Position: 0, CreatedAt: monday}},
+				},
+				{
+					UserID:    alice,
+					Title:     "Food",
+					CreatedAt: tuesday,
+					UpdatedAt: tuesday,
+					Stories:   []model.HighlightStory{{StoryID: second, Position: 0, CreatedAt: tuesday}},
+				},
+				{
+					UserID:    bob,
+					Title:     "Trips",
+					CreatedAt: wednesday,
+					UpdatedAt: wednesday,
+					Stories:   []model.HighlightStory{{StoryID: third, Position: 0, CreatedAt: wednesday}},
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := groupLegacyHighlights(test.rows); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("groupLegacyHighlights() = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
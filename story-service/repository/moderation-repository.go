@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/KristijanPill/Nishtagram/story-service/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ModerationRepository struct {
+	database *gorm.DB
+}
+
+type ReportGroup struct {
+	StoryID         uuid.UUID
+	Reports         int64
+	FirstReportedAt time.Time
+	LastReportedAt  time.Time
+}
+
+type ReasonCount struct {
+	StoryID uuid.UUID
+	Reason  model.ReportReason
+	Reports int64
+}
+
+func NewModerationRepository(database *gorm.DB) *ModerationRepository {
+	return &ModerationRepository{database: database}
+}
+
+// MigrateReports fills in the reason and the time of reports made before they
+// were recorded, so those reports can be grouped in the moderation queue.
+func (repository *ModerationRepository) MigrateReports() error {
+	result := repository.database.Model(&model.Report{}).Where("reason IS NULL OR reason = ''").Update("reason", model.OTHER)
+
+	if result.Error != nil {
+		return result.Error
+	}
+
+	result = repository.database.Model(&model.Report{}).Where("created_at IS NULL").Update("created_at", time.Now())
+
+	return result.Error
+}
+
+func (repository *ModerationRepository) FindReportGroups(status model.ReportStatus, limit int) ([]ReportGroup, error) {
+	var groups []ReportGroup
+	result := repository.database.Model(&model.Report{}).
+		Select("story_id, COUNT(*) AS reports, MIN(created_at) AS first_reported_at, MAX(created_at) AS last_reported_at").
+		Where("status = ?", status).
+		Group("story_id").
+		Order("reports desc, last_reported_at desc").
+		Limit(limit).
+		Scan(&groups)
+
+	return groups, result.Error
+}
+
+func (repository *ModerationRepository) FindReasonCounts(storyIDs []uuid.UUID, status model.ReportStatus) ([]ReasonCount, error) {
+	var counts []ReasonCount
+	result := repository.database.Model(&model.Report{}).
+		Select("story_id, reason, COUNT(*) AS reports").
+		Where("story_id IN ? AND status = ?", storyIDs, status).
+		Group("story_id, reason").
+		Scan(&counts)
+
+	return counts, result.Error
+}
+
+func (repository *ModerationRepository) FindReportsByStoryID(storyID string) ([]model.Report, error) {
+	var reports []model.Report
+	result := repository.database.Where("story_id = ?", storyID).Order("created_at desc").Find(&reports)
+
+	return reports, result.Error
+}
+
+func (repository *ModerationRepository) FindPendingReporterIDs(storyID string) ([]uuid.UUID, error) {
+	var reporterIDs []uuid.UUID
+	result := repository.database.Model(&model.Report{}).Distinct("user_id").Where("story_id = ? AND status = ?", storyID, model.PENDING).Pluck("user_id", &reporterIDs)
+
+	return reporterIDs, result.Error
+}
+
+func (repository *ModerationRepository) ResolvePendingReports(storyID string, status model.ReportStatus) error {
+	result := repository.database.Model(&model.Report{}).Where("story_id = ? AND status = ?", storyID, model.PENDING).
+		Updates(map[string]interface{}{"status": status, "resolved_at": time.Now()})
+
+	return result.Error
+}
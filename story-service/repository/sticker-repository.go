@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"github.com/KristijanPill/Nishtagram/story-service/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type StickerRepository struct {
+	database *gorm.DB
+}
+
+type OptionVotes struct {
+	StickerID uuid.UUID
+	Option    int
+	Votes     int64
+}
+
+type StickerAnswers struct {
+	StickerID uuid.UUID
+	Answers   int64
+}
+
+func NewStickerRepository(database *gorm.DB) *StickerRepository {
+	return &StickerRepository{database: database}
+}
+
+// stickersByCreation preloads the stickers of a story in the order they were
+// placed on it.
+func stickersByCreation(db *gorm.DB) *gorm.DB {
+	return db.Order("stickers.created_at, stickers.id")
+}
+
+func (repository *StickerRepository) FindByID(id string) (*model.Sticker, error) {
+	var sticker model.Sticker
+	result := repository.database.First(&sticker, "id = ?", id)
+
+	return &sticker, result.Error
+}
+
+func (repository *StickerRepository) FindByStoryID(storyID string) ([]model.Sticker, error) {
+	var stickers []model.Sticker
+	result := repository.database.Scopes(stickersByCreation).Where("story_id = ?", storyID).Find(&stickers)
+
+	return stickers, result.Error
+}
+
+// CreateVote stores the vote unless the user has already voted on the poll, in
+// which case it reports false.
+func (repository *StickerRepository) CreateVote(vote *model.PollVote) (bool, error) {
+	result := repository.database.Clauses(clause.OnConflict{DoNothing: true}).Create(vote)
+
+	return result.RowsAffected != 0, result.Error
+}
+
+func (repository *StickerRepository) CountVotes(stickerIDs []uuid.UUID) ([]OptionVotes, error) {
+	var votes []OptionVotes
+
+	if len(stickerIDs) == 0 {
+		return votes, nil
+	}
+
+	result := repository.database.Model(&model.PollVote{}).
+		Select("sticker_id, option, COUNT(*) AS votes").
+		Where("sticker_id IN ?", stickerIDs).
+		Group("sticker_id, option").
+		Scan(&votes)
+
+	return votes, result.Error
+}
+
+func (repository *StickerRepository) CreateAnswer(answer *model.QuestionAnswer) (*model.QuestionAnswer, error) {
+	result := repository.database.Create(answer)
+
+	return answer, result.Error
+}
+
+func (repository *StickerRepository) CountAnswers(stickerIDs []uuid.UUID) ([]StickerAnswers, error) {
+	var answers []StickerAnswers
+
+	if len(stickerIDs) == 0 {
+		return answers, nil
+	}
+
+	result := repository.database.Model(&model.QuestionAnswer{}).
+		Select("sticker_id, COUNT(*) AS answers").
+		Where("sticker_id IN ?", stickerIDs).
+		Group("sticker_id").
+		Scan(&answers)
+
+	return answers, result.Error
+}
+
+func (repository *StickerRepository) FindAnswers(stickerID string, page int, size int) ([]model.QuestionAnswer, error) {
+	var answers []model.QuestionAnswer
+	result := repository.database.Where("sticker_id = ?", stickerID).
+		Order("created_at desc").
+		Offset(page * size).Limit(size).
+		Find(&answers)
+
+	return answers, result.Error
+}
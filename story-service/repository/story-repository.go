@@ -4,6 +4,8 @@ import (
 	"time"
 
 	"github.com/KristijanPill/Nishtagram/story-service/model"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"gorm.io/gorm"
 )
 
@@ -15,6 +17,12 @@ func NewStoryRepository(database *gorm.DB) *StoryRepository {
 	return &StoryRepository{database: database}
 }
 
+// mediaByPosition preloads the media of a story in the order they were
+// uploaded.
+func mediaByPosition(db *gorm.DB) *gorm.DB {
+	return db.Order("story_media.position")
+}
+
 func (repository *StoryRepository) Create(story *model.Story) (*model.Story, error) {
 	result := repository.database.Create(story)
 
@@ -23,39 +31,119 @@ func (repository *StoryRepository) Create(story *model.Story) (*model.Story, err
 
 func (repository *StoryRepository) FindByID(storyID string) (*model.Story, error) {
 	var story model.Story
-	result := repository.database.First(&story, "id = ?", storyID)
+	result := repository.database.Preload("Media", mediaByPosition).Preload("Stickers", stickersByCreation).First(&story, "id = ?", storyID)
+
+	return &story, result.Error
+}
+
+func (repository *StoryRepository) FindByIDUnscoped(storyID string) (*model.Story, error) {
+	var story model.Story
+	result := repository.database.Unscoped().First(&story, "id = ?", storyID)
 
 	return &story, result.Error
 }
 
 func (repository *StoryRepository) FindByUserIDNotCloseFriends(userID string) ([]model.Story, error) {
 	var stories []model.Story
-	result := repository.database.Where("user_id = ? AND close_friends_only = ? AND created_at >= ?", userID, false, time.Now().Add(-24*time.Hour)).Order("created_at desc").Find(&stories)
+	result := repository.database.Preload("Media", mediaByPosition).Preload("Stickers", stickersByCreation).Where("user_id = ? AND close_friends_only = ? AND created_at >= ?", userID, false, time.Now().Add(-24*time.Hour)).Order("created_at desc").Find(&stories)
 
 	return stories, result.Error
 }
 
 func (repository *StoryRepository) FindByUserIDCloseFriends(userID string) ([]model.Story, error) {
 	var stories []model.Story
-	result := repository.database.Where("user_id = ? AND created_at >= ?", userID, time.Now().Add(-24*time.Hour)).Order("created_at desc").Find(&stories)
+	result := repository.database.Preload("Media", mediaByPosition).Preload("Stickers", stickersByCreation).Where("user_id = ? AND created_at >= ?", userID, time.Now().Add(-24*time.Hour)).Order("created_at desc").Find(&stories)
 
 	return stories, result.Error
 }
 
 func (repository *StoryRepository) FindByUserID(userID string) ([]model.Story, error) {
 	var stories []model.Story
-	result := repository.database.Where("user_id = ? AND created_at >= ?", userID, time.Now().Add(-24*time.Hour)).Order("created_at desc").Find(&stories)
+	result := repository.database.Preload("Media", mediaByPosition).Preload("Stickers", stickersByCreation).Where("user_id = ? AND created_at >= ?", userID, time.Now().Add(-24*time.Hour)).Order("created_at desc").Find(&stories)
+
+	return stories, result.Error
+}
+
+// FindActiveByUserIDs finds the active stories of the given authors, oldest
+// first. Close friends stories are only included for the authors in
+// closeFriendOf.
+func (repository *StoryRepository) FindActiveByUserIDs(userIDs []uuid.UUID, closeFriendOf []uuid.UUID) ([]model.Story, error) {
+	var stories []model.Story
+
+	if len(userIDs) == 0 {
+		return stories, nil
+	}
+
+	query := repository.database.Preload("Media", mediaByPosition).Preload("Stickers", stickersByCreation).Where("user_id IN ? AND created_at >= ?", userIDs, time.Now().Add(-24*time.Hour))
+
+	if len(closeFriendOf) == 0 {
+		query = query.Where("close_friends_only = ?", false)
+	} else {
+		query = query.Where("close_friends_only = ? OR user_id IN ?", false, closeFriendOf)
+	}
+
+	result := query.Order("created_at asc").Find(&stories)
 
 	return stories, result.Error
 }
 
 func (repository *StoryRepository) FindAllByUserID(userID string) ([]model.Story, error) {
 	var stories []model.Story
-	result := repository.database.Where("user_id = ?", userID).Order("created_at desc").Find(&stories)
+	result := repository.database.Preload("Media", mediaByPosition).Preload("Stickers", stickersByCreation).Where("user_id = ?", userID).Order("created_at desc").Find(&stories)
 
 	return stories, result.Error
 }
 
+func (repository *StoryRepository) FindArchivedByUserID(userID string, page int, size int) ([]model.Story, error) {
+	var stories []model.Story
+	result := repository.database.Preload("Media", mediaByPosition).Preload("Stickers", stickersByCreation).Where("user_id = ? AND archived_at IS NOT NULL AND media_deleted_at IS NULL", userID).
+		Order("created_at desc").
+		Offset(page * size).Limit(size).
+		Find(&stories)
+
+	return stories, result.Error
+}
+
+func (repository *StoryRepository) ArchiveExpired(expiredBefore time.Time) (int64, error) {
+	result := repository.database.Model(&model.Story{}).
+		Where("archived_at IS NULL AND created_at < ?", expiredBefore).
+		Update("archived_at", time.Now())
+
+	return result.RowsAffected, result.Error
+}
+
+// FindWithExpiredMedia finds the stories whose media are past retention: stories
+// archived before the cutoff that aren't highlighted and stories deleted before
+// the cutoff.
+func (repository *StoryRepository) FindWithExpiredMedia(cutoff time.Time, limit int) ([]model.Story, error) {
+	var stories []model.Story
+	result := repository.database.Unscoped().
+		Where("media_deleted_at IS NULL").
+		Where("deleted_at < ? OR (archived_at < ? AND NOT EXISTS "+
+			"(SELECT 1 FROM highlight_stories WHERE highlight_stories.story_id = stories.id))", cutoff, cutoff).
+		Order("created_at asc").
+		Limit(limit).
+		Find(&stories)
+
+	return stories, result.Error
+}
+
+// MarkMediaDeleted clears the content and media of stories whose files were
+// deleted, so no view links to files that are gone.
+func (repository *StoryRepository) MarkMediaDeleted(storyIDs []uuid.UUID) error {
+	return repository.database.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("story_id IN ?", storyIDs).Delete(&model.StoryMedia{}).Error
+
+		if err != nil {
+			return err
+		}
+
+		return tx.Unscoped().Model(&model.Story{}).
+			Where("id IN ?", storyIDs).
+			Updates(map[string]interface{}{"media_deleted_at": time.Now(), "content": pq.StringArray{}}).Error
+	})
+}
+
 func (repository *StoryRepository) Delete(story *model.Story) error {
 	result := repository.database.Delete(story)
 
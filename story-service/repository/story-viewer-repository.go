@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"github.com/KristijanPill/Nishtagram/story-service/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type StoryViewerRepository struct {
+	database *gorm.DB
+}
+
+func NewStoryViewerRepository(database *gorm.DB) *StoryViewerRepository {
+	return &StoryViewerRepository{database: database}
+}
+
+func (repository *StoryViewerRepository) Create(viewer *model.StoryViewer) error {
+	result := repository.database.Clauses(clause.OnConflict{DoNothing: true}).Create(viewer)
+
+	return result.Error
+}
+
+func (repository *StoryViewerRepository) FindByStoryID(storyID uuid.UUID, page int, size int) ([]model.StoryViewer, error) {
+	var viewers []model.StoryViewer
+	result := repository.database.Where("story_id = ?", storyID).
+		Order("created_at desc").
+		Offset(page * size).Limit(size).
+		Find(&viewers)
+
+	return viewers, result.Error
+}
+
+func (repository *StoryViewerRepository) CountByStoryID(storyID uuid.UUID) (int64, error) {
+	var count int64
+	result := repository.database.Model(&model.StoryViewer{}).Where("story_id = ?", storyID).Count(&count)
+
+	return count, result.Error
+}
+
+func (repository *StoryViewerRepository) FindSeenStoryIDs(viewerID uuid.UUID, storyIDs []uuid.UUID) ([]uuid.UUID, error) {
+	var seen []uuid.UUID
+
+	if len(storyIDs) == 0 {
+		return seen, nil
+	}
+
+	result := repository.database.Model(&model.StoryViewer{}).
+		Where("viewer_id = ? AND story_id IN ?", viewerID, storyIDs).
+		Pluck("story_id", &seen)
+
+	return seen, result.Error
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/KristijanPill/Nishtagram/story-service/helpers"
+	"github.com/KristijanPill/Nishtagram/story-service/payload"
+)
+
+type CampaignService struct{}
+
+func NewCampaignService() *CampaignService {
+	return &CampaignService{}
+}
+
+// FindSponsored asks post-service, which owns the campaigns, for the sponsored
+// stories to show in the viewer's story tray. The media were copied into the
+// campaign, so sponsored stories outlive the stories they promote.
+func (service *CampaignService) FindSponsored(count int, token string) ([]payload.SponsoredStoryView, error) {
+	requestURL := fmt.Sprintf("http://%s:%s/internal/campaigns/stories?count=%d",
+		os.Getenv("POST_SERVICE_DOMAIN"), os.Getenv("POST_SERVICE_PORT"), count)
+	client := &http.Client{}
+	req, err := http.NewRequest(http.MethodGet, requestURL, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Authorization", "Bearer "+token)
+
+	response, err := client.Do(req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("post-service responded with %s", response.Status)
+	}
+
+	var sponsoredStories []payload.SponsoredStory
+
+	if err := helpers.FromJSON(&sponsoredStories, response.Body); err != nil {
+		return nil, err
+	}
+
+	var storiesView = []payload.SponsoredStoryView{}
+
+	for _, sponsoredStory := range sponsoredStories {
+		storiesView = append(storiesView, payload.SponsoredStoryView{
+			StoryView: payload.StoryView{
+				ID:      sponsoredStory.StoryID,
+				Content: sponsoredStory.Content,
+			},
+			CampaignID: sponsoredStory.CampaignID,
+			AgentID:    sponsoredStory.AgentID,
+			Sponsored:  true,
+		})
+	}
+
+	return storiesView, nil
+}
@@ -0,0 +1,448 @@
+package service
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/KristijanPill/Nishtagram/story-service/model"
+	"github.com/KristijanPill/Nishtagram/story-service/payload"
+	"github.com/KristijanPill/Nishtagram/story-service/repository"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidHighlightTitle = errors.New("highlight title must not be empty")
+	ErrHighlightTitleTaken   = errors.New("a highlight with this title already exists")
+	ErrEmptyHighlight        = errors.New("a highlight needs at least one story")
+	ErrCoverNotInHighlight   = errors.New("the cover story must be in the highlight")
+)
+
+type HighlightService struct {
+	repository      *repository.HighlightRepository
+	storyRepository *repository.StoryRepository
+}
+
+func NewHighlightService(repository *repository.HighlightRepository, storyRepository *repository.StoryRepository) *HighlightService {
+	return &HighlightService{repository: repository, storyRepository: storyRepository}
+}
+
+func (service *HighlightService) Create(dto *payload.HighlightCreate, loggedInUserID uuid.UUID) (*payload.HighlightView, error) {
+	title, err := service.checkTitle(dto.Title, loggedInUserID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(dto.StoryIDs) == 0 {
+		return nil, ErrEmptyHighlight
+	}
+
+	if err := service.checkStories(dto.StoryIDs, loggedInUserID); err != nil {
+		return nil, err
+	}
+
+	if dto.CoverStoryID != nil && !containsID(dto.StoryIDs, *dto.CoverStoryID) {
+		return nil, ErrCoverNotInHighlight
+	}
+
+	position, err := service.repository.FindNextPosition(loggedInUserID.String())
+
+	if err != nil {
+		return nil, err
+	}
+
+	highlight, err := service.repository.Create(&model.Highlight{
+		UserID:       loggedInUserID,
+		Title:        title,
+		CoverStoryID: dto.CoverStoryID,
+		Position:     position,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := service.repository.AddStories(highlight.ID, dto.StoryIDs); err != nil {
+		return nil, err
+	}
+
+	return service.findView(highlight.ID, loggedInUserID)
+}
+
+func (service *HighlightService) FindAllByLoggedInUser(loggedInUserID uuid.UUID, token string) ([]payload.HighlightView, error) {
+	highlights, err := service.repository.FindAllByUserID(loggedInUserID.String())
+
+	if err != nil {
+		return nil, err
+	}
+
+	var highlightsView = []payload.HighlightView{}
+
+	for i := range highlights {
+		highlightsView = append(highlightsView, toHighlightView(&highlights[i], true, nil))
+	}
+
+	fillHighlightSharedPosts(highlightsView, loggedInUserID, token)
+
+	return highlightsView, nil
+}
+
+// FindByUser lists the highlights on someone's profile. Private accounts only
+// show them to followers, and close friends stories are left out unless the
+// viewer is a close friend of the owner, as are stories hidden from the viewer
+// or shared with a list they aren't on. Highlights without a visible story are
+// left out too.
+func (service *HighlightService) FindByUser(userID uuid.UUID, loggedInUserID uuid.UUID, token string) ([]payload.HighlightView, error) {
+	if userID == loggedInUserID {
+		return service.FindAllByLoggedInUser(userID, token)
+	}
+
+	if err := checkAuthor(userID, token); err != nil {
+		return nil, err
+	}
+
+	var closeFriend bool
+
+	if loggedInUserID != uuid.Nil {
+		followStatus, err := fetchFollowStatus(userID, token)
+
+		if err != nil {
+			return nil, err
+		}
+
+		closeFriend = followStatus.CloseFriend
+	}
+
+	highlights, err := service.repository.FindAllByUserID(userID.String())
+
+	if err != nil {
+		return nil, err
+	}
+
+	var stories []model.Story
+
+	for _, highlight := range highlights {
+		for _, highlightStory := range highlight.Stories {
+			stories = append(stories, highlightStory.Story)
+		}
+	}
+
+	audience, err := fetchStoryAudience(loggedInUserID, stories)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var highlightsView = []payload.HighlightView{}
+
+	for i := range highlights {
+		highlightView := toHighlightView(&highlights[i], closeFriend, audience)
+
+		if highlightView.NumberOfStories != 0 {
+			highlightsView = append(highlightsView, highlightView)
+		}
+	}
+
+	fillHighlightSharedPosts(highlightsView, loggedInUserID, token)
+
+	return highlightsView, nil
+}
+
+// Update renames the highlight and sets its cover. Without a cover story the
+// first story of the highlight is used as the cover.
+func (service *HighlightService) Update(highlightID uuid.UUID, dto *payload.HighlightUpdate, loggedInUserID uuid.UUID) (*payload.HighlightView, error) {
+	highlight, err := service.repository.FindByIDAndUserID(highlightID.String(), loggedInUserID.String())
+
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSpace(dto.Title) != highlight.Title {
+		highlight.Title, err = service.checkTitle(dto.Title, loggedInUserID)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if dto.CoverStoryID != nil && !containsID(highlightStoryIDs(highlight), *dto.CoverStoryID) {
+		return nil, ErrCoverNotInHighlight
+	}
+
+	highlight.CoverStoryID = dto.CoverStoryID
+
+	if _, err := service.repository.Update(highlight); err != nil {
+		return nil, err
+	}
+
+	highlightView := toHighlightView(highlight, true, nil)
+
+	return &highlightView, nil
+}
+
+func (service *HighlightService) Reorder(dto *payload.HighlightOrder, loggedInUserID uuid.UUID) error {
+	return service.repository.UpdatePositions(loggedInUserID.String(), dto.IDs)
+}
+
+// Delete removes the highlight only; its stories stay in the archive.
+func (service *HighlightService) Delete(highlightID uuid.UUID, loggedInUserID uuid.UUID) error {
+	highlight, err := service.repository.FindByIDAndUserID(highlightID.String(), loggedInUserID.String())
+
+	if err != nil {
+		return err
+	}
+
+	return service.repository.Delete(highlight)
+}
+
+func (service *HighlightService) AddStory(highlightID uuid.UUID, storyID uuid.UUID, loggedInUserID uuid.UUID) (*payload.HighlightView, error) {
+	highlight, err := service.repository.FindByIDAndUserID(highlightID.String(), loggedInUserID.String())
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := service.checkStories([]uuid.UUID{storyID}, loggedInUserID); err != nil {
+		return nil, err
+	}
+
+	if err := service.repository.AddStories(highlight.ID, []uuid.UUID{storyID}); err != nil {
+		return nil, err
+	}
+
+	return service.findView(highlight.ID, loggedInUserID)
+}
+
+// RemoveStory takes the story out of the highlight; it stays in the archive.
+// Removing the last story deletes the highlight.
+func (service *HighlightService) RemoveStory(highlightID uuid.UUID, storyID uuid.UUID, loggedInUserID uuid.UUID) error {
+	highlight, err := service.repository.FindByIDAndUserID(highlightID.String(), loggedInUserID.String())
+
+	if err != nil {
+		return err
+	}
+
+	storyIDs := highlightStoryIDs(highlight)
+
+	if len(storyIDs) == 1 && storyIDs[0] == storyID {
+		return service.repository.Delete(highlight)
+	}
+
+	return service.repository.RemoveStory(highlight, storyID)
+}
+
+func (service *HighlightService) ReorderStories(highlightID uuid.UUID, dto *payload.HighlightOrder, loggedInUserID uuid.UUID) (*payload.HighlightView, error) {
+	highlight, err := service.repository.FindByIDAndUserID(highlightID.String(), loggedInUserID.String())
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := service.repository.UpdateStoryPositions(highlight.ID, dto.IDs); err != nil {
+		return nil, err
+	}
+
+	return service.findView(highlight.ID, loggedInUserID)
+}
+
+// HighlightStory adds the story to the highlight with the given name, creating
+// the highlight if needed. It keeps clients that only know highlight names
+// working.
+func (service *HighlightService) HighlightStory(dto *payload.StoryHighlightCreate) error {
+	title := strings.TrimSpace(dto.HighlightName)
+
+	if title == "" {
+		return ErrInvalidHighlightTitle
+	}
+
+	highlight, err := service.repository.FindByTitleAndUserID(title, dto.UserID.String())
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		_, err = service.Create(&payload.HighlightCreate{Title: title, StoryIDs: []uuid.UUID{dto.StoryID}}, dto.UserID)
+
+		return err
+	}
+
+	if err != nil {
+		return err
+	}
+
+	_, err = service.AddStory(highlight.ID, dto.StoryID, dto.UserID)
+
+	return err
+}
+
+func (service *HighlightService) GetAllHighlightNames(loggedInUserID uuid.UUID) ([]string, error) {
+	return service.repository.FindAllTitles(loggedInUserID.String())
+}
+
+// GetAllHighlightedStories lists every highlighted story together with the name
+// of its highlight.
+func (service *HighlightService) GetAllHighlightedStories(loggedInUserID uuid.UUID, token string) ([]payload.StoryHighlightView, error) {
+	highlights, err := service.repository.FindAllByUserID(loggedInUserID.String())
+
+	if err != nil {
+		return nil, err
+	}
+
+	var highlightsView []payload.StoryHighlightView
+
+	for _, highlight := range highlights {
+		for _, highlightStory := range highlight.Stories {
+			highlightsView = append(highlightsView, payload.StoryHighlightView{
+				StoryView:     toStoryView(&highlightStory.Story),
+				HighlightName: highlight.Title,
+			})
+		}
+	}
+
+	var sharedPosts []*payload.SharedPostView
+
+	for _, highlightView := range highlightsView {
+		if highlightView.StoryView.SharedPost != nil {
+			sharedPosts = append(sharedPosts, highlightView.StoryView.SharedPost)
+		}
+	}
+
+	fillSharedPosts(sharedPosts, token)
+
+	return highlightsView, nil
+}
+
+func (service *HighlightService) findView(highlightID uuid.UUID, loggedInUserID uuid.UUID) (*payload.HighlightView, error) {
+	highlight, err := service.repository.FindByIDAndUserID(highlightID.String(), loggedInUserID.String())
+
+	if err != nil {
+		return nil, err
+	}
+
+	highlightView := toHighlightView(highlight, true, nil)
+
+	return &highlightView, nil
+}
+
+func (service *HighlightService) checkTitle(title string, loggedInUserID uuid.UUID) (string, error) {
+	title = strings.TrimSpace(title)
+
+	if title == "" {
+		return "", ErrInvalidHighlightTitle
+	}
+
+	exists, err := service.repository.ExistsByTitleAndUserID(title, loggedInUserID.String())
+
+	if err != nil {
+		return "", err
+	}
+
+	if exists {
+		return "", ErrHighlightTitleTaken
+	}
+
+	return title, nil
+}
+
+// checkStories makes sure the user owns the stories and that their media
+// haven't been deleted yet.
+func (service *HighlightService) checkStories(storyIDs []uuid.UUID, loggedInUserID uuid.UUID) error {
+	for _, storyID := range storyIDs {
+		story, err := service.storyRepository.FindByID(storyID.String())
+
+		if err != nil {
+			return err
+		}
+
+		if story.UserID != loggedInUserID {
+			return ErrNotStoryOwner
+		}
+
+		if story.MediaDeletedAt != nil {
+			return ErrStoryUnavailable
+		}
+	}
+
+	return nil
+}
+
+// toHighlightView keeps the stories the viewer may see; without an audience
+// only the close friends check applies. The cover falls back to the first
+// visible story when the chosen one isn't visible.
+func toHighlightView(highlight *model.Highlight, closeFriend bool, audience *storyAudience) payload.HighlightView {
+	highlightView := payload.HighlightView{
+		ID:       highlight.ID,
+		Title:    highlight.Title,
+		Position: highlight.Position,
+		Stories:  []payload.StoryView{},
+	}
+
+	for _, highlightStory := range highlight.Stories {
+		story := highlightStory.Story
+
+		if story.CloseFriendsOnly && !closeFriend {
+			continue
+		}
+
+		if audience != nil && !audience.canSee(&story) {
+			continue
+		}
+
+		highlightView.Stories = append(highlightView.Stories, toStoryView(&story))
+
+		if highlightView.CoverStoryID == nil || (highlight.CoverStoryID != nil && *highlight.CoverStoryID == story.ID) {
+			storyID := story.ID
+			highlightView.CoverStoryID = &storyID
+			highlightView.CoverImage = ""
+
+			if len(story.Content) != 0 {
+				highlightView.CoverImage = story.Content[0]
+			}
+		}
+	}
+
+	highlightView.NumberOfStories = len(highlightView.Stories)
+
+	return highlightView
+}
+
+func toStoryView(story *model.Story) payload.StoryView {
+	return payload.StoryView{
+		ID:               story.ID,
+		CreatedAt:        story.CreatedAt,
+		Content:          story.Content,
+		CloseFriendsOnly: story.CloseFriendsOnly,
+		AudienceListID:   story.AudienceListID,
+		Media:            toMediaViews(story.Media),
+		Stickers:         toStickerViews(story.Stickers),
+		SharedPost:       toSharedPostView(story),
+	}
+}
+
+func fillHighlightSharedPosts(highlightsView []payload.HighlightView, viewerID uuid.UUID, token string) {
+	var sharedPosts []*payload.SharedPostView
+
+	for _, highlightView := range highlightsView {
+		sharedPosts = append(sharedPosts, sharedPostsOf(highlightView.Stories)...)
+	}
+
+	fillSharedPosts(sharedPosts, token)
+}
+
+func highlightStoryIDs(highlight *model.Highlight) []uuid.UUID {
+	var storyIDs []uuid.UUID
+
+	for _, highlightStory := range highlight.Stories {
+		storyIDs = append(storyIDs, highlightStory.StoryID)
+	}
+
+	return storyIDs
+}
+
+func containsID(ids []uuid.UUID, id uuid.UUID) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+
+	return false
+}
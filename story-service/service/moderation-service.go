@@ -0,0 +1,170 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/KristijanPill/Nishtagram/story-service/model"
+	"github.com/KristijanPill/Nishtagram/story-service/payload"
+	"github.com/KristijanPill/Nishtagram/story-service/repository"
+	"github.com/google/uuid"
+)
+
+var ErrInvalidModerationAction = errors.New("invalid moderation action")
+
+var ErrNoPendingReports = errors.New("there are no pending reports for this story")
+
+// ModerationService hands the reports on stories to post-service, which keeps
+// the one moderation queue for posts and stories. The admin decisions are made
+// there; stories are only removed and their reports resolved here.
+type ModerationService struct {
+	repository      *repository.ModerationRepository
+	storyRepository *repository.StoryRepository
+}
+
+func NewModerationService(repository *repository.ModerationRepository, storyRepository *repository.StoryRepository) *ModerationService {
+	return &ModerationService{repository: repository, storyRepository: storyRepository}
+}
+
+// FindQueue groups reports by story, most reported first, so every story shows up
+// once in the queue no matter how many users reported it.
+func (service *ModerationService) FindQueue(status model.ReportStatus, limit int) ([]payload.ReportGroup, error) {
+	groups, err := service.repository.FindReportGroups(status, limit)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var queue = []payload.ReportGroup{}
+
+	if len(groups) == 0 {
+		return queue, nil
+	}
+
+	var storyIDs []uuid.UUID
+
+	for _, group := range groups {
+		storyIDs = append(storyIDs, group.StoryID)
+	}
+
+	reasonCounts, err := service.repository.FindReasonCounts(storyIDs, status)
+
+	if err != nil {
+		return nil, err
+	}
+
+	reasonsByStoryID := make(map[uuid.UUID]map[model.ReportReason]int64)
+
+	for _, reasonCount := range reasonCounts {
+		if reasonsByStoryID[reasonCount.StoryID] == nil {
+			reasonsByStoryID[reasonCount.StoryID] = make(map[model.ReportReason]int64)
+		}
+
+		reasonsByStoryID[reasonCount.StoryID][reasonCount.Reason] = reasonCount.Reports
+	}
+
+	for _, group := range groups {
+		reportGroup := payload.ReportGroup{
+			TargetID:        group.StoryID,
+			TargetType:      "story",
+			Reports:         group.Reports,
+			Reasons:         reasonsByStoryID[group.StoryID],
+			FirstReportedAt: group.FirstReportedAt,
+			LastReportedAt:  group.LastReportedAt,
+		}
+
+		story, err := service.storyRepository.FindByIDUnscoped(group.StoryID.String())
+
+		if err == nil {
+			reportGroup.OwnerID = story.UserID
+			reportGroup.Preview = payload.ContentPreview{
+				Content:          story.Content,
+				CloseFriendsOnly: story.CloseFriendsOnly,
+				Removed:          story.DeletedAt.Valid,
+			}
+		}
+
+		queue = append(queue, reportGroup)
+	}
+
+	return queue, nil
+}
+
+func (service *ModerationService) FindReports(storyID uuid.UUID) ([]payload.ReportView, error) {
+	reports, err := service.repository.FindReportsByStoryID(storyID.String())
+
+	if err != nil {
+		return nil, err
+	}
+
+	var reportsView = []payload.ReportView{}
+
+	for _, report := range reports {
+		reportsView = append(reportsView, payload.ReportView{
+			ID:          report.ID,
+			UserID:      report.UserID,
+			Reason:      report.Reason,
+			Description: report.Description,
+			Status:      report.Status,
+			CreatedAt:   report.CreatedAt,
+			ResolvedAt:  report.ResolvedAt,
+		})
+	}
+
+	return reportsView, nil
+}
+
+func (service *ModerationService) FindPending(storyID uuid.UUID) (*payload.PendingReports, error) {
+	story, err := service.storyRepository.FindByIDUnscoped(storyID.String())
+
+	if err != nil {
+		return nil, err
+	}
+
+	reporterIDs, err := service.repository.FindPendingReporterIDs(storyID.String())
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &payload.PendingReports{OwnerID: story.UserID, ReporterIDs: reporterIDs}, nil
+}
+
+// Resolve closes every pending report on the story with the decision of the
+// admin, removing the story first if that was the decision.
+func (service *ModerationService) Resolve(storyID uuid.UUID, dto *payload.ReportResolve) error {
+	if !dto.Action.IsValid() {
+		return ErrInvalidModerationAction
+	}
+
+	story, err := service.storyRepository.FindByIDUnscoped(storyID.String())
+
+	if err != nil {
+		return err
+	}
+
+	reporterIDs, err := service.repository.FindPendingReporterIDs(storyID.String())
+
+	if err != nil {
+		return err
+	}
+
+	if len(reporterIDs) == 0 {
+		return ErrNoPendingReports
+	}
+
+	if dto.Action == model.REMOVE_CONTENT {
+		err = service.storyRepository.Delete(story)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	status := model.ACTIONED
+
+	if dto.Action == model.DISMISS {
+		status = model.DISMISSED
+	}
+
+	return service.repository.ResolvePendingReports(storyID.String(), status)
+}
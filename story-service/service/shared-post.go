@@ -0,0 +1,163 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/KristijanPill/Nishtagram/story-service/helpers"
+	"github.com/KristijanPill/Nishtagram/story-service/model"
+	"github.com/KristijanPill/Nishtagram/story-service/payload"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrSharedPostPrivate     = errors.New("this post is from a private account you don't follow")
+	ErrSharedPostUnavailable = errors.New("this post is not available")
+)
+
+// fetchPost asks post-service for the post as seen by the owner of the token,
+// so the same privacy rules apply as when the post is opened in the feed.
+func fetchPost(postID uuid.UUID, token string) (*payload.PostView, error) {
+	requestURL := fmt.Sprintf("http://%s:%s/%s", os.Getenv("POST_SERVICE_DOMAIN"), os.Getenv("POST_SERVICE_PORT"), postID.String())
+	client := &http.Client{}
+	req, _ := http.NewRequest(http.MethodGet, requestURL, nil)
+	req.Header.Add("Authorization", "Bearer "+token)
+
+	response, err := client.Do(req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+
+	switch response.StatusCode {
+	case http.StatusOK:
+	case http.StatusForbidden:
+		return nil, ErrSharedPostPrivate
+	case http.StatusNotFound:
+		return nil, ErrSharedPostUnavailable
+	default:
+		return nil, fmt.Errorf("post-service responded with %s", response.Status)
+	}
+
+	var post = &payload.PostView{}
+	err = helpers.FromJSON(post, response.Body)
+
+	return post, err
+}
+
+func toSharedPostView(story *model.Story) *payload.SharedPostView {
+	if story.SharedPostID == nil {
+		return nil
+	}
+
+	return &payload.SharedPostView{ID: *story.SharedPostID}
+}
+
+// fillSharedPosts fetches the live details of the shared posts in one call.
+// Posts that were deleted or that the viewer can't see stay unavailable, and
+// so do all of them when post-service can't be reached, so the stories are
+// still shown.
+func fillSharedPosts(sharedPosts []*payload.SharedPostView, token string) {
+	var ids []uuid.UUID
+
+	for _, sharedPost := range sharedPosts {
+		if sharedPost != nil {
+			ids = append(ids, sharedPost.ID)
+		}
+	}
+
+	if len(ids) == 0 {
+		return
+	}
+
+	posts, err := fetchSharedPosts(ids, token)
+
+	if err != nil {
+		log.Println("fetching shared posts failed:", err)
+
+		return
+	}
+
+	postsByID := make(map[uuid.UUID]payload.PostView)
+
+	for _, post := range posts {
+		postsByID[post.ID] = post
+	}
+
+	for _, sharedPost := range sharedPosts {
+		if sharedPost == nil {
+			continue
+		}
+
+		post, found := postsByID[sharedPost.ID]
+
+		if !found {
+			continue
+		}
+
+		sharedPost.Available = true
+		sharedPost.UserID = post.UserID
+		sharedPost.Username = post.Username
+		sharedPost.ProfilePicture = post.ProfilePicture
+		sharedPost.Content = post.Content
+		sharedPost.Description = post.Description
+	}
+}
+
+func fetchSharedPosts(ids []uuid.UUID, token string) ([]payload.PostView, error) {
+	requestJSON, err := json.Marshal(&payload.SharedPostsRequest{IDs: ids})
+
+	if err != nil {
+		return nil, err
+	}
+
+	requestURL := fmt.Sprintf("http://%s:%s/internal/posts/shared", os.Getenv("POST_SERVICE_DOMAIN"), os.Getenv("POST_SERVICE_PORT"))
+	client := &http.Client{}
+	req, err := http.NewRequest(http.MethodPost, requestURL, bytes.NewBuffer(requestJSON))
+
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Content-Type", "application/json")
+
+	if token != "" {
+		req.Header.Add("Authorization", "Bearer "+token)
+	}
+
+	response, err := client.Do(req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("post-service responded with %s", response.Status)
+	}
+
+	var posts []payload.PostView
+	err = helpers.FromJSON(&posts, response.Body)
+
+	return posts, err
+}
+
+func sharedPostsOf(storiesView []payload.StoryView) []*payload.SharedPostView {
+	var sharedPosts []*payload.SharedPostView
+
+	for _, storyView := range storiesView {
+		if storyView.SharedPost != nil {
+			sharedPosts = append(sharedPosts, storyView.SharedPost)
+		}
+	}
+
+	return sharedPosts
+}
@@ -0,0 +1,409 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/KristijanPill/Nishtagram/story-service/helpers"
+	"github.com/KristijanPill/Nishtagram/story-service/model"
+	"github.com/KristijanPill/Nishtagram/story-service/payload"
+	"github.com/KristijanPill/Nishtagram/story-service/repository"
+	"github.com/google/uuid"
+)
+
+const (
+	maxStickersPerStory = 10
+	maxStickerText      = 100
+	maxAnswerLength     = 300
+	minPollOptions      = 2
+	maxPollOptions      = 4
+)
+
+var hashtagPattern = regexp.MustCompile(`^[\p{L}\p{N}_]+$`)
+
+var (
+	ErrInvalidSticker  = errors.New("invalid sticker")
+	ErrTooManyStickers = errors.New("a story can have at most 10 stickers")
+	ErrInvalidVote     = errors.New("invalid poll option")
+	ErrAlreadyVoted    = errors.New("you have already voted on this poll")
+	ErrInvalidAnswer   = errors.New("an answer must have between 1 and 300 characters")
+	ErrStickerSelf     = errors.New("you can't respond to stickers on your own story")
+)
+
+type StickerService struct {
+	repository      *repository.StickerRepository
+	storyRepository *repository.StoryRepository
+}
+
+func NewStickerService(repository *repository.StickerRepository, storyRepository *repository.StoryRepository) *StickerService {
+	return &StickerService{repository: repository, storyRepository: storyRepository}
+}
+
+func (service *StickerService) Vote(stickerID uuid.UUID, viewerID uuid.UUID, dto *payload.PollVoteCreate, token string) error {
+	sticker, err := service.findRespondable(stickerID, model.POLL, viewerID, token)
+
+	if err != nil {
+		return err
+	}
+
+	if dto.Option < 0 || dto.Option >= len(sticker.Options) {
+		return ErrInvalidVote
+	}
+
+	created, err := service.repository.CreateVote(&model.PollVote{StickerID: sticker.ID, UserID: viewerID, Option: dto.Option})
+
+	if err != nil {
+		return err
+	}
+
+	if !created {
+		return ErrAlreadyVoted
+	}
+
+	return nil
+}
+
+func (service *StickerService) Answer(stickerID uuid.UUID, viewerID uuid.UUID, dto *payload.QuestionAnswerCreate, token string) error {
+	sticker, err := service.findRespondable(stickerID, model.QUESTION, viewerID, token)
+
+	if err != nil {
+		return err
+	}
+
+	text := strings.TrimSpace(dto.Text)
+
+	if text == "" || utf8.RuneCountInString(text) > maxAnswerLength {
+		return ErrInvalidAnswer
+	}
+
+	_, err = service.repository.CreateAnswer(&model.QuestionAnswer{StickerID: sticker.ID, UserID: viewerID, Text: text})
+
+	return err
+}
+
+// FindResults shows the owner of the story the votes on its polls and how many
+// answers its questions collected.
+func (service *StickerService) FindResults(storyID uuid.UUID, userID uuid.UUID) ([]payload.StickerResults, error) {
+	story, err := service.storyRepository.FindByID(storyID.String())
+
+	if err != nil {
+		return nil, err
+	}
+
+	if story.UserID != userID {
+		return nil, ErrNotStoryOwner
+	}
+
+	var stickerIDs []uuid.UUID
+
+	for _, sticker := range story.Stickers {
+		stickerIDs = append(stickerIDs, sticker.ID)
+	}
+
+	optionVotes, err := service.repository.CountVotes(stickerIDs)
+
+	if err != nil {
+		return nil, err
+	}
+
+	stickerAnswers, err := service.repository.CountAnswers(stickerIDs)
+
+	if err != nil {
+		return nil, err
+	}
+
+	votesByStickerID := make(map[uuid.UUID]map[int]int64)
+
+	for _, votes := range optionVotes {
+		if votesByStickerID[votes.StickerID] == nil {
+			votesByStickerID[votes.StickerID] = make(map[int]int64)
+		}
+
+		votesByStickerID[votes.StickerID][votes.Option] = votes.Votes
+	}
+
+	answersByStickerID := make(map[uuid.UUID]int64)
+
+	for _, answers := range stickerAnswers {
+		answersByStickerID[answers.StickerID] = answers.Answers
+	}
+
+	var results = []payload.StickerResults{}
+
+	for _, sticker := range story.Stickers {
+		if sticker.Type != model.POLL && sticker.Type != model.QUESTION {
+			continue
+		}
+
+		stickerResults := payload.StickerResults{
+			Sticker: toStickerView(&sticker),
+			Answers: answersByStickerID[sticker.ID],
+		}
+
+		if sticker.Type == model.POLL {
+			stickerResults.Votes = make([]int64, len(sticker.Options))
+
+			for option := range sticker.Options {
+				stickerResults.Votes[option] = votesByStickerID[sticker.ID][option]
+				stickerResults.TotalVotes += stickerResults.Votes[option]
+			}
+		}
+
+		results = append(results, stickerResults)
+	}
+
+	return results, nil
+}
+
+func (service *StickerService) FindAnswers(stickerID uuid.UUID, userID uuid.UUID, page int, size int) ([]payload.QuestionAnswerView, error) {
+	sticker, err := service.repository.FindByID(stickerID.String())
+
+	if err != nil {
+		return nil, err
+	}
+
+	story, err := service.storyRepository.FindByID(sticker.StoryID.String())
+
+	if err != nil {
+		return nil, err
+	}
+
+	if story.UserID != userID {
+		return nil, ErrNotStoryOwner
+	}
+
+	answers, err := service.repository.FindAnswers(sticker.ID.String(), page, size)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var answersView = []payload.QuestionAnswerView{}
+
+	for _, answer := range answers {
+		answersView = append(answersView, payload.QuestionAnswerView{
+			ID:        answer.ID,
+			UserID:    answer.UserID,
+			Text:      answer.Text,
+			CreatedAt: answer.CreatedAt,
+		})
+	}
+
+	return answersView, nil
+}
+
+// findRespondable finds a sticker of the given type on an active story the
+// viewer can see.
+func (service *StickerService) findRespondable(stickerID uuid.UUID, stickerType model.StickerType, viewerID uuid.UUID, token string) (*model.Sticker, error) {
+	sticker, err := service.repository.FindByID(stickerID.String())
+
+	if err != nil {
+		return nil, err
+	}
+
+	if sticker.Type != stickerType {
+		return nil, ErrInvalidSticker
+	}
+
+	story, err := service.storyRepository.FindByID(sticker.StoryID.String())
+
+	if err != nil {
+		return nil, err
+	}
+
+	if time.Since(story.CreatedAt) > storyLifetime {
+		return nil, ErrStoryUnavailable
+	}
+
+	if story.UserID == viewerID {
+		return nil, ErrStickerSelf
+	}
+
+	if err := checkVisible(story, viewerID, token); err != nil {
+		return nil, err
+	}
+
+	return sticker, nil
+}
+
+// toStickers checks the stickers sent with a new story. Hashtags are stored
+// without the leading # and lower cased, the way post-service stores them.
+func toStickers(dtos []payload.StickerCreate) ([]model.Sticker, error) {
+	if len(dtos) > maxStickersPerStory {
+		return nil, ErrTooManyStickers
+	}
+
+	var stickers []model.Sticker
+
+	for _, dto := range dtos {
+		sticker := model.Sticker{
+			Type:      dto.Type,
+			Text:      strings.TrimSpace(dto.Text),
+			Latitude:  dto.Latitude,
+			Longitude: dto.Longitude,
+			X:         dto.X,
+			Y:         dto.Y,
+		}
+
+		if !dto.Type.IsValid() || sticker.X < 0 || sticker.X > 1 || sticker.Y < 0 || sticker.Y > 1 {
+			return nil, ErrInvalidSticker
+		}
+
+		switch dto.Type {
+		case model.POLL:
+			if len(dto.Options) < minPollOptions || len(dto.Options) > maxPollOptions {
+				return nil, ErrInvalidSticker
+			}
+
+			for _, option := range dto.Options {
+				option = strings.TrimSpace(option)
+
+				if option == "" || utf8.RuneCountInString(option) > maxStickerText {
+					return nil, ErrInvalidSticker
+				}
+
+				sticker.Options = append(sticker.Options, option)
+			}
+		case model.MENTION:
+			sticker.Text = strings.TrimPrefix(sticker.Text, "@")
+		case model.HASHTAG:
+			sticker.Text = strings.ToLower(strings.TrimLeft(sticker.Text, "#"))
+
+			if !hashtagPattern.MatchString(sticker.Text) {
+				return nil, ErrInvalidSticker
+			}
+		case model.LOCATION:
+			if (sticker.Latitude == nil) != (sticker.Longitude == nil) {
+				return nil, ErrInvalidSticker
+			}
+		}
+
+		if sticker.Text == "" || utf8.RuneCountInString(sticker.Text) > maxStickerText {
+			return nil, ErrInvalidSticker
+		}
+
+		stickers = append(stickers, sticker)
+	}
+
+	return stickers, nil
+}
+
+// resolveMentions links mention stickers to the mentioned users. Mentions of
+// users who can't be tagged by the author are dropped.
+func resolveMentions(authorID uuid.UUID, stickers []model.Sticker) ([]model.Sticker, error) {
+	var usernames []string
+
+	for _, sticker := range stickers {
+		if sticker.Type == model.MENTION {
+			usernames = append(usernames, sticker.Text)
+		}
+	}
+
+	if len(usernames) == 0 {
+		return stickers, nil
+	}
+
+	requestURL := fmt.Sprintf("http://%s:%s/internal/mentions", os.Getenv("USER_SERVICE_DOMAIN"), os.Getenv("USER_SERVICE_PORT"))
+	response, err := postInternal(requestURL, &payload.MentionCreate{AuthorID: authorID, Usernames: usernames})
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("user-service responded with %s", response.Status)
+	}
+
+	var mentions []payload.Mention
+
+	if err := helpers.FromJSON(&mentions, response.Body); err != nil {
+		return nil, err
+	}
+
+	userIDs := make(map[string]uuid.UUID)
+
+	for _, mention := range mentions {
+		userIDs[mention.Username] = mention.UserID
+	}
+
+	var resolved []model.Sticker
+
+	for _, sticker := range stickers {
+		if sticker.Type == model.MENTION {
+			userID, ok := userIDs[sticker.Text]
+
+			if !ok {
+				continue
+			}
+
+			sticker.MentionedUserID = &userID
+		}
+
+		resolved = append(resolved, sticker)
+	}
+
+	return resolved, nil
+}
+
+// notifyMentions has user-service let the users mentioned in a stored story
+// know about it.
+func notifyMentions(authorID uuid.UUID, stickers []model.Sticker) error {
+	var userIDs []uuid.UUID
+
+	for _, sticker := range stickers {
+		if sticker.MentionedUserID != nil {
+			userIDs = append(userIDs, *sticker.MentionedUserID)
+		}
+	}
+
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	requestURL := fmt.Sprintf("http://%s:%s/internal/mentions/notify", os.Getenv("USER_SERVICE_DOMAIN"), os.Getenv("USER_SERVICE_PORT"))
+	response, err := postInternal(requestURL, &payload.MentionNotify{AuthorID: authorID, UserIDs: userIDs})
+
+	if err != nil {
+		return err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("user-service responded with %s", response.Status)
+	}
+
+	return nil
+}
+
+func toStickerView(sticker *model.Sticker) payload.StickerView {
+	return payload.StickerView{
+		ID:              sticker.ID,
+		Type:            sticker.Type,
+		Text:            sticker.Text,
+		Options:         sticker.Options,
+		MentionedUserID: sticker.MentionedUserID,
+		Latitude:        sticker.Latitude,
+		Longitude:       sticker.Longitude,
+		X:               sticker.X,
+		Y:               sticker.Y,
+	}
+}
+
+func toStickerViews(stickers []model.Sticker) []payload.StickerView {
+	var stickersView = []payload.StickerView{}
+
+	for i := range stickers {
+		stickersView = append(stickersView, toStickerView(&stickers[i]))
+	}
+
+	return stickersView
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/KristijanPill/Nishtagram/story-service/helpers"
+	"github.com/KristijanPill/Nishtagram/story-service/repository"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultMediaRetentionDays = 30
+	mediaDeletionBatchSize    = 100
+)
+
+type StoryArchiveService struct {
+	repository *repository.StoryRepository
+}
+
+type storyIDs struct {
+	IDs []uuid.UUID `json:"ids"`
+}
+
+type mediaDelete struct {
+	Paths []string `json:"paths"`
+}
+
+func NewStoryArchiveService(repository *repository.StoryRepository) *StoryArchiveService {
+	return &StoryArchiveService{repository: repository}
+}
+
+// ArchiveExpired moves the stories that are more than a day old into the
+// archive of their owners.
+func (service *StoryArchiveService) ArchiveExpired() {
+	archived, err := service.repository.ArchiveExpired(time.Now().Add(-storyLifetime))
+
+	if err != nil {
+		log.Println("archiving expired stories failed:", err)
+
+		return
+	}
+
+	if archived != 0 {
+		log.Println("archived", archived, "expired stories")
+	}
+}
+
+// DeleteExpiredMedia deletes the media of stories that have been archived or
+// deleted for longer than STORY_MEDIA_RETENTION_DAYS. Highlighted stories keep
+// their media, and so do stories promoted by campaigns that haven't ended.
+// Stories are only marked once media-service has deleted their files, so a
+// failed run is retried the next time.
+func (service *StoryArchiveService) DeleteExpiredMedia() {
+	cutoff := time.Now().AddDate(0, 0, -mediaRetentionDays())
+
+	stories, err := service.repository.FindWithExpiredMedia(cutoff, mediaDeletionBatchSize)
+
+	if err != nil || len(stories) == 0 {
+		return
+	}
+
+	var ids []uuid.UUID
+
+	for _, story := range stories {
+		ids = append(ids, story.ID)
+	}
+
+	promoted, err := fetchPromotedStories(ids)
+
+	if err != nil {
+		log.Println("deleting story media skipped:", err)
+
+		return
+	}
+
+	var expiredIDs []uuid.UUID
+	var paths []string
+
+	for _, story := range stories {
+		if promoted[story.ID] {
+			continue
+		}
+
+		expiredIDs = append(expiredIDs, story.ID)
+		paths = append(paths, story.Content...)
+	}
+
+	if len(expiredIDs) == 0 {
+		return
+	}
+
+	if err := deleteStoryMedia(paths); err != nil {
+		log.Println("deleting story media failed:", err)
+
+		return
+	}
+
+	if err := service.repository.MarkMediaDeleted(expiredIDs); err != nil {
+		log.Println("marking story media as deleted failed:", err)
+	}
+}
+
+func mediaRetentionDays() int {
+	days, err := strconv.Atoi(os.Getenv("STORY_MEDIA_RETENTION_DAYS"))
+
+	if err != nil || days < 0 {
+		return defaultMediaRetentionDays
+	}
+
+	return days
+}
+
+func fetchPromotedStories(ids []uuid.UUID) (map[uuid.UUID]bool, error) {
+	response, err := postInternal(
+		fmt.Sprintf("http://%s:%s/internal/campaigns/stories/promoted", os.Getenv("POST_SERVICE_DOMAIN"), os.Getenv("POST_SERVICE_PORT")),
+		&storyIDs{IDs: ids})
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("post-service responded with %s", response.Status)
+	}
+
+	var promotedIDs = &storyIDs{}
+
+	if err := helpers.FromJSON(promotedIDs, response.Body); err != nil {
+		return nil, err
+	}
+
+	promoted := make(map[uuid.UUID]bool)
+
+	for _, id := range promotedIDs.IDs {
+		promoted[id] = true
+	}
+
+	return promoted, nil
+}
+
+func deleteStoryMedia(paths []string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+
+	response, err := postInternal(
+		fmt.Sprintf("http://%s:%s/internal/delete/stories", os.Getenv("MEDIA_SERVICE_DOMAIN"), os.Getenv("MEDIA_SERVICE_PORT")),
+		&mediaDelete{Paths: paths})
+
+	if err != nil {
+		return err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("media-service responded with %s", response.Status)
+	}
+
+	return nil
+}
+
+func postInternal(requestURL string, body interface{}) (*http.Response, error) {
+	var requestBody = &bytes.Buffer{}
+
+	if err := json.NewEncoder(requestBody).Encode(body); err != nil {
+		return nil, err
+	}
+
+	return http.Post(requestURL, "application/json", requestBody)
+}
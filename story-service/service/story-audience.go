@@ -0,0 +1,177 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/KristijanPill/Nishtagram/story-service/helpers"
+	"github.com/KristijanPill/Nishtagram/story-service/model"
+	"github.com/KristijanPill/Nishtagram/story-service/payload"
+	"github.com/google/uuid"
+)
+
+var ErrInvalidAudience = errors.New("a story is either for close friends or for one of your lists")
+
+// storyAudience says which stories the viewer may see besides the close friends
+// check: authors can hide their stories from the viewer, and stories shared
+// with an audience list are only for the members of that list.
+type storyAudience struct {
+	viewerID uuid.UUID
+	hiddenBy map[uuid.UUID]bool
+	memberOf map[uuid.UUID]bool
+}
+
+func (audience *storyAudience) canSee(story *model.Story) bool {
+	if story.UserID == audience.viewerID {
+		return true
+	}
+
+	if audience.hiddenBy[story.UserID] {
+		return false
+	}
+
+	return story.AudienceListID == nil || audience.memberOf[*story.AudienceListID]
+}
+
+func (audience *storyAudience) filter(stories []model.Story) []model.Story {
+	var visible = []model.Story{}
+
+	for _, story := range stories {
+		if audience.canSee(&story) {
+			visible = append(visible, story)
+		}
+	}
+
+	return visible
+}
+
+// fetchStoryAudience asks user-service about all the authors and audience lists
+// of the stories in one call. Anonymous viewers aren't on any list, and nobody
+// hides stories from them.
+func fetchStoryAudience(viewerID uuid.UUID, stories []model.Story) (*storyAudience, error) {
+	audience := &storyAudience{
+		viewerID: viewerID,
+		hiddenBy: make(map[uuid.UUID]bool),
+		memberOf: make(map[uuid.UUID]bool),
+	}
+
+	request := &payload.StoryAudienceRequest{ViewerID: viewerID}
+	authors := make(map[uuid.UUID]bool)
+
+	for _, story := range stories {
+		if story.UserID == viewerID {
+			continue
+		}
+
+		if !authors[story.UserID] {
+			authors[story.UserID] = true
+			request.AuthorIDs = append(request.AuthorIDs, story.UserID)
+		}
+
+		if story.AudienceListID != nil {
+			request.Lists = append(request.Lists, payload.AudienceListRef{ID: *story.AudienceListID, UserID: story.UserID})
+		}
+	}
+
+	if viewerID == uuid.Nil || len(request.AuthorIDs) == 0 {
+		return audience, nil
+	}
+
+	requestURL := fmt.Sprintf("http://%s:%s/internal/story-audience", os.Getenv("USER_SERVICE_DOMAIN"), os.Getenv("USER_SERVICE_PORT"))
+	response, err := postInternal(requestURL, request)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("user-service responded with %s", response.Status)
+	}
+
+	var result = &payload.StoryAudience{}
+
+	if err := helpers.FromJSON(result, response.Body); err != nil {
+		return nil, err
+	}
+
+	for _, userID := range result.HiddenBy {
+		audience.hiddenBy[userID] = true
+	}
+
+	for _, listID := range result.MemberOf {
+		audience.memberOf[listID] = true
+	}
+
+	return audience, nil
+}
+
+// checkVisible applies the author, close friends and audience rules to a single
+// story.
+func checkVisible(story *model.Story, viewerID uuid.UUID, token string) error {
+	if err := checkAuthor(story.UserID, token); err != nil {
+		return err
+	}
+
+	if err := checkCloseFriend(story, token); err != nil {
+		return err
+	}
+
+	audience, err := fetchStoryAudience(viewerID, []model.Story{*story})
+
+	if err != nil {
+		return err
+	}
+
+	if !audience.canSee(story) {
+		return ErrStoryUnavailable
+	}
+
+	return nil
+}
+
+// checkAudience makes sure a new story goes to a single audience, and that the
+// audience list belongs to the author.
+func checkAudience(closeFriendsOnly bool, audienceListID *uuid.UUID, token string) error {
+	if audienceListID == nil {
+		return nil
+	}
+
+	if closeFriendsOnly {
+		return ErrInvalidAudience
+	}
+
+	requestURL := fmt.Sprintf("http://%s:%s/audience-lists", os.Getenv("USER_SERVICE_DOMAIN"), os.Getenv("USER_SERVICE_PORT"))
+	client := &http.Client{}
+	req, _ := http.NewRequest(http.MethodGet, requestURL, nil)
+	req.Header.Add("Authorization", "Bearer "+token)
+
+	response, err := client.Do(req)
+
+	if err != nil {
+		return err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("user-service responded with %s", response.Status)
+	}
+
+	var lists []payload.AudienceListView
+
+	if err := helpers.FromJSON(&lists, response.Body); err != nil {
+		return err
+	}
+
+	for _, list := range lists {
+		if list.ID == *audienceListID {
+			return nil
+		}
+	}
+
+	return ErrInvalidAudience
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/KristijanPill/Nishtagram/story-service/model"
+	"github.com/google/uuid"
+)
+
+func TestStoryAudienceCanSee(t *testing.T) {
+	viewer := uuid.MustParse("00000000-0000-0000-0000-0000000000a1")
+	author := uuid.MustParse("00000000-0000-0000-0000-0000000000b0")
+	hiding := uuid.MustParse("00000000-0000-0000-0000-0000000000c0")
+	list := uuid.MustParse("00000000-0000-0000-0000-00000000001a")
+	otherList := uuid.MustParse("00000000-0000-0000-0000-00000000001b")
+
+	audience := &storyAudience{
+		viewerID: viewer,
+		hiddenBy: map[uuid.UUID]bool{hiding: true},
+		memberOf: map[uuid.UUID]bool{list: true},
+	}
+
+	tests := []struct {
+		name  string
+		story model.Story
+		want  bool
+	}{
+		{name: "public story", story: model.Story{UserID: author}, want: true},
+		{name: "own story", story: model.Story{UserID: viewer, AudienceListID: &otherList}, want: true},
+		{name: "author hides stories from the viewer", story: model.Story{UserID: hiding}, want: false},
+		{name: "member of the audience list", story: model.Story{UserID: author, AudienceListID: &list}, want: true},
+		{name: "not a member of the audience list", story: model.Story{UserID: author, AudienceListID: &otherList}, want: false},
+		{name: "hidden even for a member of the list", story: model.Story{UserID: hiding, AudienceListID: &list}, want: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := audience.canSee(&test.story); got != test.want {
+				t.Errorf("canSee() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestStoryAudienceFilter(t *testing.T) {
+	viewer := uuid.MustParse("00000000-0000-0000-0000-0000000000a1")
+	author := uuid.MustParse("00000000-0000-0000-0000-0000000000b0")
+	list := uuid.MustParse("00000000-0000-0000-0000-00000000001a")
+
+	public := model.Story{ID: uuid.MustParse("00000000-0000-0000-0000-000000000001"), UserID: author}
+	listed := model.Story{ID: uuid.MustParse("00000000-0000-0000-0000-000000000002"), UserID: author, AudienceListID: &list}
+
+	tests := []struct {
+		name     string
+		audience storyAudience
+		stories  []model.Story
+		want     []model.Story
+	}{
+		{
+			name:     "no stories",
+			audience: storyAudience{viewerID: viewer},
+			stories:  nil,
+			want:     []model.Story{},
+		},
+		{
+			name:     "list stories are left out for non-members",
+			audience: storyAudience{viewerID: viewer},
+			stories:  []model.Story{public, listed},
+			want:     []model.Story{public},
+		},
+		{
+			name:     "members see all of them",
+			audience: storyAudience{viewerID: viewer, memberOf: map[uuid.UUID]bool{list: true}},
+			stories:  []model.Story{listed, public},
+			want:     []model.Story{listed, public},
+		},
+		{
+			name:     "nothing from an author hiding their stories",
+			audience: storyAudience{viewerID: viewer, hiddenBy: map[uuid.UUID]bool{author: true}, memberOf: map[uuid.UUID]bool{list: true}},
+			stories:  []model.Story{public, listed},
+			want:     []model.Story{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.audience.filter(test.stories); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("filter() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
@@ -3,8 +3,11 @@ package service
 import (
 	"errors"
 	"fmt"
+	"log"
 	"net/http"
 	"os"
+	"sort"
+	"time"
 
 	"github.com/KristijanPill/Nishtagram/story-service/helpers"
 	"github.com/KristijanPill/Nishtagram/story-service/model"
@@ -13,16 +16,112 @@ import (
 	"github.com/google/uuid"
 )
 
+const storyLifetime = 24 * time.Hour
+
+var ErrInvalidReportReason = errors.New("invalid report reason")
+
+var ErrNotStoryOwner = errors.New("only the owner can do this")
+
+var ErrStoryUnavailable = errors.New("story is not available")
+
+var ErrInvalidReaction = errors.New("invalid reaction")
+
+var ErrInvalidStoryResponse = errors.New("a reply must have between 1 and 1000 characters")
+
+var ErrStoryResponseSelf = errors.New("you can't reply to your own story")
+
+var ErrStoryResponseBlocked = errors.New("you can't reply to this story")
+
+const sponsoredStoriesPerTray = 1
+
+const (
+	storyReplyMessage    = "STORY_REPLY"
+	storyReactionMessage = "STORY_REACTION"
+)
+
+var quickReactions = map[string]bool{"😂": true, "😮": true, "😍": true, "😢": true, "👏": true, "🔥": true, "🎉": true, "💯": true}
+
 type StoryService struct {
-	repository *repository.StoryRepository
+	repository       *repository.StoryRepository
+	viewerRepository *repository.StoryViewerRepository
+	campaignService  *CampaignService
+}
+
+func NewStoryService(repository *repository.StoryRepository, viewerRepository *repository.StoryViewerRepository, campaignService *CampaignService) *StoryService {
+	return &StoryService{repository: repository, viewerRepository: viewerRepository, campaignService: campaignService}
+}
+
+// CheckStickers validates the stickers of a new story before its media are
+// uploaded.
+func (service *StoryService) CheckStickers(dtos []payload.StickerCreate) error {
+	_, err := toStickers(dtos)
+
+	return err
+}
+
+// CheckAudience validates the audience of a new story before its media are
+// uploaded.
+func (service *StoryService) CheckAudience(closeFriendsOnly bool, audienceListID *uuid.UUID, token string) error {
+	return checkAudience(closeFriendsOnly, audienceListID, token)
 }
 
-func NewStoryService(repository *repository.StoryRepository) *StoryService {
-	return &StoryService{repository: repository}
+func (service *StoryService) Create(story *model.Story, dtos []payload.StickerCreate) (*model.Story, error) {
+	stickers, err := toStickers(dtos)
+
+	if err != nil {
+		return nil, err
+	}
+
+	story.Stickers, err = resolveMentions(story.UserID, stickers)
+
+	if err != nil {
+		return nil, err
+	}
+
+	story, err = service.repository.Create(story)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// the story is already stored, so a failed notification must not fail it
+	if err := notifyMentions(story.UserID, story.Stickers); err != nil {
+		log.Println("notifying mentions of story", story.ID, "failed:", err)
+	}
+
+	return story, nil
 }
 
-func (service *StoryService) Create(story *model.Story) (*model.Story, error) {
-	return service.repository.Create(story)
+// SharePost creates a story that shows the given post. The post must be visible
+// to the user sharing it, so posts of private accounts they don't follow can't
+// be shared. Shared posts go to the same audiences as other stories.
+func (service *StoryService) SharePost(userID uuid.UUID, dto *payload.StoryShareCreate, token string) (*payload.StoryView, error) {
+	if err := checkAudience(dto.CloseFriends, dto.AudienceListID, token); err != nil {
+		return nil, err
+	}
+
+	post, err := fetchPost(dto.PostID, token)
+
+	if err != nil {
+		return nil, err
+	}
+
+	story, err := service.repository.Create(&model.Story{
+		UserID:           userID,
+		Content:          []string{},
+		CloseFriendsOnly: dto.CloseFriends,
+		AudienceListID:   dto.AudienceListID,
+		SharedPostID:     &post.ID,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	storyView := toStoryView(story)
+	fillSharedPosts([]*payload.SharedPostView{storyView.SharedPost}, token)
+
+	return &storyView, nil
 }
 
 func (service *StoryService) Delete(storyID uuid.UUID, userID uuid.UUID) error {
@@ -32,14 +131,368 @@ func (service *StoryService) Delete(storyID uuid.UUID, userID uuid.UUID) error {
 		return err
 	}
 
-	if story.UserID == userID {
-		return service.repository.Delete(story)
-	} else {
-		return errors.New("unauthorized")
+	if story.UserID != userID {
+		return ErrNotStoryOwner
 	}
+
+	return service.repository.Delete(story)
 }
 
+// FindByUser shows the active stories of another user, unless the viewer is
+// blocked by them or they are private and not followed by the viewer.
 func (service *StoryService) FindByUser(userID uuid.UUID, loggedInUserID uuid.UUID, token string) ([]payload.StoryView, error) {
+	if userID != loggedInUserID {
+		if err := checkAuthor(userID, token); err != nil {
+			return nil, err
+		}
+	}
+
+	var closeFriend bool
+
+	if loggedInUserID != uuid.Nil {
+		followStatus, err := fetchFollowStatus(userID, token)
+
+		if err != nil {
+			return nil, err
+		}
+
+		closeFriend = followStatus.CloseFriend
+	}
+
+	var stories = []model.Story{}
+	var err error
+
+	if closeFriend {
+		stories, err = service.repository.FindByUserIDCloseFriends(userID.String())
+	} else {
+		stories, err = service.repository.FindByUserIDNotCloseFriends(userID.String())
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	audience, err := fetchStoryAudience(loggedInUserID, stories)
+
+	if err != nil {
+		return nil, err
+	}
+
+	stories = audience.filter(stories)
+
+	seen, err := service.findSeen(loggedInUserID, stories)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var storiesView []payload.StoryView
+
+	for _, story := range stories {
+		storyView := toStoryView(&story)
+		storyView.Seen = seen[story.ID]
+		storiesView = append(storiesView, storyView)
+	}
+
+	fillSharedPosts(sharedPostsOf(storiesView), token)
+
+	return storiesView, nil
+}
+
+// FindTray groups the active stories of the accounts the viewer follows by
+// author. Authors with stories the viewer hasn't seen come first, and within
+// that the ones who posted most recently. Muted and blocked accounts are left
+// out by user-service, which also says in one call whose close friend the
+// viewer is. Sponsored stories are best effort and left out when post-service
+// can't be reached.
+func (service *StoryService) FindTray(viewerID uuid.UUID, token string) (*payload.StoryTray, error) {
+	outgoing, err := fetchOutgoingFollows(token)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var userIDs []uuid.UUID
+	var closeFriendOf []uuid.UUID
+
+	for _, follow := range outgoing {
+		userIDs = append(userIDs, follow.UserID)
+
+		if follow.CloseFriend {
+			closeFriendOf = append(closeFriendOf, follow.UserID)
+		}
+	}
+
+	stories, err := service.repository.FindActiveByUserIDs(userIDs, closeFriendOf)
+
+	if err != nil {
+		return nil, err
+	}
+
+	audience, err := fetchStoryAudience(viewerID, stories)
+
+	if err != nil {
+		return nil, err
+	}
+
+	stories = audience.filter(stories)
+
+	seen, err := service.findSeen(viewerID, stories)
+
+	if err != nil {
+		return nil, err
+	}
+
+	tray := &payload.StoryTray{Authors: []payload.StoryTrayItem{}}
+	indexByUserID := make(map[uuid.UUID]int)
+
+	for _, story := range stories {
+		i, found := indexByUserID[story.UserID]
+
+		if !found {
+			i = len(tray.Authors)
+			indexByUserID[story.UserID] = i
+			tray.Authors = append(tray.Authors, payload.StoryTrayItem{UserID: story.UserID})
+		}
+
+		item := &tray.Authors[i]
+		storyView := toStoryView(&story)
+		storyView.Seen = seen[story.ID]
+		item.Stories = append(item.Stories, storyView)
+		item.HasUnseen = item.HasUnseen || !seen[story.ID]
+		item.LatestStoryAt = story.CreatedAt
+	}
+
+	var sharedPosts []*payload.SharedPostView
+
+	for _, item := range tray.Authors {
+		sharedPosts = append(sharedPosts, sharedPostsOf(item.Stories)...)
+	}
+
+	fillSharedPosts(sharedPosts, token)
+
+	sort.SliceStable(tray.Authors, func(i, j int) bool {
+		if tray.Authors[i].HasUnseen != tray.Authors[j].HasUnseen {
+			return tray.Authors[i].HasUnseen
+		}
+
+		return tray.Authors[i].LatestStoryAt.After(tray.Authors[j].LatestStoryAt)
+	})
+
+	tray.Sponsored, err = service.campaignService.FindSponsored(sponsoredStoriesPerTray, token)
+
+	if err != nil {
+		tray.Sponsored = []payload.SponsoredStoryView{}
+	}
+
+	return tray, nil
+}
+
+// RecordView marks an active story as seen by the viewer. Owners looking at
+// their own stories are not counted, and close friends stories only count for
+// close friends.
+func (service *StoryService) RecordView(storyID uuid.UUID, viewerID uuid.UUID, token string) error {
+	story, err := service.repository.FindByID(storyID.String())
+
+	if err != nil {
+		return err
+	}
+
+	if time.Since(story.CreatedAt) > storyLifetime {
+		return ErrStoryUnavailable
+	}
+
+	if story.UserID == viewerID {
+		return nil
+	}
+
+	if err := checkVisible(story, viewerID, token); err != nil {
+		return err
+	}
+
+	return service.viewerRepository.Create(&model.StoryViewer{StoryID: storyID, ViewerID: viewerID})
+}
+
+// Reply sends the text to the author of the story as a direct message.
+func (service *StoryService) Reply(storyID uuid.UUID, viewerID uuid.UUID, dto *payload.StoryReplyCreate, token string) error {
+	return service.respond(storyID, viewerID, storyReplyMessage, dto.Text, token)
+}
+
+// React sends one of the quick reactions to the author of the story as a direct
+// message.
+func (service *StoryService) React(storyID uuid.UUID, viewerID uuid.UUID, dto *payload.StoryReactionCreate, token string) error {
+	if !quickReactions[dto.Emoji] {
+		return ErrInvalidReaction
+	}
+
+	return service.respond(storyID, viewerID, storyReactionMessage, dto.Emoji, token)
+}
+
+// respond delivers a reply or reaction through user-service, which refuses it
+// when either user has blocked the other. Responses to close friends stories
+// are marked so the author knows which audience the viewer saw.
+func (service *StoryService) respond(storyID uuid.UUID, viewerID uuid.UUID, messageType string, text string, token string) error {
+	story, err := service.repository.FindByID(storyID.String())
+
+	if err != nil {
+		return err
+	}
+
+	if time.Since(story.CreatedAt) > storyLifetime {
+		return ErrStoryUnavailable
+	}
+
+	if story.UserID == viewerID {
+		return ErrStoryResponseSelf
+	}
+
+	if err := checkVisible(story, viewerID, token); err != nil {
+		return err
+	}
+
+	var preview string
+
+	if len(story.Content) != 0 {
+		preview = story.Content[0]
+	}
+
+	requestURL := fmt.Sprintf("http://%s:%s/internal/messages/story", os.Getenv("USER_SERVICE_DOMAIN"), os.Getenv("USER_SERVICE_PORT"))
+	response, err := postInternal(requestURL, &payload.StoryResponseCreate{
+		SenderID:         viewerID,
+		RecipientID:      story.UserID,
+		Type:             messageType,
+		Text:             text,
+		StoryID:          story.ID,
+		StoryPreview:     preview,
+		CloseFriendsOnly: story.CloseFriendsOnly,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	defer response.Body.Close()
+
+	switch response.StatusCode {
+	case http.StatusCreated:
+		return nil
+	case http.StatusForbidden:
+		return ErrStoryResponseBlocked
+	case http.StatusBadRequest:
+		return ErrInvalidStoryResponse
+	}
+
+	return fmt.Errorf("user-service responded with %s", response.Status)
+}
+
+// checkCloseFriend lets only close friends of the author see close friends
+// stories.
+func checkCloseFriend(story *model.Story, token string) error {
+	if !story.CloseFriendsOnly {
+		return nil
+	}
+
+	followStatus, err := fetchFollowStatus(story.UserID, token)
+
+	if err != nil {
+		return err
+	}
+
+	if !followStatus.CloseFriend {
+		return ErrStoryUnavailable
+	}
+
+	return nil
+}
+
+func (service *StoryService) FindViewers(storyID uuid.UUID, userID uuid.UUID, page int, size int) (*payload.StoryViewers, error) {
+	story, err := service.repository.FindByID(storyID.String())
+
+	if err != nil {
+		return nil, err
+	}
+
+	if story.UserID != userID {
+		return nil, ErrNotStoryOwner
+	}
+
+	total, err := service.viewerRepository.CountByStoryID(storyID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	viewers, err := service.viewerRepository.FindByStoryID(storyID, page, size)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var storyViewers = &payload.StoryViewers{Total: total, Viewers: []payload.StoryViewerView{}}
+
+	for _, viewer := range viewers {
+		storyViewers.Viewers = append(storyViewers.Viewers, payload.StoryViewerView{
+			UserID:   viewer.ViewerID,
+			ViewedAt: viewer.CreatedAt,
+		})
+	}
+
+	return storyViewers, nil
+}
+
+// findSeen looks up in one query which of the stories the viewer has already
+// seen. Anonymous viewers have seen nothing.
+func (service *StoryService) findSeen(viewerID uuid.UUID, stories []model.Story) (map[uuid.UUID]bool, error) {
+	seen := make(map[uuid.UUID]bool)
+
+	if viewerID == uuid.Nil {
+		return seen, nil
+	}
+
+	var storyIDs []uuid.UUID
+
+	for _, story := range stories {
+		storyIDs = append(storyIDs, story.ID)
+	}
+
+	seenIDs, err := service.viewerRepository.FindSeenStoryIDs(viewerID, storyIDs)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, storyID := range seenIDs {
+		seen[storyID] = true
+	}
+
+	return seen, nil
+}
+
+func fetchOutgoingFollows(token string) ([]payload.OutgoingFollow, error) {
+	requestURL := fmt.Sprintf("http://%s:%s/follow/outgoing", os.Getenv("USER_SERVICE_DOMAIN"), os.Getenv("USER_SERVICE_PORT"))
+	client := &http.Client{}
+	req, _ := http.NewRequest(http.MethodGet, requestURL, nil)
+	req.Header.Add("Authorization", "Bearer "+token)
+
+	response, err := client.Do(req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("user-service responded with %s", response.Status)
+	}
+
+	var outgoing []payload.OutgoingFollow
+	err = helpers.FromJSON(&outgoing, response.Body)
+
+	return outgoing, err
+}
+
+func fetchFollowStatus(userID uuid.UUID, token string) (*payload.FollowStatus, error) {
 	requestURL := fmt.Sprintf("http://%s:%s/follow/outgoing/"+userID.String(), os.Getenv("USER_SERVICE_DOMAIN"), os.Getenv("USER_SERVICE_PORT"))
 	client := &http.Client{}
 	req, _ := http.NewRequest(http.MethodGet, requestURL, nil)
@@ -54,16 +507,22 @@ func (service *StoryService) FindByUser(userID uuid.UUID, loggedInUserID uuid.UU
 		return nil, err
 	}
 
-	var followStatus = &payload.FollowStatus{}
-	helpers.FromJSON(&followStatus, response.Body)
+	defer response.Body.Close()
 
-	var stories = []model.Story{}
-	if followStatus.CloseFriend {
-		stories, err = service.repository.FindByUserIDCloseFriends(userID.String())
-	} else {
-		stories, err = service.repository.FindByUserIDNotCloseFriends(userID.String())
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("user-service responded with %s", response.Status)
 	}
 
+	var followStatus = &payload.FollowStatus{}
+	err = helpers.FromJSON(followStatus, response.Body)
+
+	return followStatus, err
+}
+
+func (service *StoryService) FindByLoggedInUser(userID uuid.UUID, token string) ([]payload.StoryView, error) {
+
+	stories, err := service.repository.FindByUserID(userID.String())
+
 	if err != nil {
 		return nil, err
 	}
@@ -71,21 +530,16 @@ func (service *StoryService) FindByUser(userID uuid.UUID, loggedInUserID uuid.UU
 	var storiesView []payload.StoryView
 
 	for _, story := range stories {
-		storyView := &payload.StoryView{
-			ID:               story.ID,
-			CreatedAt:        story.CreatedAt,
-			Content:          story.Content,
-			CloseFriendsOnly: story.CloseFriendsOnly,
-		}
-		storiesView = append(storiesView, *storyView)
+		storiesView = append(storiesView, toStoryView(&story))
 	}
 
+	fillSharedPosts(sharedPostsOf(storiesView), token)
+
 	return storiesView, nil
 }
 
-func (service *StoryService) FindByLoggedInUser(userID uuid.UUID) ([]payload.StoryView, error) {
-
-	stories, err := service.repository.FindByUserID(userID.String())
+func (service *StoryService) FindAllByLoggedInUser(userID uuid.UUID, token string) ([]payload.StoryView, error) {
+	stories, err := service.repository.FindAllByUserID(userID.String())
 
 	if err != nil {
 		return nil, err
@@ -94,47 +548,80 @@ func (service *StoryService) FindByLoggedInUser(userID uuid.UUID) ([]payload.Sto
 	var storiesView []payload.StoryView
 
 	for _, story := range stories {
-		storyView := &payload.StoryView{
-			ID:               story.ID,
-			CreatedAt:        story.CreatedAt,
-			Content:          story.Content,
-			CloseFriendsOnly: story.CloseFriendsOnly,
-		}
-		storiesView = append(storiesView, *storyView)
+		storiesView = append(storiesView, toStoryView(&story))
 	}
 
+	fillSharedPosts(sharedPostsOf(storiesView), token)
+
 	return storiesView, nil
 }
 
-func (service *StoryService) FindAllByLoggedInUser(userID uuid.UUID) ([]payload.StoryView, error) {
-	stories, err := service.repository.FindAllByUserID(userID.String())
+// FindArchive lists the archived stories of the logged in user, which nobody
+// else can see anymore. Stories whose media were deleted are left out.
+func (service *StoryService) FindArchive(userID uuid.UUID, page int, size int, token string) ([]payload.StoryView, error) {
+	stories, err := service.repository.FindArchivedByUserID(userID.String(), page, size)
 
 	if err != nil {
 		return nil, err
 	}
 
-	var storiesView []payload.StoryView
+	var storiesView = []payload.StoryView{}
 
 	for _, story := range stories {
-		storyView := &payload.StoryView{
-			ID:               story.ID,
-			CreatedAt:        story.CreatedAt,
-			Content:          story.Content,
-			CloseFriendsOnly: story.CloseFriendsOnly,
-		}
-		storiesView = append(storiesView, *storyView)
+		storiesView = append(storiesView, toStoryView(&story))
 	}
 
+	fillSharedPosts(sharedPostsOf(storiesView), token)
+
 	return storiesView, nil
 }
 
+// GetDetails is used by post-service, which lets agents promote their stories.
+func (service *StoryService) GetDetails(storyID uuid.UUID) (*payload.StoryDetails, error) {
+	story, err := service.repository.FindByID(storyID.String())
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &payload.StoryDetails{
+		ID:               story.ID,
+		UserID:           story.UserID,
+		Content:          story.Content,
+		CloseFriendsOnly: story.CloseFriendsOnly,
+	}, nil
+}
+
 func (service *StoryService) CreateReport(dto *payload.ReportCreate) (*model.Report, error) {
+	if !dto.Reason.IsValid() {
+		return nil, ErrInvalidReportReason
+	}
 
 	report := &model.Report{
 		StoryID:     dto.StoryID,
 		UserID:      dto.UserID,
+		Reason:      dto.Reason,
 		Description: dto.Description,
 	}
 
 	return service.repository.CreateReport(report)
 }
+
+func toMediaViews(media []model.StoryMedia) []payload.MediaView {
+	var mediaViews = []payload.MediaView{}
+
+	for _, item := range media {
+		mediaViews = append(mediaViews, payload.MediaView{
+			Path:          item.Path,
+			ThumbnailPath: item.ThumbnailPath,
+			FeedPath:      item.FeedPath,
+			Blurhash:      item.Blurhash,
+			Type:          item.Type,
+			Width:         item.Width,
+			Height:        item.Height,
+			Duration:      item.Duration,
+		})
+	}
+
+	return mediaViews
+}
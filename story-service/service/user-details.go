@@ -0,0 +1,82 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/KristijanPill/Nishtagram/story-service/helpers"
+	"github.com/KristijanPill/Nishtagram/story-service/payload"
+	"github.com/google/uuid"
+)
+
+var ErrPrivateAccount = errors.New("this account is private, request to follow to see its stories")
+
+// fetchUserDetails asks user-service about the user as seen by the owner of the
+// token, so follow and block status are filled in.
+func fetchUserDetails(userID uuid.UUID, token string) (*payload.UserDetails, error) {
+	requestJSON, err := json.Marshal(&payload.UserIDs{IDs: []payload.UserID{{ID: userID}}})
+
+	if err != nil {
+		return nil, err
+	}
+
+	requestURL := fmt.Sprintf("http://%s:%s/users-details", os.Getenv("USER_SERVICE_DOMAIN"), os.Getenv("USER_SERVICE_PORT"))
+	client := &http.Client{}
+	req, err := http.NewRequest(http.MethodPost, requestURL, bytes.NewBuffer(requestJSON))
+
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Content-Type", "application/json")
+
+	if token != "" {
+		req.Header.Add("Authorization", "Bearer "+token)
+	}
+
+	response, err := client.Do(req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+
+	var details = &payload.UsersDetails{}
+
+	if err := helpers.FromJSON(details, response.Body); err != nil {
+		return nil, err
+	}
+
+	for _, userDetails := range details.UsersDetails {
+		if userDetails.ID == userID {
+			return &userDetails, nil
+		}
+	}
+
+	return nil, ErrStoryUnavailable
+}
+
+// checkAuthor keeps the stories of an author from viewers the author blocked
+// and, if the account is private, from viewers who don't follow it.
+func checkAuthor(authorID uuid.UUID, token string) error {
+	details, err := fetchUserDetails(authorID, token)
+
+	if err != nil {
+		return err
+	}
+
+	if details.Blocked {
+		return ErrStoryUnavailable
+	}
+
+	if details.Private && !details.Followed {
+		return ErrPrivateAccount
+	}
+
+	return nil
+}
@@ -0,0 +1,142 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/KristijanPill/Nishtagram/user-service/helpers"
+	"github.com/KristijanPill/Nishtagram/user-service/middleware"
+	"github.com/KristijanPill/Nishtagram/user-service/model"
+	"github.com/KristijanPill/Nishtagram/user-service/payload"
+	"github.com/KristijanPill/Nishtagram/user-service/service"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+type AgentRequestHandler struct {
+	service *service.AgentRequestService
+}
+
+func NewAgentRequestHandler(service *service.AgentRequestService) *AgentRequestHandler {
+	return &AgentRequestHandler{service: service}
+}
+
+func (handler *AgentRequestHandler) Register(w http.ResponseWriter, r *http.Request) {
+	dto := &payload.AgentRegistration{}
+	err := helpers.FromJSON(&dto, r.Body)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	_, err = handler.service.Register(dto)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (handler *AgentRequestHandler) Create(w http.ResponseWriter, r *http.Request) {
+	claims := r.Context().Value(middleware.TokenKey{}).(jwt.MapClaims)
+
+	userIDString := helpers.ExtractClaim("sub", claims)
+	userID, err := uuid.Parse(userIDString)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	dto := &payload.AgentRequestCreate{}
+	err = helpers.FromJSON(&dto, r.Body)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	_, err = handler.service.Create(dto, userID)
+
+	switch err {
+	case nil:
+		w.WriteHeader(http.StatusCreated)
+	case service.ErrWebsiteRequired:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case service.ErrAgentRequestPending, service.ErrAlreadyAgent:
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (handler *AgentRequestHandler) FindByStatus(w http.ResponseWriter, r *http.Request) {
+	status := model.AgentRequestStatus(r.URL.Query().Get("status"))
+
+	switch status {
+	case "":
+		status = model.AGENT_PENDING
+	case model.AGENT_PENDING, model.AGENT_APPROVED, model.AGENT_REJECTED:
+	default:
+		http.Error(w, "unknown agent request status", http.StatusBadRequest)
+
+		return
+	}
+
+	page, size := helpers.ExtractPagination(r)
+
+	agentRequests, err := handler.service.FindByStatus(status, page, size)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	helpers.ToJSON(&agentRequests, w)
+}
+
+func (handler *AgentRequestHandler) Approve(w http.ResponseWriter, r *http.Request) {
+	handler.review(w, r, handler.service.Approve)
+}
+
+func (handler *AgentRequestHandler) Reject(w http.ResponseWriter, r *http.Request) {
+	handler.review(w, r, handler.service.Reject)
+}
+
+func (handler *AgentRequestHandler) review(w http.ResponseWriter, r *http.Request, review func(uuid.UUID) (*payload.AgentRequestView, error)) {
+	vars := mux.Vars(r)
+	id, err := uuid.Parse(vars["id"])
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	agentRequest, err := review(id)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case err == service.ErrAgentRequestReviewed:
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	helpers.ToJSON(agentRequest, w)
+}
@@ -221,3 +221,26 @@ func (handler *FollowHandler) Unmute(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 }
+
+func (handler *FollowHandler) GetOutgoingFollows(w http.ResponseWriter, r *http.Request) {
+	claims := r.Context().Value(middleware.TokenKey{}).(jwt.MapClaims)
+
+	userIDString := helpers.ExtractClaim("sub", claims)
+	userID, err := uuid.Parse(userIDString)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	outgoing, err := handler.service.FindOutgoing(userID)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	helpers.ToJSON(&outgoing, w)
+}
@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/KristijanPill/Nishtagram/user-service/helpers"
+	"github.com/KristijanPill/Nishtagram/user-service/payload"
+	"github.com/KristijanPill/Nishtagram/user-service/service"
+	"gorm.io/gorm"
+)
+
+type MentionHandler struct {
+	service *service.MentionService
+}
+
+func NewMentionHandler(service *service.MentionService) *MentionHandler {
+	return &MentionHandler{service: service}
+}
+
+func (handler *MentionHandler) Resolve(w http.ResponseWriter, r *http.Request) {
+	dto := &payload.MentionCreate{}
+	err := helpers.FromJSON(&dto, r.Body)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	mentions, err := handler.service.Resolve(dto)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+
+		return
+	}
+
+	helpers.ToJSON(&mentions, w)
+}
+
+func (handler *MentionHandler) Notify(w http.ResponseWriter, r *http.Request) {
+	dto := &payload.MentionNotify{}
+	err := helpers.FromJSON(&dto, r.Body)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	err = handler.service.Notify(dto)
+
+	if err == gorm.ErrRecordNotFound {
+		http.Error(w, err.Error(), http.StatusNotFound)
+
+		return
+	}
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/KristijanPill/Nishtagram/user-service/helpers"
+	"github.com/KristijanPill/Nishtagram/user-service/middleware"
+	"github.com/KristijanPill/Nishtagram/user-service/payload"
+	"github.com/KristijanPill/Nishtagram/user-service/service"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+type MessageHandler struct {
+	service *service.MessageService
+}
+
+func NewMessageHandler(service *service.MessageService) *MessageHandler {
+	return &MessageHandler{service: service}
+}
+
+func (handler *MessageHandler) SendStoryResponse(w http.ResponseWriter, r *http.Request) {
+	dto := &payload.StoryResponseCreate{}
+	err := helpers.FromJSON(&dto, r.Body)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	message, err := handler.service.SendStoryResponse(dto)
+
+	if writeMessageError(w, err) {
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	helpers.ToJSON(message, w)
+}
+
+func (handler *MessageHandler) FindConversations(w http.ResponseWriter, r *http.Request) {
+	claims := r.Context().Value(middleware.TokenKey{}).(jwt.MapClaims)
+
+	userIDString := helpers.ExtractClaim("sub", claims)
+	userID, err := uuid.Parse(userIDString)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	page, size := helpers.ExtractPagination(r)
+
+	conversations, err := handler.service.FindConversations(userID, page, size)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	helpers.ToJSON(&conversations, w)
+}
+
+func (handler *MessageHandler) FindMessages(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	conversationID, err := uuid.Parse(vars["id"])
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	claims := r.Context().Value(middleware.TokenKey{}).(jwt.MapClaims)
+
+	userIDString := helpers.ExtractClaim("sub", claims)
+	userID, err := uuid.Parse(userIDString)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	page, size := helpers.ExtractPagination(r)
+
+	messages, err := handler.service.FindMessages(conversationID, userID, page, size)
+
+	if writeMessageError(w, err) {
+		return
+	}
+
+	helpers.ToJSON(&messages, w)
+}
+
+func writeMessageError(w http.ResponseWriter, err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case err == service.ErrInvalidMessage, err == service.ErrMessageSelf:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case err == service.ErrMessagingBlocked, err == service.ErrMessagesClosed:
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+
+	return true
+}
@@ -0,0 +1,259 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/KristijanPill/Nishtagram/user-service/helpers"
+	"github.com/KristijanPill/Nishtagram/user-service/middleware"
+	"github.com/KristijanPill/Nishtagram/user-service/payload"
+	"github.com/KristijanPill/Nishtagram/user-service/service"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+type StoryAudienceHandler struct {
+	service *service.StoryAudienceService
+}
+
+func NewStoryAudienceHandler(service *service.StoryAudienceService) *StoryAudienceHandler {
+	return &StoryAudienceHandler{service: service}
+}
+
+func (handler *StoryAudienceHandler) Hide(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	hiddenUserID, err := uuid.Parse(vars["id"])
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	claims := r.Context().Value(middleware.TokenKey{}).(jwt.MapClaims)
+
+	userIDString := helpers.ExtractClaim("sub", claims)
+	userID, err := uuid.Parse(userIDString)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	err = handler.service.Hide(userID, hiddenUserID)
+
+	if writeStoryAudienceError(w, err) {
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (handler *StoryAudienceHandler) Unhide(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	hiddenUserID, err := uuid.Parse(vars["id"])
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	claims := r.Context().Value(middleware.TokenKey{}).(jwt.MapClaims)
+
+	userIDString := helpers.ExtractClaim("sub", claims)
+	userID, err := uuid.Parse(userIDString)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	err = handler.service.Unhide(userID, hiddenUserID)
+
+	if writeStoryAudienceError(w, err) {
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (handler *StoryAudienceHandler) FindHidden(w http.ResponseWriter, r *http.Request) {
+	claims := r.Context().Value(middleware.TokenKey{}).(jwt.MapClaims)
+
+	userIDString := helpers.ExtractClaim("sub", claims)
+	userID, err := uuid.Parse(userIDString)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	hiddenUserIDs, err := handler.service.FindHidden(userID)
+
+	if writeStoryAudienceError(w, err) {
+		return
+	}
+
+	helpers.ToJSON(&hiddenUserIDs, w)
+}
+
+func (handler *StoryAudienceHandler) CreateList(w http.ResponseWriter, r *http.Request) {
+	dto := &payload.AudienceListCreate{}
+	err := helpers.FromJSON(&dto, r.Body)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	claims := r.Context().Value(middleware.TokenKey{}).(jwt.MapClaims)
+
+	userIDString := helpers.ExtractClaim("sub", claims)
+	userID, err := uuid.Parse(userIDString)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	list, err := handler.service.CreateList(userID, dto)
+
+	if writeStoryAudienceError(w, err) {
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	helpers.ToJSON(list, w)
+}
+
+func (handler *StoryAudienceHandler) FindLists(w http.ResponseWriter, r *http.Request) {
+	claims := r.Context().Value(middleware.TokenKey{}).(jwt.MapClaims)
+
+	userIDString := helpers.ExtractClaim("sub", claims)
+	userID, err := uuid.Parse(userIDString)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	lists, err := handler.service.FindLists(userID)
+
+	if writeStoryAudienceError(w, err) {
+		return
+	}
+
+	helpers.ToJSON(&lists, w)
+}
+
+func (handler *StoryAudienceHandler) UpdateList(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	listID, err := uuid.Parse(vars["id"])
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	dto := &payload.AudienceListCreate{}
+	err = helpers.FromJSON(&dto, r.Body)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	claims := r.Context().Value(middleware.TokenKey{}).(jwt.MapClaims)
+
+	userIDString := helpers.ExtractClaim("sub", claims)
+	userID, err := uuid.Parse(userIDString)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	list, err := handler.service.UpdateList(listID, userID, dto)
+
+	if writeStoryAudienceError(w, err) {
+		return
+	}
+
+	helpers.ToJSON(list, w)
+}
+
+func (handler *StoryAudienceHandler) DeleteList(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	listID, err := uuid.Parse(vars["id"])
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	claims := r.Context().Value(middleware.TokenKey{}).(jwt.MapClaims)
+
+	userIDString := helpers.ExtractClaim("sub", claims)
+	userID, err := uuid.Parse(userIDString)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	err = handler.service.DeleteList(listID, userID)
+
+	if writeStoryAudienceError(w, err) {
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (handler *StoryAudienceHandler) Check(w http.ResponseWriter, r *http.Request) {
+	dto := &payload.StoryAudienceRequest{}
+	err := helpers.FromJSON(&dto, r.Body)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	audience, err := handler.service.Check(dto)
+
+	if writeStoryAudienceError(w, err) {
+		return
+	}
+
+	helpers.ToJSON(audience, w)
+}
+
+func writeStoryAudienceError(w http.ResponseWriter, err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, service.ErrHideSelf), errors.Is(err, service.ErrInvalidAudienceList), errors.Is(err, service.ErrTooManyAudienceMembers):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+
+	return true
+}
@@ -6,6 +6,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"os"
+	"strings"
 
 	"github.com/KristijanPill/Nishtagram/user-service/helpers"
 	"github.com/KristijanPill/Nishtagram/user-service/middleware"
@@ -21,8 +22,8 @@ type UserHandler struct {
 	followService *service.FollowService
 }
 
-func NewUserHandler(service *service.UserService) *UserHandler {
-	return &UserHandler{userService: service}
+func NewUserHandler(userService *service.UserService, followService *service.FollowService) *UserHandler {
+	return &UserHandler{userService: userService, followService: followService}
 }
 
 func (handler *UserHandler) Create(w http.ResponseWriter, r *http.Request) {
@@ -98,6 +99,8 @@ func (handler *UserHandler) GetUserInfo(w http.ResponseWriter, r *http.Request)
 		Name:           user.Name,
 		DOB:            user.DOB,
 		Gender:         user.Gender,
+		City:           user.City,
+		Country:        user.Country,
 		PhoneNumber:    user.PhoneNumber,
 		Website:        user.Website,
 		Bio:            user.Bio,
@@ -215,6 +218,15 @@ func (handler *UserHandler) UpdateProfilePicture(w http.ResponseWriter, r *http.
 		return
 	}
 
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		message, _ := ioutil.ReadAll(response.Body)
+		http.Error(w, strings.TrimSpace(string(message)), response.StatusCode)
+
+		return
+	}
+
 	profilePicturePath := &payload.ProfilePictureUploadResponse{}
 
 	err = helpers.FromJSON(&profilePicturePath, response.Body)
@@ -275,7 +287,93 @@ func (handler *UserHandler) GetUsersDetails(w http.ResponseWriter, r *http.Reque
 	}
 
 	usersDetails := handler.userService.BindUsernameToID(userIDs)
-	details := handler.followService.BindFollowStatus(usersDetails, loggedInUserID)
+	details, err := handler.followService.BindFollowStatus(usersDetails, loggedInUserID)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
 
 	helpers.ToJSON(details, w)
 }
+
+func (handler *UserHandler) GetAccountStatus(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	userID, err := uuid.Parse(vars["id"])
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	status, err := handler.userService.GetAccountStatus(userID)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+
+		return
+	}
+
+	helpers.ToJSON(&status, w)
+}
+
+func (handler *UserHandler) GetAudience(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	userID, err := uuid.Parse(vars["id"])
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	audience, err := handler.userService.GetAudience(userID)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+
+		return
+	}
+
+	helpers.ToJSON(audience, w)
+}
+
+func (handler *UserHandler) Warn(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	userID, err := uuid.Parse(vars["id"])
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	_, err = handler.userService.Warn(userID)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+}
+
+func (handler *UserHandler) Suspend(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	userID, err := uuid.Parse(vars["id"])
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	_, err = handler.userService.Suspend(userID)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+}
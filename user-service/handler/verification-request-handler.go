@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/KristijanPill/Nishtagram/user-service/helpers"
 	"github.com/KristijanPill/Nishtagram/user-service/middleware"
@@ -57,6 +58,15 @@ func (handler *VerificationRequestHandler) Create(w http.ResponseWriter, r *http
 		return
 	}
 
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		message, _ := ioutil.ReadAll(response.Body)
+		http.Error(w, strings.TrimSpace(string(message)), response.StatusCode)
+
+		return
+	}
+
 	documentPath := &payload.DocumentPictureUploadResponse{}
 	err = helpers.FromJSON(&documentPath, response.Body)
 	if err != nil {
@@ -50,15 +50,28 @@ func initDatabase() *gorm.DB {
 	db.AutoMigrate(&model.FollowRequest{})
 	db.AutoMigrate(&model.VerificationRequest{})
 	db.AutoMigrate(&model.Block{})
+	db.AutoMigrate(&model.Notification{})
+	db.AutoMigrate(&model.AgentRequest{})
+	db.AutoMigrate(&model.Conversation{})
+	db.AutoMigrate(&model.Message{})
+	db.AutoMigrate(&model.StoryHide{})
+	db.AutoMigrate(&model.AudienceList{})
+	db.AutoMigrate(&model.AudienceListMember{})
 
 	return db
 }
 
 func handleFunc(handler *handler.UserHandler, followHandler *handler.FollowHandler, followRequestHandler *handler.FollowRequestHandler,
-	verificationRequestHandler *handler.VerificationRequestHandler, blockHandler *handler.BlockHandler, securityMiddleware *middleware.SecurityMiddleware, sm *mux.Router) {
+	verificationRequestHandler *handler.VerificationRequestHandler, blockHandler *handler.BlockHandler, notificationHandler *handler.NotificationHandler, agentRequestHandler *handler.AgentRequestHandler, messageHandler *handler.MessageHandler, mentionHandler *handler.MentionHandler, storyAudienceHandler *handler.StoryAudienceHandler, securityMiddleware *middleware.SecurityMiddleware, sm *mux.Router) {
 	getRouterRestricted := sm.Methods(http.MethodGet).Subrouter()
 	getRouterRestricted.HandleFunc("/user-info", handler.GetUserInfo)
 	getRouterRestricted.HandleFunc("/user-profile-info", handler.GetUserProfileInfo)
+	getRouterRestricted.HandleFunc("/notifications", notificationHandler.FindByLoggedInUser)
+	getRouterRestricted.HandleFunc("/follow/outgoing", followHandler.GetOutgoingFollows)
+	getRouterRestricted.HandleFunc("/conversations", messageHandler.FindConversations)
+	getRouterRestricted.HandleFunc("/conversations/{id}/messages", messageHandler.FindMessages)
+	getRouterRestricted.HandleFunc("/stories/hidden", storyAudienceHandler.FindHidden)
+	getRouterRestricted.HandleFunc("/audience-lists", storyAudienceHandler.FindLists)
 	getRouterRestricted.Use(securityMiddleware.Authenticate)
 
 	getRouterPublic := sm.Methods(http.MethodGet).Subrouter()
@@ -69,6 +82,7 @@ func handleFunc(handler *handler.UserHandler, followHandler *handler.FollowHandl
 
 	postRouter := sm.Methods(http.MethodPost).Subrouter()
 	postRouter.HandleFunc("/register", handler.Create)
+	postRouter.HandleFunc("/register/agent", agentRequestHandler.Register)
 
 	postRouterPublic := sm.Methods(http.MethodPost).Subrouter()
 	postRouterPublic.HandleFunc("/users-details", handler.GetUsersDetails)
@@ -81,6 +95,9 @@ func handleFunc(handler *handler.UserHandler, followHandler *handler.FollowHandl
 	putRouterRestricted.HandleFunc("/follow/mute/{id}", followHandler.Mute)
 	putRouterRestricted.HandleFunc("/follow/unmute/{id}", followHandler.Unmute)
 	putRouterRestricted.HandleFunc("/profile-picture", handler.UpdateProfilePicture)
+	putRouterRestricted.HandleFunc("/stories/hide/{id}", storyAudienceHandler.Hide)
+	putRouterRestricted.HandleFunc("/stories/unhide/{id}", storyAudienceHandler.Unhide)
+	putRouterRestricted.HandleFunc("/audience-lists/{id}", storyAudienceHandler.UpdateList)
 	putRouterRestricted.Use(securityMiddleware.Authenticate)
 
 	postRouterRestricted := sm.Methods(http.MethodPost).Subrouter()
@@ -88,9 +105,40 @@ func handleFunc(handler *handler.UserHandler, followHandler *handler.FollowHandl
 	postRouterRestricted.HandleFunc("/follow/accept/{id}", followRequestHandler.Accept)
 	postRouterRestricted.HandleFunc("/follow/decline/{id}", followRequestHandler.Decline)
 	postRouterRestricted.HandleFunc("/verify", verificationRequestHandler.Create)
+	postRouterRestricted.HandleFunc("/agent-request", agentRequestHandler.Create)
 	postRouterRestricted.HandleFunc("/block/{id}", blockHandler.Block)
 	postRouterRestricted.HandleFunc("/unblock/{id}", blockHandler.Unblock)
+	postRouterRestricted.HandleFunc("/audience-lists", storyAudienceHandler.CreateList)
 	postRouterRestricted.Use(securityMiddleware.Authenticate)
+
+	deleteRouterRestricted := sm.Methods(http.MethodDelete).Subrouter()
+	deleteRouterRestricted.HandleFunc("/audience-lists/{id}", storyAudienceHandler.DeleteList)
+	deleteRouterRestricted.Use(securityMiddleware.Authenticate)
+
+	getRouterInternal := sm.Methods(http.MethodGet).Subrouter()
+	getRouterInternal.HandleFunc("/internal/account-status/{id}", handler.GetAccountStatus)
+	getRouterInternal.HandleFunc("/internal/audience/{id}", handler.GetAudience)
+
+	postRouterInternal := sm.Methods(http.MethodPost).Subrouter()
+	postRouterInternal.HandleFunc("/internal/messages/story", messageHandler.SendStoryResponse)
+	postRouterInternal.HandleFunc("/internal/mentions", mentionHandler.Resolve)
+	postRouterInternal.HandleFunc("/internal/mentions/notify", mentionHandler.Notify)
+	postRouterInternal.HandleFunc("/internal/story-audience", storyAudienceHandler.Check)
+
+	getRouterAdmin := sm.Methods(http.MethodGet).Subrouter()
+	getRouterAdmin.HandleFunc("/admin/agent-requests", agentRequestHandler.FindByStatus)
+	getRouterAdmin.Use(securityMiddleware.AuthorizeAdmin)
+
+	putRouterAdmin := sm.Methods(http.MethodPut).Subrouter()
+	putRouterAdmin.HandleFunc("/admin/warn/{id}", handler.Warn)
+	putRouterAdmin.HandleFunc("/admin/suspend/{id}", handler.Suspend)
+	putRouterAdmin.HandleFunc("/admin/agent-requests/{id}/approve", agentRequestHandler.Approve)
+	putRouterAdmin.HandleFunc("/admin/agent-requests/{id}/reject", agentRequestHandler.Reject)
+	putRouterAdmin.Use(securityMiddleware.AuthorizeAdmin)
+
+	postRouterAdmin := sm.Methods(http.MethodPost).Subrouter()
+	postRouterAdmin.HandleFunc("/admin/notifications", notificationHandler.Create)
+	postRouterAdmin.Use(securityMiddleware.AuthorizeAdmin)
 }
 
 func main() {
@@ -101,23 +149,37 @@ func main() {
 	followRequestRepository := repository.NewFollowRequestRepository(database)
 	verificationRequestRepository := repository.NewVerificationRequestRepository(database)
 	blockRepository := repository.NewBlockRepository(database)
+	notificationRepository := repository.NewNotificationRepository(database)
+	agentRequestRepository := repository.NewAgentRequestRepository(database)
+	messageRepository := repository.NewMessageRepository(database)
+	storyAudienceRepository := repository.NewStoryAudienceRepository(database)
 
 	userService := service.NewUserService(userRepository, followRepository)
-	followService := service.NewFollowService(followRepository, followRequestRepository, userRepository)
+	followService := service.NewFollowService(followRepository, followRequestRepository, userRepository, blockRepository)
 	followRequestService := service.NewFollowRequestService(followRequestRepository, followRepository)
 	verificationRequestService := service.NewVerificationRequestService(verificationRequestRepository)
 	blockService := service.NewBlockService(blockRepository, followRepository, followRequestRepository)
+	notificationService := service.NewNotificationService(notificationRepository)
+	agentRequestService := service.NewAgentRequestService(agentRequestRepository, userRepository, userService, notificationService)
+	messageService := service.NewMessageService(messageRepository, userRepository, blockRepository, followRepository)
+	mentionService := service.NewMentionService(userRepository, blockRepository, notificationService)
+	storyAudienceService := service.NewStoryAudienceService(storyAudienceRepository, userRepository)
 
 	securityMiddleware := middleware.NewSecurityMiddleware(publicKey)
-	userHandler := handler.NewUserHandler(userService)
+	userHandler := handler.NewUserHandler(userService, followService)
 	followHandler := handler.NewFollowHandler(followService)
 	followRequestHandler := handler.NewFollowRequestHandler(followRequestService)
 	verificationRequestHandler := handler.NewVerificationRequestHandler(verificationRequestService)
 	blockHandler := handler.NewBlockHandler(blockService)
+	notificationHandler := handler.NewNotificationHandler(notificationService)
+	agentRequestHandler := handler.NewAgentRequestHandler(agentRequestService)
+	messageHandler := handler.NewMessageHandler(messageService)
+	mentionHandler := handler.NewMentionHandler(mentionService)
+	storyAudienceHandler := handler.NewStoryAudienceHandler(storyAudienceService)
 
 	sm := mux.NewRouter()
 
-	handleFunc(userHandler, followHandler, followRequestHandler, verificationRequestHandler, blockHandler, securityMiddleware, sm)
+	handleFunc(userHandler, followHandler, followRequestHandler, verificationRequestHandler, blockHandler, notificationHandler, agentRequestHandler, messageHandler, mentionHandler, storyAudienceHandler, securityMiddleware, sm)
 
 	bindAddress := fmt.Sprintf(":%s", os.Getenv("USER_SERVICE_PORT"))
 
@@ -20,9 +20,13 @@ type User struct {
 	Name                   string
 	DOB                    time.Time
 	Gender                 Gender
+	City                   string
+	Country                string
 	PhoneNumber            string
 	Website                string
 	Bio                    string
+	Suspended              bool
+	Warnings               int
 	CreatedAt              time.Time
 	UpdatedAt              time.Time
 }
@@ -39,8 +43,29 @@ type Role string
 const (
 	USER  Role = "ROLE_USER"
 	ADMIN Role = "ROLE_ADMIN"
+	AGENT Role = "ROLE_AGENT"
 )
 
+type AgentRequestStatus string
+
+const (
+	AGENT_PENDING  AgentRequestStatus = "PENDING"
+	AGENT_APPROVED AgentRequestStatus = "APPROVED"
+	AGENT_REJECTED AgentRequestStatus = "REJECTED"
+)
+
+// AgentRequest is an application for the agent role, which lets businesses
+// run campaigns once an admin approves it.
+type AgentRequest struct {
+	ID          uuid.UUID `gorm:"primary_key; unique; type:uuid;"`
+	UserID      uuid.UUID `gorm:"type:uuid; index"`
+	Website     string
+	Description string
+	Status      AgentRequestStatus `gorm:"index; default:PENDING"`
+	CreatedAt   time.Time
+	ReviewedAt  *time.Time
+}
+
 type VerificationRequest struct {
 	ID                      uuid.UUID `gorm:"primary_key; unique; type:uuid;"`
 	UserID                  uuid.UUID
@@ -94,6 +119,80 @@ type Mute struct {
 	Muted   User
 }
 
+// StoryHide keeps the stories of UserID from HiddenUserID, who can still see
+// the profile and posts.
+type StoryHide struct {
+	UserID       uuid.UUID `gorm:"primaryKey; type:uuid"`
+	HiddenUserID uuid.UUID `gorm:"primaryKey; type:uuid; index"`
+	CreatedAt    time.Time
+}
+
+// AudienceList is a list of users, other than close friends, a story can be
+// shared with.
+type AudienceList struct {
+	ID        uuid.UUID `gorm:"primaryKey; unique; type:uuid"`
+	UserID    uuid.UUID `gorm:"type:uuid; index"`
+	Name      string
+	Members   []AudienceListMember `gorm:"foreignKey:ListID"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type AudienceListMember struct {
+	ListID    uuid.UUID `gorm:"primaryKey; type:uuid"`
+	MemberID  uuid.UUID `gorm:"primaryKey; type:uuid; index"`
+	CreatedAt time.Time
+}
+
+type NotificationType string
+
+const (
+	MODERATION    NotificationType = "MODERATION"
+	AGENT_REQUEST NotificationType = "AGENT_REQUEST"
+	MENTION       NotificationType = "MENTION"
+)
+
+type Notification struct {
+	ID        uuid.UUID `gorm:"primary_key; unique; type:uuid;"`
+	UserID    uuid.UUID `gorm:"type:uuid; index"`
+	Type      NotificationType
+	Message   string
+	Read      bool
+	CreatedAt time.Time
+}
+
+// Conversation is the direct message thread of two users. UserID holds the
+// smaller of the two IDs so every pair of users has a single conversation.
+type Conversation struct {
+	ID          uuid.UUID `gorm:"primary_key; unique; type:uuid;"`
+	UserID      uuid.UUID `gorm:"type:uuid; uniqueIndex:idx_conversation_users"`
+	OtherUserID uuid.UUID `gorm:"type:uuid; uniqueIndex:idx_conversation_users; index"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time `gorm:"index"`
+}
+
+type MessageType string
+
+const (
+	STORY_REPLY    MessageType = "STORY_REPLY"
+	STORY_REACTION MessageType = "STORY_REACTION"
+)
+
+// Message is a reply or a reaction to a story, delivered to the author of the
+// story as a direct message. It keeps the story it responds to, and whether it
+// was shared with close friends only.
+type Message struct {
+	ID               uuid.UUID `gorm:"primary_key; unique; type:uuid;"`
+	ConversationID   uuid.UUID `gorm:"type:uuid; index"`
+	SenderID         uuid.UUID `gorm:"type:uuid"`
+	Type             MessageType
+	Text             string
+	StoryID          *uuid.UUID `gorm:"type:uuid"`
+	StoryPreview     string
+	CloseFriendsOnly bool
+	CreatedAt        time.Time `gorm:"index"`
+}
+
 func (u *User) BeforeCreate(tx *gorm.DB) (err error) {
 	u.ID = uuid.New()
 	return
@@ -103,3 +202,28 @@ func (v *VerificationRequest) BeforeCreate(tx *gorm.DB) (err error) {
 	v.ID = uuid.New()
 	return
 }
+
+func (a *AgentRequest) BeforeCreate(tx *gorm.DB) (err error) {
+	a.ID = uuid.New()
+	return
+}
+
+func (n *Notification) BeforeCreate(tx *gorm.DB) (err error) {
+	n.ID = uuid.New()
+	return
+}
+
+func (l *AudienceList) BeforeCreate(tx *gorm.DB) (err error) {
+	l.ID = uuid.New()
+	return
+}
+
+func (c *Conversation) BeforeCreate(tx *gorm.DB) (err error) {
+	c.ID = uuid.New()
+	return
+}
+
+func (m *Message) BeforeCreate(tx *gorm.DB) (err error) {
+	m.ID = uuid.New()
+	return
+}
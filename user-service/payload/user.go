@@ -31,6 +31,8 @@ type UserInfo struct {
 	Name           string       `json:"name"`
 	DOB            time.Time    `json:"dob"`
 	Gender         model.Gender `json:"gender"`
+	City           string       `json:"city"`
+	Country        string       `json:"country"`
 	PhoneNumber    string       `json:"phone_number"`
 	Website        string       `json:"website"`
 	Bio            string       `json:"bio"`
@@ -74,6 +76,7 @@ type UserDetails struct {
 	Username       string    `json:"username"`
 	Private        bool      `json:"private"`
 	Followed       bool      `json:"followed"`
+	Blocked        bool      `json:"blocked"`
 	ProfilePicture string    `json:"profile_picture"`
 }
 
@@ -96,12 +99,138 @@ type FollowStatus struct {
 	CloseFriend bool `json:"close_friend"`
 }
 
+type OutgoingFollow struct {
+	UserID      uuid.UUID `json:"user_id"`
+	CloseFriend bool      `json:"close_friend"`
+}
+
 type ProfilePictureUploadResponse struct {
 	ProfilePicture string `json:"profile_picture"`
 }
 
 type UserView struct {
-	Username       string `json:"username"`
-	Name           string `json:"name"`
-	ProfilePicture string `json:"profile_picture,omitempty"`
+	ID             uuid.UUID `json:"id"`
+	Username       string    `json:"username"`
+	Name           string    `json:"name"`
+	ProfilePicture string    `json:"profile_picture,omitempty"`
+}
+
+type AccountStatus struct {
+	Role      model.Role `json:"role"`
+	Suspended bool       `json:"suspended"`
+}
+
+type NotificationCreate struct {
+	UserIDs []uuid.UUID            `json:"user_ids"`
+	Type    model.NotificationType `json:"type"`
+	Message string                 `json:"message"`
+}
+
+type NotificationView struct {
+	ID        uuid.UUID              `json:"id"`
+	Type      model.NotificationType `json:"type"`
+	Message   string                 `json:"message"`
+	Read      bool                   `json:"read"`
+	CreatedAt time.Time              `json:"created_at"`
+}
+
+type AgentRegistration struct {
+	CreateUser
+	Description string `json:"description"`
+}
+
+type AgentRequestCreate struct {
+	Website     string `json:"website"`
+	Description string `json:"description"`
+}
+
+type AgentRequestView struct {
+	ID          uuid.UUID                `json:"id"`
+	UserID      uuid.UUID                `json:"user_id"`
+	Username    string                   `json:"username"`
+	Website     string                   `json:"website"`
+	Description string                   `json:"description"`
+	Status      model.AgentRequestStatus `json:"status"`
+	CreatedAt   time.Time                `json:"created_at"`
+	ReviewedAt  *time.Time               `json:"reviewed_at,omitempty"`
+}
+
+type Audience struct {
+	Age     int          `json:"age"`
+	Gender  model.Gender `json:"gender"`
+	City    string       `json:"city"`
+	Country string       `json:"country"`
+}
+
+type StoryResponseCreate struct {
+	SenderID         uuid.UUID         `json:"sender_id"`
+	RecipientID      uuid.UUID         `json:"recipient_id"`
+	Type             model.MessageType `json:"type"`
+	Text             string            `json:"text"`
+	StoryID          uuid.UUID         `json:"story_id"`
+	StoryPreview     string            `json:"story_preview"`
+	CloseFriendsOnly bool              `json:"close_friends_only"`
+}
+
+type MessageView struct {
+	ID               uuid.UUID         `json:"id"`
+	SenderID         uuid.UUID         `json:"sender_id"`
+	Type             model.MessageType `json:"type"`
+	Text             string            `json:"text"`
+	StoryID          *uuid.UUID        `json:"story_id,omitempty"`
+	StoryPreview     string            `json:"story_preview,omitempty"`
+	CloseFriendsOnly bool              `json:"close_friends_only"`
+	CreatedAt        time.Time         `json:"created_at"`
+}
+
+type ConversationView struct {
+	ID          uuid.UUID    `json:"id"`
+	UserID      uuid.UUID    `json:"user_id"`
+	LastMessage *MessageView `json:"last_message"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+type MentionCreate struct {
+	AuthorID  uuid.UUID `json:"author_id"`
+	Usernames []string  `json:"usernames"`
+}
+
+type Mention struct {
+	UserID   uuid.UUID `json:"user_id"`
+	Username string    `json:"username"`
+}
+
+type MentionNotify struct {
+	AuthorID uuid.UUID   `json:"author_id"`
+	UserIDs  []uuid.UUID `json:"user_ids"`
+}
+
+type AudienceListCreate struct {
+	Name      string      `json:"name"`
+	MemberIDs []uuid.UUID `json:"member_ids"`
+}
+
+type AudienceListView struct {
+	ID        uuid.UUID   `json:"id"`
+	Name      string      `json:"name"`
+	MemberIDs []uuid.UUID `json:"member_ids"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+type AudienceListRef struct {
+	ID     uuid.UUID `json:"id"`
+	UserID uuid.UUID `json:"user_id"`
+}
+
+// StoryAudienceRequest asks which of the authors hide their stories from the
+// viewer and which of the authors' audience lists the viewer is on.
+type StoryAudienceRequest struct {
+	ViewerID  uuid.UUID         `json:"viewer_id"`
+	AuthorIDs []uuid.UUID       `json:"author_ids"`
+	Lists     []AudienceListRef `json:"lists"`
+}
+
+type StoryAudience struct {
+	HiddenBy []uuid.UUID `json:"hidden_by"`
+	MemberOf []uuid.UUID `json:"member_of"`
 }
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/KristijanPill/Nishtagram/user-service/model"
+	"gorm.io/gorm"
+)
+
+type AgentRequestRepository struct {
+	database *gorm.DB
+}
+
+func NewAgentRequestRepository(database *gorm.DB) *AgentRequestRepository {
+	return &AgentRequestRepository{database: database}
+}
+
+func (repository *AgentRequestRepository) Create(agentRequest *model.AgentRequest) (*model.AgentRequest, error) {
+	result := repository.database.Create(agentRequest)
+
+	return agentRequest, result.Error
+}
+
+func (repository *AgentRequestRepository) FindByID(id string) (*model.AgentRequest, error) {
+	var agentRequest model.AgentRequest
+	result := repository.database.First(&agentRequest, "id = ?", id)
+
+	return &agentRequest, result.Error
+}
+
+func (repository *AgentRequestRepository) ExistsPendingByUserID(userID string) bool {
+	var count int64
+	repository.database.Model(&model.AgentRequest{}).Where("user_id = ? AND status = ?", userID, model.AGENT_PENDING).Count(&count)
+
+	return count != 0
+}
+
+func (repository *AgentRequestRepository) FindByStatus(status model.AgentRequestStatus, page int, size int) ([]model.AgentRequest, error) {
+	var agentRequests []model.AgentRequest
+	result := repository.database.Where("status = ?", status).Order("created_at").Offset(page * size).Limit(size).Find(&agentRequests)
+
+	return agentRequests, result.Error
+}
+
+// Review resolves a pending request and, when it is approved, grants the agent
+// role in the same transaction. It reports false when the request had already
+// been reviewed.
+func (repository *AgentRequestRepository) Review(agentRequest *model.AgentRequest, status model.AgentRequestStatus) (bool, error) {
+	reviewed := false
+
+	err := repository.database.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&model.AgentRequest{}).
+			Where("id = ? AND status = ?", agentRequest.ID, model.AGENT_PENDING).
+			Updates(map[string]interface{}{"status": status, "reviewed_at": now})
+
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		reviewed = true
+		agentRequest.Status = status
+		agentRequest.ReviewedAt = &now
+
+		if status != model.AGENT_APPROVED {
+			return nil
+		}
+
+		return tx.Model(&model.User{}).Where("id = ? AND role = ?", agentRequest.UserID, model.USER).Update("role", model.AGENT).Error
+	})
+
+	return reviewed, err
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"github.com/KristijanPill/Nishtagram/user-service/model"
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
@@ -36,3 +37,28 @@ func (repository *BlockRepository) Delete(block *model.Block) error {
 
 	return result.Error
 }
+
+// FindBlockedUserIDs tells which of the users are blocked by the user or have
+// blocked them.
+func (repository *BlockRepository) FindBlockedUserIDs(userID uuid.UUID, userIDs []uuid.UUID) ([]uuid.UUID, error) {
+	var blocks []model.Block
+	var blockedIDs []uuid.UUID
+
+	if len(userIDs) == 0 {
+		return blockedIDs, nil
+	}
+
+	result := repository.database.
+		Where("(user_id = ? AND blocked_id IN ?) OR (blocked_id = ? AND user_id IN ?)", userID, userIDs, userID, userIDs).
+		Find(&blocks)
+
+	for _, block := range blocks {
+		if block.UserID == userID {
+			blockedIDs = append(blockedIDs, block.BlockedID)
+		} else {
+			blockedIDs = append(blockedIDs, block.UserID)
+		}
+	}
+
+	return blockedIDs, result.Error
+}
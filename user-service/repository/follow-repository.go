@@ -2,6 +2,7 @@ package repository
 
 import (
 	"github.com/KristijanPill/Nishtagram/user-service/model"
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
@@ -56,3 +57,29 @@ func (repository *FollowRepository) GetFollowingCount(id string) int64 {
 
 	return count
 }
+
+// FindUnmutedByFollowerID lists the follows of the follower that aren't muted,
+// leaving out accounts blocked in either direction.
+func (repository *FollowRepository) FindUnmutedByFollowerID(followerID string) ([]model.Follow, error) {
+	var follows []model.Follow
+	result := repository.database.
+		Where("follower_id = ? AND muted = ?", followerID, false).
+		Where("NOT EXISTS (SELECT 1 FROM blocks WHERE (blocks.user_id = follows.user_id AND blocks.blocked_id = follows.follower_id) " +
+			"OR (blocks.user_id = follows.follower_id AND blocks.blocked_id = follows.user_id))").
+		Find(&follows)
+
+	return follows, result.Error
+}
+
+// FindFollowedUserIDs tells which of the users the follower follows.
+func (repository *FollowRepository) FindFollowedUserIDs(followerID uuid.UUID, userIDs []uuid.UUID) ([]uuid.UUID, error) {
+	var followedIDs []uuid.UUID
+
+	if len(userIDs) == 0 {
+		return followedIDs, nil
+	}
+
+	result := repository.database.Model(&model.Follow{}).Where("follower_id = ? AND user_id IN ?", followerID, userIDs).Pluck("user_id", &followedIDs)
+
+	return followedIDs, result.Error
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/KristijanPill/Nishtagram/user-service/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type MessageRepository struct {
+	database *gorm.DB
+}
+
+func NewMessageRepository(database *gorm.DB) *MessageRepository {
+	return &MessageRepository{database: database}
+}
+
+// FindOrCreateConversation returns the conversation of the two users, creating
+// it the first time they talk.
+func (repository *MessageRepository) FindOrCreateConversation(userID uuid.UUID, otherUserID uuid.UUID) (*model.Conversation, error) {
+	if otherUserID.String() < userID.String() {
+		userID, otherUserID = otherUserID, userID
+	}
+
+	conversation := &model.Conversation{UserID: userID, OtherUserID: otherUserID}
+	result := repository.database.Clauses(clause.OnConflict{DoNothing: true}).Create(conversation)
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	result = repository.database.First(conversation, "user_id = ? AND other_user_id = ?", userID, otherUserID)
+
+	return conversation, result.Error
+}
+
+func (repository *MessageRepository) FindConversationByIDAndUserID(id string, userID string) (*model.Conversation, error) {
+	var conversation model.Conversation
+	result := repository.database.First(&conversation, "id = ? AND (user_id = ? OR other_user_id = ?)", id, userID, userID)
+
+	return &conversation, result.Error
+}
+
+func (repository *MessageRepository) FindConversationsByUserID(userID string, page int, size int) ([]model.Conversation, error) {
+	var conversations []model.Conversation
+	result := repository.database.Where("user_id = ? OR other_user_id = ?", userID, userID).
+		Order("updated_at desc").
+		Offset(page * size).Limit(size).
+		Find(&conversations)
+
+	return conversations, result.Error
+}
+
+// CreateMessage stores the message and moves its conversation to the top.
+func (repository *MessageRepository) CreateMessage(message *model.Message) (*model.Message, error) {
+	err := repository.database.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(message).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&model.Conversation{}).Where("id = ?", message.ConversationID).Update("updated_at", time.Now()).Error
+	})
+
+	return message, err
+}
+
+func (repository *MessageRepository) FindMessagesByConversationID(conversationID uuid.UUID, page int, size int) ([]model.Message, error) {
+	var messages []model.Message
+	result := repository.database.Where("conversation_id = ?", conversationID).
+		Order("created_at desc").
+		Offset(page * size).Limit(size).
+		Find(&messages)
+
+	return messages, result.Error
+}
+
+func (repository *MessageRepository) FindLastMessages(conversationIDs []uuid.UUID) ([]model.Message, error) {
+	var messages []model.Message
+
+	if len(conversationIDs) == 0 {
+		return messages, nil
+	}
+
+	result := repository.database.Raw(`SELECT DISTINCT ON (conversation_id) *
+		FROM messages
+		WHERE conversation_id IN ?
+		ORDER BY conversation_id, created_at DESC`, conversationIDs).
+		Scan(&messages)
+
+	return messages, result.Error
+}
@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"github.com/KristijanPill/Nishtagram/user-service/model"
+	"gorm.io/gorm"
+)
+
+type NotificationRepository struct {
+	database *gorm.DB
+}
+
+func NewNotificationRepository(database *gorm.DB) *NotificationRepository {
+	return &NotificationRepository{database: database}
+}
+
+func (repository *NotificationRepository) CreateAll(notifications []model.Notification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+
+	result := repository.database.Create(&notifications)
+
+	return result.Error
+}
+
+func (repository *NotificationRepository) FindByUserID(userID string, page int, size int) ([]model.Notification, error) {
+	var notifications []model.Notification
+	result := repository.database.Where("user_id = ?", userID).Order("created_at desc").Offset(page * size).Limit(size).Find(&notifications)
+
+	return notifications, result.Error
+}
+
+func (repository *NotificationRepository) MarkAllRead(userID string) error {
+	result := repository.database.Model(&model.Notification{}).Where("user_id = ? AND read = ?", userID, false).Update("read", true)
+
+	return result.Error
+}
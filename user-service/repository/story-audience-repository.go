@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"github.com/KristijanPill/Nishtagram/user-service/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type StoryAudienceRepository struct {
+	database *gorm.DB
+}
+
+func NewStoryAudienceRepository(database *gorm.DB) *StoryAudienceRepository {
+	return &StoryAudienceRepository{database: database}
+}
+
+func (repository *StoryAudienceRepository) Hide(hide *model.StoryHide) error {
+	result := repository.database.Clauses(clause.OnConflict{DoNothing: true}).Create(hide)
+
+	return result.Error
+}
+
+func (repository *StoryAudienceRepository) Unhide(userID string, hiddenUserID string) error {
+	result := repository.database.Where("user_id = ? AND hidden_user_id = ?", userID, hiddenUserID).Delete(&model.StoryHide{})
+
+	return result.Error
+}
+
+func (repository *StoryAudienceRepository) FindHiddenUserIDs(userID string) ([]uuid.UUID, error) {
+	var hiddenUserIDs []uuid.UUID
+	result := repository.database.Model(&model.StoryHide{}).Where("user_id = ?", userID).Order("created_at desc").Pluck("hidden_user_id", &hiddenUserIDs)
+
+	return hiddenUserIDs, result.Error
+}
+
+// FindHidingUserIDs finds which of the authors hide their stories from the
+// viewer.
+func (repository *StoryAudienceRepository) FindHidingUserIDs(viewerID string, authorIDs []uuid.UUID) ([]uuid.UUID, error) {
+	var userIDs []uuid.UUID
+
+	if len(authorIDs) == 0 {
+		return userIDs, nil
+	}
+
+	result := repository.database.Model(&model.StoryHide{}).Where("hidden_user_id = ? AND user_id IN ?", viewerID, authorIDs).Pluck("user_id", &userIDs)
+
+	return userIDs, result.Error
+}
+
+func (repository *StoryAudienceRepository) CreateList(list *model.AudienceList) (*model.AudienceList, error) {
+	result := repository.database.Create(list)
+
+	return list, result.Error
+}
+
+func (repository *StoryAudienceRepository) FindListByIDAndUserID(id string, userID string) (*model.AudienceList, error) {
+	var list model.AudienceList
+	result := repository.database.Preload("Members").First(&list, "id = ? AND user_id = ?", id, userID)
+
+	return &list, result.Error
+}
+
+func (repository *StoryAudienceRepository) FindListsByUserID(userID string) ([]model.AudienceList, error) {
+	var lists []model.AudienceList
+	result := repository.database.Preload("Members").Where("user_id = ?", userID).Order("created_at").Find(&lists)
+
+	return lists, result.Error
+}
+
+// UpdateList renames the list and replaces its members.
+func (repository *StoryAudienceRepository) UpdateList(list *model.AudienceList) (*model.AudienceList, error) {
+	err := repository.database.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Members").Save(list).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("list_id = ?", list.ID).Delete(&model.AudienceListMember{}).Error; err != nil {
+			return err
+		}
+
+		if len(list.Members) == 0 {
+			return nil
+		}
+
+		return tx.Create(&list.Members).Error
+	})
+
+	return list, err
+}
+
+func (repository *StoryAudienceRepository) DeleteList(list *model.AudienceList) error {
+	return repository.database.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("list_id = ?", list.ID).Delete(&model.AudienceListMember{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Omit("Members").Delete(list).Error
+	})
+}
+
+// FindListsWithMember finds which of the given lists the viewer is on.
+func (repository *StoryAudienceRepository) FindListsWithMember(viewerID string, listIDs []uuid.UUID) ([]model.AudienceList, error) {
+	var lists []model.AudienceList
+
+	if len(listIDs) == 0 {
+		return lists, nil
+	}
+
+	result := repository.database.Select("audience_lists.id, audience_lists.user_id").
+		Joins("JOIN audience_list_members ON audience_list_members.list_id = audience_lists.id").
+		Where("audience_list_members.member_id = ? AND audience_lists.id IN ?", viewerID, listIDs).
+		Find(&lists)
+
+	return lists, result.Error
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/KristijanPill/Nishtagram/user-service/model"
+	"github.com/KristijanPill/Nishtagram/user-service/payload"
+	"github.com/KristijanPill/Nishtagram/user-service/repository"
+	"github.com/google/uuid"
+)
+
+var ErrWebsiteRequired = errors.New("agents must provide the website of their business")
+
+var ErrAgentRequestPending = errors.New("there already is a pending agent request for this account")
+
+var ErrAlreadyAgent = errors.New("this account can already run campaigns")
+
+var ErrAgentRequestReviewed = errors.New("this agent request has already been reviewed")
+
+type AgentRequestService struct {
+	repository          *repository.AgentRequestRepository
+	userRepository      *repository.UserRepository
+	userService         *UserService
+	notificationService *NotificationService
+}
+
+func NewAgentRequestService(repository *repository.AgentRequestRepository, userRepository *repository.UserRepository,
+	userService *UserService, notificationService *NotificationService) *AgentRequestService {
+	return &AgentRequestService{
+		repository:          repository,
+		userRepository:      userRepository,
+		userService:         userService,
+		notificationService: notificationService,
+	}
+}
+
+// Register creates a regular account together with its agent request, so the
+// account can be used right away and gets the agent role once approved.
+func (service *AgentRequestService) Register(dto *payload.AgentRegistration) (*model.AgentRequest, error) {
+	if strings.TrimSpace(dto.Website) == "" {
+		return nil, ErrWebsiteRequired
+	}
+
+	user, err := service.userService.Create(&dto.CreateUser)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return service.repository.Create(&model.AgentRequest{
+		UserID:      user.ID,
+		Website:     dto.Website,
+		Description: dto.Description,
+	})
+}
+
+func (service *AgentRequestService) Create(dto *payload.AgentRequestCreate, userID uuid.UUID) (*model.AgentRequest, error) {
+	if strings.TrimSpace(dto.Website) == "" {
+		return nil, ErrWebsiteRequired
+	}
+
+	user, err := service.userRepository.FindByID(userID.String())
+
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Role != model.USER {
+		return nil, ErrAlreadyAgent
+	}
+
+	if service.repository.ExistsPendingByUserID(userID.String()) {
+		return nil, ErrAgentRequestPending
+	}
+
+	return service.repository.Create(&model.AgentRequest{
+		UserID:      userID,
+		Website:     dto.Website,
+		Description: dto.Description,
+	})
+}
+
+func (service *AgentRequestService) FindByStatus(status model.AgentRequestStatus, page int, size int) ([]payload.AgentRequestView, error) {
+	agentRequests, err := service.repository.FindByStatus(status, page, size)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var agentRequestsView = []payload.AgentRequestView{}
+
+	for _, agentRequest := range agentRequests {
+		agentRequestsView = append(agentRequestsView, service.toAgentRequestView(&agentRequest))
+	}
+
+	return agentRequestsView, nil
+}
+
+func (service *AgentRequestService) Approve(id uuid.UUID) (*payload.AgentRequestView, error) {
+	return service.review(id, model.AGENT_APPROVED, "Your agent request was approved. Log in again to start creating campaigns.")
+}
+
+func (service *AgentRequestService) Reject(id uuid.UUID) (*payload.AgentRequestView, error) {
+	return service.review(id, model.AGENT_REJECTED, "Your agent request was rejected.")
+}
+
+func (service *AgentRequestService) review(id uuid.UUID, status model.AgentRequestStatus, message string) (*payload.AgentRequestView, error) {
+	agentRequest, err := service.repository.FindByID(id.String())
+
+	if err != nil {
+		return nil, err
+	}
+
+	reviewed, err := service.repository.Review(agentRequest, status)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !reviewed {
+		return nil, ErrAgentRequestReviewed
+	}
+
+	_ = service.notificationService.Notify(&payload.NotificationCreate{
+		UserIDs: []uuid.UUID{agentRequest.UserID},
+		Type:    model.AGENT_REQUEST,
+		Message: message,
+	})
+
+	agentRequestView := service.toAgentRequestView(agentRequest)
+
+	return &agentRequestView, nil
+}
+
+func (service *AgentRequestService) toAgentRequestView(agentRequest *model.AgentRequest) payload.AgentRequestView {
+	agentRequestView := payload.AgentRequestView{
+		ID:          agentRequest.ID,
+		UserID:      agentRequest.UserID,
+		Website:     agentRequest.Website,
+		Description: agentRequest.Description,
+		Status:      agentRequest.Status,
+		CreatedAt:   agentRequest.CreatedAt,
+		ReviewedAt:  agentRequest.ReviewedAt,
+	}
+
+	if user, err := service.userRepository.FindByID(agentRequest.UserID.String()); err == nil {
+		agentRequestView.Username = user.Username
+	}
+
+	return agentRequestView
+}
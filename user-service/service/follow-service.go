@@ -13,15 +13,18 @@ type FollowService struct {
 	followRepository        *repository.FollowRepository
 	followRequestRepository *repository.FollowRequestRepository
 	userRepository          *repository.UserRepository
+	blockRepository         *repository.BlockRepository
 }
 
 func NewFollowService(followRepository *repository.FollowRepository,
 	followRequestRepository *repository.FollowRequestRepository,
-	userRepository *repository.UserRepository) *FollowService {
+	userRepository *repository.UserRepository,
+	blockRepository *repository.BlockRepository) *FollowService {
 	return &FollowService{
 		followRepository:        followRepository,
 		followRequestRepository: followRequestRepository,
 		userRepository:          userRepository,
+		blockRepository:         blockRepository,
 	}
 }
 
@@ -86,7 +89,64 @@ func (service *FollowService) FindByUserIDAndFollowerID(userID uuid.UUID, follow
 	return service.followRepository.FindByUserIDAndFollowerID(userID.String(), followerID.String())
 }
 
-func (service *FollowService) BindFollowStatus(usersDetails *payload.UsersDetails, loggedInUserID uuid.UUID) *payload.UsersDetails {
+// FindOutgoing returns the follow status towards every account the user follows
+// and hasn't muted, so callers don't have to ask about each account separately.
+func (service *FollowService) FindOutgoing(userID uuid.UUID) ([]payload.OutgoingFollow, error) {
+	follows, err := service.followRepository.FindUnmutedByFollowerID(userID.String())
+
+	if err != nil {
+		return nil, err
+	}
+
+	var outgoing = []payload.OutgoingFollow{}
+
+	for _, follow := range follows {
+		outgoing = append(outgoing, payload.OutgoingFollow{
+			UserID:      follow.UserID,
+			CloseFriend: follow.CloseFriend,
+		})
+	}
+
+	return outgoing, nil
+}
+
+// BindFollowStatus fills in whether the logged in user follows each of the
+// users and whether either of them blocked the other, with one query for the
+// follows and one for the blocks.
+func (service *FollowService) BindFollowStatus(usersDetails *payload.UsersDetails, loggedInUserID uuid.UUID) (*payload.UsersDetails, error) {
+	var userIDs []uuid.UUID
+
+	for _, details := range usersDetails.UsersDetails {
+		if details.ID != uuid.Nil {
+			userIDs = append(userIDs, details.ID)
+		}
+	}
+
+	followed := make(map[uuid.UUID]bool)
+	blocked := make(map[uuid.UUID]bool)
+
+	if loggedInUserID != uuid.Nil {
+		followedIDs, err := service.followRepository.FindFollowedUserIDs(loggedInUserID, userIDs)
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, userID := range followedIDs {
+			followed[userID] = true
+		}
+
+		blockedIDs, err := service.blockRepository.FindBlockedUserIDs(loggedInUserID, userIDs)
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, userID := range blockedIDs {
+			blocked[userID] = true
+		}
+	}
+
 	var retVal = []payload.UserDetails{}
 	for _, details := range usersDetails.UsersDetails {
 		retVal = append(retVal, payload.UserDetails{
@@ -94,11 +154,12 @@ func (service *FollowService) BindFollowStatus(usersDetails *payload.UsersDetail
 			Username:       details.Username,
 			Private:        details.Private,
 			ProfilePicture: details.ProfilePicture,
-			Followed:       true,
+			Followed:       details.ID != uuid.Nil && followed[details.ID],
+			Blocked:        details.ID != uuid.Nil && blocked[details.ID],
 		})
 	}
 
-	return &payload.UsersDetails{UsersDetails: retVal}
+	return &payload.UsersDetails{UsersDetails: retVal}, nil
 }
 
 func (service *FollowService) UpdateMuted(userID uuid.UUID, followerID uuid.UUID, muted bool) error {
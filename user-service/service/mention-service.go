@@ -0,0 +1,95 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/KristijanPill/Nishtagram/user-service/model"
+	"github.com/KristijanPill/Nishtagram/user-service/payload"
+	"github.com/KristijanPill/Nishtagram/user-service/repository"
+	"github.com/google/uuid"
+)
+
+type MentionService struct {
+	userRepository      *repository.UserRepository
+	blockRepository     *repository.BlockRepository
+	notificationService *NotificationService
+}
+
+func NewMentionService(userRepository *repository.UserRepository, blockRepository *repository.BlockRepository, notificationService *NotificationService) *MentionService {
+	return &MentionService{
+		userRepository:      userRepository,
+		blockRepository:     blockRepository,
+		notificationService: notificationService,
+	}
+}
+
+// Resolve finds the users behind the mentioned usernames. Users who don't allow
+// tagging, the author and users blocked either way are left out, so only the
+// returned users may be linked to.
+func (service *MentionService) Resolve(dto *payload.MentionCreate) ([]payload.Mention, error) {
+	author, err := service.userRepository.FindByID(dto.AuthorID.String())
+
+	if err != nil {
+		return nil, err
+	}
+
+	var mentions = []payload.Mention{}
+	mentioned := make(map[uuid.UUID]bool)
+
+	for _, username := range dto.Usernames {
+		user, err := service.userRepository.FindByUsername(strings.TrimPrefix(strings.TrimSpace(username), "@"))
+
+		if err != nil || mentioned[user.ID] || !service.canMention(author, user) {
+			continue
+		}
+
+		mentioned[user.ID] = true
+		mentions = append(mentions, payload.Mention{UserID: user.ID, Username: user.Username})
+	}
+
+	return mentions, nil
+}
+
+// Notify lets the mentioned users know about the story once it is stored.
+// Users who can no longer be mentioned by the author are skipped.
+func (service *MentionService) Notify(dto *payload.MentionNotify) error {
+	author, err := service.userRepository.FindByID(dto.AuthorID.String())
+
+	if err != nil {
+		return err
+	}
+
+	var userIDs []uuid.UUID
+	notified := make(map[uuid.UUID]bool)
+
+	for _, userID := range dto.UserIDs {
+		user, err := service.userRepository.FindByID(userID.String())
+
+		if err != nil || notified[user.ID] || !service.canMention(author, user) {
+			continue
+		}
+
+		notified[user.ID] = true
+		userIDs = append(userIDs, user.ID)
+	}
+
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	return service.notificationService.Notify(&payload.NotificationCreate{
+		UserIDs: userIDs,
+		Type:    model.MENTION,
+		Message: fmt.Sprintf("%s mentioned you in their story.", author.Username),
+	})
+}
+
+func (service *MentionService) canMention(author *model.User, user *model.User) bool {
+	if user.ID == author.ID || !user.Taggable || user.Suspended {
+		return false
+	}
+
+	return !service.blockRepository.ExistsByUserIDAndBlockedID(user.ID.String(), author.ID.String()) &&
+		!service.blockRepository.ExistsByUserIDAndBlockedID(author.ID.String(), user.ID.String())
+}
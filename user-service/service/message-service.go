@@ -0,0 +1,187 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/KristijanPill/Nishtagram/user-service/model"
+	"github.com/KristijanPill/Nishtagram/user-service/payload"
+	"github.com/KristijanPill/Nishtagram/user-service/repository"
+	"github.com/google/uuid"
+)
+
+const maxMessageLength = 1000
+
+var (
+	ErrInvalidMessage   = errors.New("a message must have between 1 and 1000 characters")
+	ErrMessageSelf      = errors.New("cannot send a message to yourself")
+	ErrMessagingBlocked = errors.New("you can't send messages to this user")
+	ErrMessagesClosed   = errors.New("this user only accepts messages from people they follow")
+)
+
+type MessageService struct {
+	repository       *repository.MessageRepository
+	userRepository   *repository.UserRepository
+	blockRepository  *repository.BlockRepository
+	followRepository *repository.FollowRepository
+}
+
+func NewMessageService(repository *repository.MessageRepository, userRepository *repository.UserRepository,
+	blockRepository *repository.BlockRepository, followRepository *repository.FollowRepository) *MessageService {
+	return &MessageService{
+		repository:       repository,
+		userRepository:   userRepository,
+		blockRepository:  blockRepository,
+		followRepository: followRepository,
+	}
+}
+
+// SendStoryResponse delivers a reply or a reaction to a story to the author of
+// the story. It is called by story-service, which has already checked that the
+// sender can see the story.
+func (service *MessageService) SendStoryResponse(dto *payload.StoryResponseCreate) (*payload.MessageView, error) {
+	if dto.Type != model.STORY_REPLY && dto.Type != model.STORY_REACTION {
+		return nil, ErrInvalidMessage
+	}
+
+	storyID := dto.StoryID
+
+	return service.send(dto.SenderID, dto.RecipientID, &model.Message{
+		SenderID:         dto.SenderID,
+		Type:             dto.Type,
+		Text:             strings.TrimSpace(dto.Text),
+		StoryID:          &storyID,
+		StoryPreview:     dto.StoryPreview,
+		CloseFriendsOnly: dto.CloseFriendsOnly,
+	})
+}
+
+func (service *MessageService) FindConversations(userID uuid.UUID, page int, size int) ([]payload.ConversationView, error) {
+	conversations, err := service.repository.FindConversationsByUserID(userID.String(), page, size)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var conversationIDs []uuid.UUID
+
+	for _, conversation := range conversations {
+		conversationIDs = append(conversationIDs, conversation.ID)
+	}
+
+	lastMessages, err := service.repository.FindLastMessages(conversationIDs)
+
+	if err != nil {
+		return nil, err
+	}
+
+	lastMessageByConversationID := make(map[uuid.UUID]*payload.MessageView)
+
+	for i := range lastMessages {
+		messageView := toMessageView(&lastMessages[i])
+		lastMessageByConversationID[lastMessages[i].ConversationID] = &messageView
+	}
+
+	var conversationsView = []payload.ConversationView{}
+
+	for _, conversation := range conversations {
+		otherUserID := conversation.OtherUserID
+
+		if otherUserID == userID {
+			otherUserID = conversation.UserID
+		}
+
+		conversationsView = append(conversationsView, payload.ConversationView{
+			ID:          conversation.ID,
+			UserID:      otherUserID,
+			LastMessage: lastMessageByConversationID[conversation.ID],
+			UpdatedAt:   conversation.UpdatedAt,
+		})
+	}
+
+	return conversationsView, nil
+}
+
+func (service *MessageService) FindMessages(conversationID uuid.UUID, userID uuid.UUID, page int, size int) ([]payload.MessageView, error) {
+	conversation, err := service.repository.FindConversationByIDAndUserID(conversationID.String(), userID.String())
+
+	if err != nil {
+		return nil, err
+	}
+
+	messages, err := service.repository.FindMessagesByConversationID(conversation.ID, page, size)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var messagesView = []payload.MessageView{}
+
+	for i := range messages {
+		messagesView = append(messagesView, toMessageView(&messages[i]))
+	}
+
+	return messagesView, nil
+}
+
+// send refuses messages between users when either of them has blocked the
+// other, and messages from people the recipient doesn't follow when the
+// recipient turned those off.
+func (service *MessageService) send(senderID uuid.UUID, recipientID uuid.UUID, message *model.Message) (*payload.MessageView, error) {
+	length := utf8.RuneCountInString(message.Text)
+
+	if length == 0 || length > maxMessageLength {
+		return nil, ErrInvalidMessage
+	}
+
+	if senderID == recipientID {
+		return nil, ErrMessageSelf
+	}
+
+	recipient, err := service.userRepository.FindByID(recipientID.String())
+
+	if err != nil {
+		return nil, err
+	}
+
+	if service.blockRepository.ExistsByUserIDAndBlockedID(recipientID.String(), senderID.String()) ||
+		service.blockRepository.ExistsByUserIDAndBlockedID(senderID.String(), recipientID.String()) {
+		return nil, ErrMessagingBlocked
+	}
+
+	if !recipient.CanRecieveAnonMessages && !service.followRepository.ExistsByUserIDAndFollowerID(senderID.String(), recipientID.String()) {
+		return nil, ErrMessagesClosed
+	}
+
+	conversation, err := service.repository.FindOrCreateConversation(senderID, recipientID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	message.ConversationID = conversation.ID
+
+	message, err = service.repository.CreateMessage(message)
+
+	if err != nil {
+		return nil, err
+	}
+
+	messageView := toMessageView(message)
+
+	return &messageView, nil
+}
+
+func toMessageView(message *model.Message) payload.MessageView {
+	return payload.MessageView{
+		ID:               message.ID,
+		SenderID:         message.SenderID,
+		Type:             message.Type,
+		Text:             message.Text,
+		StoryID:          message.StoryID,
+		StoryPreview:     message.StoryPreview,
+		CloseFriendsOnly: message.CloseFriendsOnly,
+		CreatedAt:        message.CreatedAt,
+	}
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"github.com/KristijanPill/Nishtagram/user-service/model"
+	"github.com/KristijanPill/Nishtagram/user-service/payload"
+	"github.com/KristijanPill/Nishtagram/user-service/repository"
+	"github.com/google/uuid"
+)
+
+type NotificationService struct {
+	repository *repository.NotificationRepository
+}
+
+func NewNotificationService(repository *repository.NotificationRepository) *NotificationService {
+	return &NotificationService{repository: repository}
+}
+
+func (service *NotificationService) Notify(dto *payload.NotificationCreate) error {
+	var notifications []model.Notification
+
+	for _, userID := range dto.UserIDs {
+		notifications = append(notifications, model.Notification{
+			UserID:  userID,
+			Type:    dto.Type,
+			Message: dto.Message,
+		})
+	}
+
+	return service.repository.CreateAll(notifications)
+}
+
+func (service *NotificationService) FindByUser(userID uuid.UUID, page int, size int) ([]payload.NotificationView, error) {
+	notifications, err := service.repository.FindByUserID(userID.String(), page, size)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var notificationsView = []payload.NotificationView{}
+
+	for _, notification := range notifications {
+		notificationsView = append(notificationsView, payload.NotificationView{
+			ID:        notification.ID,
+			Type:      notification.Type,
+			Message:   notification.Message,
+			Read:      notification.Read,
+			CreatedAt: notification.CreatedAt,
+		})
+	}
+
+	return notificationsView, service.repository.MarkAllRead(userID.String())
+}
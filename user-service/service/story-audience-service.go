@@ -0,0 +1,214 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/KristijanPill/Nishtagram/user-service/model"
+	"github.com/KristijanPill/Nishtagram/user-service/payload"
+	"github.com/KristijanPill/Nishtagram/user-service/repository"
+	"github.com/google/uuid"
+)
+
+const (
+	maxAudienceListName    = 50
+	maxAudienceListMembers = 500
+)
+
+var (
+	ErrHideSelf               = errors.New("cannot hide stories from yourself")
+	ErrInvalidAudienceList    = errors.New("a list needs a name of at most 50 characters")
+	ErrTooManyAudienceMembers = errors.New("a list can have at most 500 members")
+)
+
+type StoryAudienceService struct {
+	repository     *repository.StoryAudienceRepository
+	userRepository *repository.UserRepository
+}
+
+func NewStoryAudienceService(repository *repository.StoryAudienceRepository, userRepository *repository.UserRepository) *StoryAudienceService {
+	return &StoryAudienceService{repository: repository, userRepository: userRepository}
+}
+
+func (service *StoryAudienceService) Hide(userID uuid.UUID, hiddenUserID uuid.UUID) error {
+	if userID == hiddenUserID {
+		return ErrHideSelf
+	}
+
+	if _, err := service.userRepository.FindByID(hiddenUserID.String()); err != nil {
+		return err
+	}
+
+	return service.repository.Hide(&model.StoryHide{UserID: userID, HiddenUserID: hiddenUserID})
+}
+
+func (service *StoryAudienceService) Unhide(userID uuid.UUID, hiddenUserID uuid.UUID) error {
+	return service.repository.Unhide(userID.String(), hiddenUserID.String())
+}
+
+func (service *StoryAudienceService) FindHidden(userID uuid.UUID) ([]uuid.UUID, error) {
+	hiddenUserIDs, err := service.repository.FindHiddenUserIDs(userID.String())
+
+	if hiddenUserIDs == nil {
+		hiddenUserIDs = []uuid.UUID{}
+	}
+
+	return hiddenUserIDs, err
+}
+
+func (service *StoryAudienceService) CreateList(userID uuid.UUID, dto *payload.AudienceListCreate) (*payload.AudienceListView, error) {
+	list := &model.AudienceList{UserID: userID}
+
+	if err := service.fillList(list, dto); err != nil {
+		return nil, err
+	}
+
+	list, err := service.repository.CreateList(list)
+
+	if err != nil {
+		return nil, err
+	}
+
+	listView := toAudienceListView(list)
+
+	return &listView, nil
+}
+
+func (service *StoryAudienceService) FindLists(userID uuid.UUID) ([]payload.AudienceListView, error) {
+	lists, err := service.repository.FindListsByUserID(userID.String())
+
+	if err != nil {
+		return nil, err
+	}
+
+	var listsView = []payload.AudienceListView{}
+
+	for i := range lists {
+		listsView = append(listsView, toAudienceListView(&lists[i]))
+	}
+
+	return listsView, nil
+}
+
+// UpdateList renames the list and replaces its members with the given ones.
+func (service *StoryAudienceService) UpdateList(listID uuid.UUID, userID uuid.UUID, dto *payload.AudienceListCreate) (*payload.AudienceListView, error) {
+	list, err := service.repository.FindListByIDAndUserID(listID.String(), userID.String())
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := service.fillList(list, dto); err != nil {
+		return nil, err
+	}
+
+	list, err = service.repository.UpdateList(list)
+
+	if err != nil {
+		return nil, err
+	}
+
+	listView := toAudienceListView(list)
+
+	return &listView, nil
+}
+
+func (service *StoryAudienceService) DeleteList(listID uuid.UUID, userID uuid.UUID) error {
+	list, err := service.repository.FindListByIDAndUserID(listID.String(), userID.String())
+
+	if err != nil {
+		return err
+	}
+
+	return service.repository.DeleteList(list)
+}
+
+// Check answers for story-service, in one call, which authors hide their
+// stories from the viewer and which audience lists the viewer is on. A list
+// only counts for the author who owns it.
+func (service *StoryAudienceService) Check(dto *payload.StoryAudienceRequest) (*payload.StoryAudience, error) {
+	audience := &payload.StoryAudience{HiddenBy: []uuid.UUID{}, MemberOf: []uuid.UUID{}}
+
+	if dto.ViewerID == uuid.Nil {
+		return audience, nil
+	}
+
+	hiddenBy, err := service.repository.FindHidingUserIDs(dto.ViewerID.String(), dto.AuthorIDs)
+
+	if err != nil {
+		return nil, err
+	}
+
+	audience.HiddenBy = append(audience.HiddenBy, hiddenBy...)
+
+	var listIDs []uuid.UUID
+	ownerByListID := make(map[uuid.UUID]uuid.UUID)
+
+	for _, list := range dto.Lists {
+		listIDs = append(listIDs, list.ID)
+		ownerByListID[list.ID] = list.UserID
+	}
+
+	lists, err := service.repository.FindListsWithMember(dto.ViewerID.String(), listIDs)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, list := range lists {
+		if ownerByListID[list.ID] == list.UserID {
+			audience.MemberOf = append(audience.MemberOf, list.ID)
+		}
+	}
+
+	return audience, nil
+}
+
+// fillList sets the name and members of the list. Unknown users and the owner
+// are left out of the members.
+func (service *StoryAudienceService) fillList(list *model.AudienceList, dto *payload.AudienceListCreate) error {
+	name := strings.TrimSpace(dto.Name)
+
+	if name == "" || utf8.RuneCountInString(name) > maxAudienceListName {
+		return ErrInvalidAudienceList
+	}
+
+	if len(dto.MemberIDs) > maxAudienceListMembers {
+		return ErrTooManyAudienceMembers
+	}
+
+	list.Name = name
+	list.Members = []model.AudienceListMember{}
+	added := make(map[uuid.UUID]bool)
+
+	for _, memberID := range dto.MemberIDs {
+		if memberID == list.UserID || added[memberID] {
+			continue
+		}
+
+		if _, err := service.userRepository.FindByID(memberID.String()); err != nil {
+			continue
+		}
+
+		added[memberID] = true
+		list.Members = append(list.Members, model.AudienceListMember{ListID: list.ID, MemberID: memberID})
+	}
+
+	return nil
+}
+
+func toAudienceListView(list *model.AudienceList) payload.AudienceListView {
+	listView := payload.AudienceListView{
+		ID:        list.ID,
+		Name:      list.Name,
+		MemberIDs: []uuid.UUID{},
+		CreatedAt: list.CreatedAt,
+	}
+
+	for _, member := range list.Members {
+		listView.MemberIDs = append(listView.MemberIDs, member.MemberID)
+	}
+
+	return listView
+}
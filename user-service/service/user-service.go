@@ -100,6 +100,8 @@ func (service *UserService) Update(dto *payload.UserInfo, id uuid.UUID) (*model.
 	user.Name = dto.Name
 	user.DOB = dto.DOB
 	user.Gender = dto.Gender
+	user.City = dto.City
+	user.Country = dto.Country
 	user.PhoneNumber = dto.PhoneNumber
 	user.Website = dto.Website
 	user.Bio = dto.Bio
@@ -144,6 +146,62 @@ func (service *UserService) UpdateProfilePicture(profilePicturePath string, user
 	return user, nil
 }
 
+func (service *UserService) GetAccountStatus(id uuid.UUID) (*payload.AccountStatus, error) {
+	user, err := service.userRepository.FindByID(id.String())
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &payload.AccountStatus{Role: user.Role, Suspended: user.Suspended}, nil
+}
+
+// GetAudience describes the user the way campaigns target their audience.
+func (service *UserService) GetAudience(id uuid.UUID) (*payload.Audience, error) {
+	user, err := service.userRepository.FindByID(id.String())
+
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	age := now.Year() - user.DOB.Year()
+
+	if now.Month() < user.DOB.Month() || (now.Month() == user.DOB.Month() && now.Day() < user.DOB.Day()) {
+		age--
+	}
+
+	return &payload.Audience{Age: age, Gender: user.Gender, City: user.City, Country: user.Country}, nil
+}
+
+func (service *UserService) Warn(id uuid.UUID) (*model.User, error) {
+	user, err := service.userRepository.FindByID(id.String())
+
+	if err != nil {
+		return nil, err
+	}
+
+	user.Warnings++
+
+	return service.userRepository.Update(user)
+}
+
+func (service *UserService) Suspend(id uuid.UUID) (*model.User, error) {
+	user, err := service.userRepository.FindByID(id.String())
+
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Role == model.ADMIN {
+		return nil, errors.New("cannot suspend an admin")
+	}
+
+	user.Suspended = true
+
+	return service.userRepository.Update(user)
+}
+
 func (service *UserService) hashPassword(password string) ([]byte, error) {
 	return bcrypt.GenerateFromPassword([]byte(password), 14)
 }
@@ -167,6 +225,7 @@ func (service *UserService) SearchUsers(query string) ([]payload.UserView, error
 
 	for _, user := range result {
 		users = append(users, payload.UserView{
+			ID:             user.ID,
 			Username:       user.Username,
 			Name:           user.Name,
 			ProfilePicture: user.ProfilePicture,